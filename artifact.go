@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// artifactETagSuffix is appended to the URL key to form the name of the file
+// that records the ETag of the currently stored artifact.
+const artifactETagSuffix = ".etag"
+
+// FetchArtifact downloads the resource identified by url into destDir and
+// returns the path of the local file. Downloads are stored keyed by the URL
+// and the ETag reported by the server.
+//
+// If an artifact has been downloaded for url before, the request is sent with
+// an If-None-Match header carrying the stored ETag. A 304 Not Modified
+// response causes the existing file to be reused without transferring the
+// body again. A 200 OK response replaces the stored artifact. Responses
+// without an ETag are downloaded on every call. Any other status code is
+// reported as an error.
+//
+// The artifact is handled in PhasePreValidate. A 304 Not Modified response
+// is passed on to later phases as a body-less 200 OK response marked with
+// MarkFromCache, so client-level status validators such as
+// ExpectedStatusCode(http.StatusOK) accept revalidated artifacts.
+//
+// The body is streamed to a temporary file in destDir which is renamed once
+// the download completed, so an interrupted download never replaces a valid
+// artifact. destDir is created if it does not exist. Any opts are applied to
+// the request before the revalidation header is set.
+func (c *Client) FetchArtifact(ctx context.Context, url, destDir string, opts ...RequestOption) (string, error) {
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return "", err
+	}
+
+	urlKey := artifactKey(url)
+	etagPath := filepath.Join(destDir, urlKey+artifactETagSuffix)
+
+	var etag, path string
+	if b, err := os.ReadFile(etagPath); err == nil && len(b) > 0 {
+		etag = string(b)
+		path = filepath.Join(destDir, artifactKey(url, etag))
+		if _, err := os.Stat(path); err != nil {
+			etag, path = "", ""
+		}
+	}
+
+	var result string
+
+	opts = append(opts[:len(opts):len(opts)], InPhaseFunc(PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		switch r.StatusCode {
+		case http.StatusNotModified:
+			if path == "" {
+				return r, errors.New("received 304 Not Modified for unknown artifact")
+			}
+			result = path
+
+			cached := *r
+			cached.StatusCode = http.StatusOK
+			cached.Status = "200 OK"
+			cached.Header = r.Header.Clone()
+			cached.Body = http.NoBody
+			cached.ContentLength = 0
+			MarkFromCache(&cached)
+			return &cached, nil
+
+		case http.StatusOK:
+			p, err := storeArtifact(destDir, url, r)
+			if err != nil {
+				return r, err
+			}
+			if path != "" && path != p {
+				os.Remove(path)
+			}
+			result = p
+			return r, nil
+
+		default:
+			return r, nil
+		}
+	}))
+
+	if etag != "" {
+		opts = append(opts, WithRequestHeader("If-None-Match", etag))
+	}
+
+	res, err := c.Get(ctx, url, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if result == "" {
+		return "", newResponseError(ErrUnexpectedStatus, res, nil)
+	}
+
+	return result, nil
+}
+
+// storeArtifact streams the body of r into destDir and records the ETag of r
+// as the current version of the artifact stored for url. It returns the path
+// of the stored file.
+func storeArtifact(destDir, url string, r *http.Response) (string, error) {
+	urlKey := artifactKey(url)
+	etag := r.Header.Get("ETag")
+
+	f, err := os.CreateTemp(destDir, urlKey+"-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r.Body); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(destDir, artifactKey(url, etag))
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(destDir, urlKey+artifactETagSuffix), []byte(etag), 0o644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// artifactKey computes a file system safe key from parts.
+func artifactKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		io.WriteString(h, p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,97 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestClient_FetchArtifact(t *testing.T) {
+	etag := `"v1"`
+	content := "artifact v1"
+	var downloads int
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(content))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	path, err := client.FetchArtifact(ctx, testServer.URL+"/artifact", dir)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, downloads).Is(Equal(1))
+	expectFileContent(t, path, "artifact v1")
+
+	revalidated, err := client.FetchArtifact(ctx, testServer.URL+"/artifact", dir)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, downloads).Is(Equal(1))
+	ExpectThat(t, revalidated).Is(Equal(path))
+
+	etag = `"v2"`
+	content = "artifact v2"
+
+	updated, err := client.FetchArtifact(ctx, testServer.URL+"/artifact", dir)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, downloads).Is(Equal(2))
+	expectFileContent(t, updated, "artifact v2")
+
+	_, err = os.Stat(path)
+	ExpectThat(t, os.IsNotExist(err)).Is(Equal(true))
+}
+
+func TestClient_FetchArtifact_withStatusValidation(t *testing.T) {
+	var downloads int
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("artifact"))
+	}))
+	defer testServer.Close()
+
+	var fromCache []bool
+	client := httpclient.New(
+		httpclient.ExpectedStatusCode(http.StatusOK),
+		httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			fromCache = append(fromCache, httpclient.FromCache(r))
+			return r, nil
+		}),
+	)
+	dir := t.TempDir()
+
+	path, err := client.FetchArtifact(context.Background(), testServer.URL+"/artifact", dir)
+	ExpectThat(t, err).Is(NoError())
+
+	revalidated, err := client.FetchArtifact(context.Background(), testServer.URL+"/artifact", dir)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, revalidated).Is(Equal(path))
+	ExpectThat(t, downloads).Is(Equal(1))
+	ExpectThat(t, fromCache).Is(DeepEqual([]bool{false, true}))
+	expectFileContent(t, revalidated, "artifact")
+}
+
+func expectFileContent(t *testing.T, path, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(path)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, string(got)).Is(Equal(want))
+}
@@ -0,0 +1,242 @@
+// Package azuread authenticates requests using access tokens obtained from
+// Microsoft Entra ID (formerly Azure Active Directory) with the OAuth 2.0
+// client credentials flow. Clients authenticate using either a client secret
+// or a certificate, in which case a signed client assertion is sent as
+// defined for private_key_jwt.
+//
+// Tokens are cached per set of scopes and refreshed shortly before they
+// expire, so the token endpoint is only called when needed. Use
+// WithTokenCache to reuse tokens across runs of a process.
+package azuread
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/jose"
+)
+
+// DefaultAuthority is the authority of the Microsoft Entra ID global cloud.
+const DefaultAuthority = "https://login.microsoftonline.com"
+
+// RefreshWindow is the time before a token expires at which it is refreshed.
+const RefreshWindow = 5 * time.Minute
+
+// assertionType is the client_assertion_type of client assertions.
+const assertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// assertionLifetime is the time a client assertion is valid.
+const assertionLifetime = 10 * time.Minute
+
+// Option customizes a Credential.
+type Option func(*Credential)
+
+// WithAuthority sets the authority tokens are obtained from, i.e.
+// "https://login.microsoftonline.us" for the US Government cloud. It
+// defaults to DefaultAuthority.
+func WithAuthority(authority string) Option {
+	return func(c *Credential) {
+		c.authority = strings.TrimSuffix(authority, "/")
+	}
+}
+
+// WithClient sets the Client used to request tokens. It defaults to a Client
+// created using httpclient.New without options. Don't pass a Client
+// authenticated using the Credential itself.
+func WithClient(client *httpclient.Client) Option {
+	return func(c *Credential) {
+		c.client = client
+	}
+}
+
+// WithTokenCache sets the cache tokens are stored in. It defaults to a
+// httpclient.MemoryTokenCache used by the Credential only. Tokens are keyed
+// by authority, tenant, client ID and scopes, so a cache may be shared by
+// multiple Credentials.
+func WithTokenCache(cache httpclient.TokenCache) Option {
+	return func(c *Credential) {
+		c.cache = cache
+	}
+}
+
+// Credential obtains access tokens for an application registered in a
+// Microsoft Entra ID tenant. A Credential is safe for concurrent use.
+type Credential struct {
+	tenantID  string
+	clientID  string
+	authority string
+	client    *httpclient.Client
+
+	secret     string
+	key        *rsa.PrivateKey
+	thumbprint string
+
+	mu    sync.Mutex
+	cache httpclient.TokenCache
+}
+
+// NewClientSecretCredential creates a Credential for the application
+// clientID in tenantID authenticating using secret.
+func NewClientSecretCredential(tenantID, clientID, secret string, opts ...Option) *Credential {
+	c := newCredential(tenantID, clientID, opts)
+	c.secret = secret
+	return c
+}
+
+// NewClientCertificateCredential creates a Credential for the application
+// clientID in tenantID authenticating using a client assertion signed with
+// key. cert is the certificate registered for the application and must
+// contain key's public key.
+func NewClientCertificateCredential(tenantID, clientID string, cert *x509.Certificate, key *rsa.PrivateKey, opts ...Option) (*Credential, error) {
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		return nil, errors.New("azuread: certificate doesn't match private key")
+	}
+
+	thumbprint := sha1.Sum(cert.Raw)
+
+	c := newCredential(tenantID, clientID, opts)
+	c.key = key
+	c.thumbprint = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	return c, nil
+}
+
+func newCredential(tenantID, clientID string, opts []Option) *Credential {
+	c := &Credential{
+		tenantID:  tenantID,
+		clientID:  clientID,
+		authority: DefaultAuthority,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.client == nil {
+		c.client = httpclient.New()
+	}
+	if c.cache == nil {
+		c.cache = &httpclient.MemoryTokenCache{}
+	}
+
+	return c
+}
+
+// WithCredential creates a RequestInterceptorOption authenticating requests
+// with an access token for scopes obtained using cred, i.e.
+// "api://my-api/.default" or "https://graph.microsoft.com/.default". The
+// Authorization header is set in httpclient.RequestPhaseAuth.
+func WithCredential(cred *Credential, scopes ...string) httpclient.RequestInterceptorOption {
+	return httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, func(r *http.Request) (*http.Request, error) {
+		tok, err := cred.Token(r.Context(), scopes...)
+		if err != nil {
+			return r, err
+		}
+
+		r.Header.Set("Authorization", "Bearer "+tok)
+		return r, nil
+	})
+}
+
+// Token returns an access token for scopes. A cached token is returned
+// unless it expires within RefreshWindow.
+func (c *Credential) Token(ctx context.Context, scopes ...string) (string, error) {
+	key := strings.Join([]string{c.authority, c.tenantID, c.clientID, strings.Join(scopes, " ")}, "|")
+	clock := httpclient.ClockFromContext(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.cache.Get(key); ok && clock.Now().Before(t.Expiry.Add(-RefreshWindow)) {
+		return t.Value, nil
+	}
+
+	t, err := c.requestToken(ctx, clock, scopes)
+	if err != nil {
+		return "", fmt.Errorf("azuread: failed to obtain token: %w", err)
+	}
+
+	c.cache.Set(key, t)
+	return t.Value, nil
+}
+
+// tokenURL returns the URL of the tenant's token endpoint.
+func (c *Credential) tokenURL() string {
+	return c.authority + "/" + url.PathEscape(c.tenantID) + "/oauth2/v2.0/token"
+}
+
+func (c *Credential) requestToken(ctx context.Context, clock httpclient.Clock, scopes []string) (httpclient.Token, error) {
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"client_id":  {c.clientID},
+		"scope":      {strings.Join(scopes, " ")},
+	}
+
+	if c.key != nil {
+		assertion, err := c.assertion(clock.Now())
+		if err != nil {
+			return httpclient.Token{}, err
+		}
+		form.Set("client_assertion_type", assertionType)
+		form.Set("client_assertion", assertion)
+	} else {
+		form.Set("client_secret", c.secret)
+	}
+
+	var res struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	start := clock.Now()
+	_, err := c.client.Post(httpclient.ContextWithoutOptions(ctx), c.tokenURL(),
+		httpclient.WithForm(form),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+		httpclient.ForJSON(&res),
+	)
+	if err != nil {
+		return httpclient.Token{}, err
+	}
+
+	if res.AccessToken == "" || !strings.EqualFold(res.TokenType, "Bearer") {
+		return httpclient.Token{}, fmt.Errorf("unexpected token response of type %q", res.TokenType)
+	}
+
+	return httpclient.Token{
+		Value:  res.AccessToken,
+		Expiry: start.Add(time.Duration(res.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// assertion creates a client assertion valid from now.
+func (c *Credential) assertion(now time.Time) (string, error) {
+	claims, err := json.Marshal(map[string]any{
+		"aud": c.tokenURL(),
+		"iss": c.clientID,
+		"sub": c.clientID,
+		"jti": httpclient.NewUUID(),
+		"nbf": now.Unix(),
+		"iat": now.Unix(),
+		"exp": now.Add(assertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return jose.SignHeader(claims, jose.Key{Algorithm: jose.RS256, Key: c.key}, jose.Header{
+		Type:           "JWT",
+		X509Thumbprint: c.thumbprint,
+	})
+}
@@ -0,0 +1,163 @@
+package azuread_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/azuread"
+	"github.com/halimath/httpclient/httpclienttest"
+	"github.com/halimath/httpclient/jose"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	requests int
+	key      *rsa.PublicKey
+	err      error
+}
+
+func newTokenServer(t *testing.T, key *rsa.PublicKey) *tokenServer {
+	s := &tokenServer{key: key}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+
+		if r.URL.Path != "/tenant/oauth2/v2.0/token" || r.FormValue("grant_type") != "client_credentials" ||
+			r.FormValue("client_id") != "client" || r.FormValue("scope") != "api://app/.default" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if s.key != nil {
+			payload, err := jose.Verify(r.FormValue("client_assertion"), []jose.Key{{Algorithm: jose.RS256, Key: s.key}})
+			var claims map[string]any
+			if err == nil {
+				err = json.Unmarshal(payload, &claims)
+			}
+			if err == nil && (claims["aud"] != s.URL+"/tenant/oauth2/v2.0/token" || claims["sub"] != "client") {
+				err = fmt.Errorf("invalid claims: %v", claims)
+			}
+			if err != nil {
+				s.err = err
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		} else if r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token_type":"Bearer","expires_in":3600,"access_token":"token-%d"}`, s.requests)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWithCredential(t *testing.T) {
+	tokens := newTokenServer(t, nil)
+	cred := azuread.NewClientSecretCredential("tenant", "client", "secret", azuread.WithAuthority(tokens.URL+"/"))
+
+	var auth []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+	}))
+	defer api.Close()
+
+	clock := httpclienttest.NewFakeClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	client := httpclient.New(
+		httpclient.WithURLPrefix(api.URL),
+		httpclient.WithClock(clock),
+		azuread.WithCredential(cred, "api://app/.default"),
+	)
+
+	for range 2 {
+		_, err := client.Get(context.Background(), "/")
+		ExpectThat(t, err).Is(NoError())
+	}
+
+	clock.Advance(56 * time.Minute)
+	_, err := client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, auth).Is(DeepEqual([]string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}))
+
+	_, err = client.Get(context.Background(), "/", azuread.WithCredential(cred, "api://other/.default"))
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+}
+
+func TestWithCredential_contextOptions(t *testing.T) {
+	tokens := newTokenServer(t, nil)
+	cred := azuread.NewClientSecretCredential("tenant", "client", "secret", azuread.WithAuthority(tokens.URL+"/"))
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer api.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(api.URL), azuread.WithCredential(cred, "api://app/.default"))
+
+	ctx := httpclient.ContextWithOptions(context.Background(), httpclient.ExpectedStatusCode(http.StatusNoContent))
+	_, err := client.Get(ctx, "/")
+	ExpectThat(t, err).Is(NoError())
+}
+
+func TestNewClientCertificateCredential(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	ExpectThat(t, err).Is(NoError())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	ExpectThat(t, err).Is(NoError())
+	cert, err := x509.ParseCertificate(der)
+	ExpectThat(t, err).Is(NoError())
+
+	tokens := newTokenServer(t, &key.PublicKey)
+
+	cred, err := azuread.NewClientCertificateCredential("tenant", "client", cert, key, azuread.WithAuthority(tokens.URL))
+	ExpectThat(t, err).Is(NoError())
+
+	tok, err := cred.Token(context.Background(), "api://app/.default")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, tokens.err).Is(NoError())
+	ExpectThat(t, tok).Is(Equal("token-1"))
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	ExpectThat(t, err).Is(NoError())
+	_, err = azuread.NewClientCertificateCredential("tenant", "client", cert, other)
+	ExpectThat(t, err).Is(NotNil())
+}
+
+func TestWithTokenCache(t *testing.T) {
+	tokens := newTokenServer(t, nil)
+	cache := &httpclient.MemoryTokenCache{}
+
+	for range 2 {
+		cred := azuread.NewClientSecretCredential("tenant", "client", "secret",
+			azuread.WithAuthority(tokens.URL),
+			azuread.WithTokenCache(cache),
+		)
+
+		tok, err := cred.Token(context.Background(), "api://app/.default")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, tok).Is(Equal("token-1"))
+	}
+
+	ExpectThat(t, tokens.requests).Is(Equal(1))
+}
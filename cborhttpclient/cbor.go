@@ -0,0 +1,24 @@
+// Package cborhttpclient integrates httpclient with CBOR (RFC 8949) as used
+// by IoT and COSE based APIs. It is provided as a separate module so that
+// applications not using CBOR don't depend on it.
+package cborhttpclient
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/halimath/httpclient"
+)
+
+// ContentType is the content type of CBOR encoded bodies.
+const ContentType = "application/cbor"
+
+// WithCBOR creates a RequestInterceptorOption that uses v encoded as CBOR as
+// the request's body. See httpclient.WithEncoded.
+func WithCBOR(v any) httpclient.RequestInterceptorOption {
+	return httpclient.WithEncoded(cbor.Marshal, v, ContentType)
+}
+
+// ForCBOR creates a RequestOption that decodes a CBOR encoded response body
+// into v. See httpclient.ForDecoded.
+func ForCBOR(v any) httpclient.RequestOption {
+	return httpclient.ForDecoded(cbor.Unmarshal, v, ContentType)
+}
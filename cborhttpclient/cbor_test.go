@@ -0,0 +1,43 @@
+package cborhttpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/cborhttpclient"
+)
+
+func TestCBOR(t *testing.T) {
+	type reading struct {
+		Sensor string  `cbor:"sensor"`
+		Value  float64 `cbor:"value"`
+	}
+
+	var contentType, accept string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", cborhttpclient.ContentType)
+		io.Copy(w, r.Body)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	var got reading
+	_, err := client.Post(context.Background(), "/",
+		cborhttpclient.WithCBOR(reading{Sensor: "t1", Value: 21.5}),
+		cborhttpclient.ForCBOR(&got),
+	)
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, got).Is(Equal(reading{Sensor: "t1", Value: 21.5}))
+	ExpectThat(t, contentType).Is(Equal(cborhttpclient.ContentType))
+	ExpectThat(t, accept).Is(Equal(cborhttpclient.ContentType))
+}
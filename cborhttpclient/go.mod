@@ -0,0 +1,14 @@
+module github.com/halimath/httpclient/cborhttpclient
+
+go 1.23.0
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.4
+	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7
+	github.com/halimath/httpclient v0.1.0
+)
+
+require (
+	github.com/deckarep/golang-set/v2 v2.1.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
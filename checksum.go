@@ -0,0 +1,210 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/md5"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithChecksum creates an Option verifying that the body of a successful
+// response hashes to expected using alg, i.e. crypto.SHA256. expected is
+// given hex encoded, as checksums are usually published. The body is
+// verified while it is read, so it is never buffered; a mismatch is reported
+// as an *Error of kind ErrChecksumMismatch by the Read call reaching the end
+// of the body, which fails decoders such as ForJSON as well as
+// Client.Download.
+//
+// Responses with a status code other than 2xx and partial responses are not
+// verified. Downloads using WithDownloadSegments verify the assembled
+// destination instead.
+// An unavailable alg or an expected value which is not hex encoded fails
+// the request before it is sent.
+func WithChecksum(alg crypto.Hash, expected string) Option {
+	sum, err := hex.DecodeString(expected)
+	if err == nil && !alg.Available() {
+		err = fmt.Errorf("unavailable hash function: %v", alg)
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid checksum: %w", err)
+	}
+
+	return &withChecksum{alg: alg, sum: sum, err: err}
+}
+
+type withChecksum struct {
+	alg crypto.Hash
+	sum []byte
+	err error
+}
+
+func (*withChecksum) clientOpt() {}
+func (*withChecksum) reqOpt()    {}
+
+func (c *withChecksum) Phase() Phase { return PhasePreValidate }
+
+func (c *withChecksum) InterceptRequest(r *http.Request) (*http.Request, error) {
+	return r, c.err
+}
+
+func (c *withChecksum) InterceptResponse(r *http.Response) (*http.Response, error) {
+	if r.StatusCode < 200 || r.StatusCode > 299 || r.StatusCode == http.StatusPartialContent {
+		return r, nil
+	}
+
+	verifyBody(r, c.alg.String(), c.alg.New(), c.sum)
+	return r, nil
+}
+
+// verify reads r to its end and returns an *Error of kind
+// ErrChecksumMismatch for res unless the bytes read hash to c.sum.
+func (c *withChecksum) verify(res *http.Response, r io.Reader) error {
+	h := c.alg.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, c.sum) {
+		return newResponseError(ErrChecksumMismatch, res, fmt.Errorf("expected %s %x but got %x", c.alg, c.sum, got))
+	}
+	return nil
+}
+
+// checksumOf returns the last WithChecksum given in opts or nil.
+func checksumOf(opts []RequestOption) *withChecksum {
+	var c *withChecksum
+	for _, opt := range opts {
+		if o, ok := opt.(*withChecksum); ok {
+			c = o
+		}
+	}
+	return c
+}
+
+// contentDigestAlgorithms lists the algorithms supported by
+// VerifyContentDigest in order of preference, keyed by their name as
+// registered for the Content-Digest and Digest headers.
+var contentDigestAlgorithms = []struct {
+	name string
+	alg  crypto.Hash
+}{
+	{"sha-512", crypto.SHA512},
+	{"sha-256", crypto.SHA256},
+}
+
+// VerifyContentDigest creates a ResponseInterceptorOption verifying the body
+// of responses carrying a Content-Digest header as defined by RFC 9530 or a
+// Digest header as defined by RFC 3230 using sha-256 or sha-512. The body is
+// verified while it is read just like using WithChecksum. Responses without
+// any of these headers or using other algorithms only are not verified, nor
+// are responses the transport has decompressed transparently, as the digest
+// refers to the compressed content.
+func VerifyContentDigest() ResponseInterceptorOption {
+	return InPhaseFunc(PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		if r.Uncompressed || r.Body == nil || r.Body == http.NoBody {
+			return r, nil
+		}
+
+		digests := parseDigests(r.Header.Get("Content-Digest"))
+		if digests == nil {
+			digests = parseDigests(r.Header.Get("Digest"))
+		}
+
+		for _, a := range contentDigestAlgorithms {
+			if sum, ok := digests[a.name]; ok {
+				verifyBody(r, a.name, a.alg.New(), sum)
+				return r, nil
+			}
+		}
+
+		return r, nil
+	})
+}
+
+// parseDigests parses the value of a Content-Digest or Digest header into a
+// map of lower case algorithm names to digests. Both the structured field
+// syntax of Content-Digest (sha-256=:base64:) and the plain syntax of Digest
+// (SHA-256=base64) are accepted. Malformed entries are skipped. It returns
+// nil if no digest has been found.
+func parseDigests(header string) map[string][]byte {
+	var digests map[string][]byte
+
+	for _, entry := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == ':' && value[len(value)-1] == ':' {
+			value = value[1 : len(value)-1]
+		}
+
+		sum, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+
+		if digests == nil {
+			digests = make(map[string][]byte)
+		}
+		digests[strings.ToLower(strings.TrimSpace(name))] = sum
+	}
+
+	return digests
+}
+
+// verifyBody replaces the body of r with one hashing the bytes read using h
+// and failing with an *Error of kind ErrChecksumMismatch at the end of the
+// body unless the hash equals sum.
+func verifyBody(r *http.Response, name string, h hash.Hash, sum []byte) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+
+	r.Body = &checksumReader{
+		body: r.Body,
+		r:    io.TeeReader(r.Body, h),
+		res:  r,
+		name: name,
+		h:    h,
+		sum:  sum,
+	}
+}
+
+type checksumReader struct {
+	body io.ReadCloser
+	r    io.Reader
+	res  *http.Response
+	name string
+	h    hash.Hash
+	sum  []byte
+	err  error
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	n, err := c.r.Read(p)
+	if err == io.EOF {
+		if got := c.h.Sum(nil); !bytes.Equal(got, c.sum) {
+			c.err = newResponseError(ErrChecksumMismatch, c.res, fmt.Errorf("expected %s %x but got %x", c.name, c.sum, got))
+			return n, c.err
+		}
+	}
+	return n, err
+}
+
+func (c *checksumReader) Close() error {
+	return c.body.Close()
+}
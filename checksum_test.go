@@ -0,0 +1,84 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithChecksum(t *testing.T) {
+	content := []byte("hello, world")
+	sum := sha256.Sum256(content)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("match", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := client.Download(context.Background(), "/", &buf, httpclient.WithChecksum(crypto.SHA256, hex.EncodeToString(sum[:])))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, buf.Bytes()).Is(DeepEqual(content))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := client.Download(context.Background(), "/", &buf, httpclient.WithChecksum(crypto.SHA256, hex.EncodeToString(make([]byte, 32))))
+		ExpectThat(t, err).Is(Error(httpclient.ErrChecksumMismatch))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/", httpclient.WithChecksum(crypto.SHA256, "xyz"))
+		ExpectThat(t, err).Is(NotNil())
+	})
+}
+
+func TestVerifyContentDigest(t *testing.T) {
+	content := []byte(`{"name":"Alice"}`)
+	sum256 := sha256.Sum256(content)
+	sum512 := sha512.Sum512(content)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/content-digest":
+			w.Header().Set("Content-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum256[:])+":, sha-512=:"+base64.StdEncoding.EncodeToString(sum512[:])+":")
+		case "/digest":
+			w.Header().Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum256[:]))
+		case "/corrupt":
+			w.Header().Set("Content-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(make([]byte, 32))+":")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(content)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL), httpclient.VerifyContentDigest())
+
+	for _, path := range []string{"/content-digest", "/digest", "/none"} {
+		t.Run(path, func(t *testing.T) {
+			var v map[string]string
+			_, err := client.Get(context.Background(), path, httpclient.ForJSON(&v))
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, v["name"]).Is(Equal("Alice"))
+		})
+	}
+
+	t.Run("/corrupt", func(t *testing.T) {
+		var v map[string]string
+		_, err := client.Get(context.Background(), "/corrupt", httpclient.ForJSON(&v))
+		ExpectThat(t, err).Is(Error(httpclient.ErrChecksumMismatch))
+	})
+}
@@ -2,8 +2,12 @@ package httpclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"slices"
+	"time"
 )
 
 // RequestOption defines an interface for types that can be passed to requests
@@ -18,46 +22,282 @@ type ClientOption interface {
 	clientOpt()
 }
 
+// Option defines an interface for types that can be used both as a
+// ClientOption and as a RequestOption.
+type Option interface {
+	ClientOption
+	RequestOption
+}
+
 // HTTPClientOption is a ClientOption that customizes the http.Client in use.
 type HTTPClientOption func(*http.Client)
 
 func (HTTPClientOption) clientOpt() {}
 
+// clientConfigOption is a ClientOption that configures the Client itself.
+type clientConfigOption func(*Client)
+
+func (clientConfigOption) clientOpt() {}
+
+// transportOption is an HTTPClientOption replacing the client's transport.
+// It is created by WithTransport and WithHTTPClient.
+type transportOption struct {
+	HTTPClientOption
+}
+
 // WithTransport creates a ClientOption using t for the Client to be created.
 func WithTransport(t http.RoundTripper) ClientOption {
-	return HTTPClientOption(func(c *http.Client) {
+	return transportOption{func(c *http.Client) {
 		c.Transport = t
+	}}
+}
+
+// WithHTTPClient creates a ClientOption sending requests using a copy of c,
+// i.e. a pre-configured client returned by an authentication library. The
+// copy shares c's transport, cookie jar and redirect policy. c itself is
+// never modified; options customizing the http.Client or its transport
+// modify the copy and must be given after this option.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return transportOption{func(hc *http.Client) {
+		*hc = *c
+	}}
+}
+
+// WithDialTimeout creates a ClientOption that limits the time spent waiting for
+// a connection to be established to d. The option modifies a copy of the
+// client's *http.Transport, which defaults to a copy of
+// http.DefaultTransport. It has no effect if a different kind of
+// http.RoundTripper is in use.
+//
+// The dialer of http.DefaultTransport is replaced with one using d. A
+// DialContext function configured for a custom transport, i.e. a custom dialer
+// or proxy, is kept and called with a context limited to d. Any timeout
+// enforced by that function still applies, so the effective timeout is the
+// smaller of both.
+func WithDialTimeout(d time.Duration) ClientOption {
+	return HTTPClientOption(func(c *http.Client) {
+		isDefault := c.Transport == nil || c.Transport == http.DefaultTransport
+		modifyTransport(c, func(t *http.Transport) {
+			dial := t.DialContext
+			if dial == nil || isDefault {
+				t.DialContext = (&net.Dialer{
+					Timeout:   d,
+					KeepAlive: 30 * time.Second,
+				}).DialContext
+				return
+			}
+
+			t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				ctx, cancel := context.WithTimeout(ctx, d)
+				defer cancel()
+				return dial(ctx, network, addr)
+			}
+		})
 	})
 }
 
+// WithTLSHandshakeTimeout creates a ClientOption that limits the time spent
+// waiting for a TLS handshake to d. The option modifies a copy of the client's
+// *http.Transport, which defaults to a copy of http.DefaultTransport. It has
+// no effect if a different kind of http.RoundTripper is in use.
+func WithTLSHandshakeTimeout(d time.Duration) ClientOption {
+	return HTTPClientOption(func(c *http.Client) {
+		modifyTransport(c, func(t *http.Transport) {
+			t.TLSHandshakeTimeout = d
+		})
+	})
+}
+
+// WithRequestTimeout creates a ClientOption that limits the time spent on
+// each request to d. Unlike http.Client.Timeout, the limit is enforced using
+// the request's context and covers sending the request - including any
+// retries - and running the response interceptors, but not reading bodies
+// left open for the caller, i.e. streams consumed using Subscribe or bodies
+// of responses returned by Transport. Requests exceeding d fail with an
+// error of kind ErrTimeout.
+func WithRequestTimeout(d time.Duration) ClientOption {
+	return clientConfigOption(func(c *Client) {
+		c.timeout = d
+	})
+}
+
+// modifyTransport replaces c's transport with a modified copy of it if it is
+// an *http.Transport.
+func modifyTransport(c *http.Client, modify func(*http.Transport)) {
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return
+	}
+
+	t = t.Clone()
+	modify(t)
+	c.Transport = t
+}
+
 // Client implements a convenient HTTP client.
 type Client struct {
 	c               *http.Client
 	reqInterceptors []RequestInterceptor
 	resInterceptors []ResponseInterceptor
+	wrappers        []roundTripWrapper
+	misuse          *misuseDetector
+	events          *eventEmitter
+	clock           Clock
+	degradation     *degradation
+	timeout         time.Duration
+	resOrder        InterceptorOrder
+	jsonCodec       *jsonCodec
+	decoders        []registeredDecoder
+}
+
+// roundTripWrapper is implemented by options that need to observe a request
+// as it is sent - after all request interceptors have been applied - together
+// with the outcome of sending it. wrapRoundTrip must call next to send r.
+// Wrappers given first wrap the ones given later.
+type roundTripWrapper interface {
+	wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error)
 }
 
 // New create a new Client using the given opts to customize the client.
 // Calling New() with no options creates a fully usable Client using defaults.
+// New panics if any of opts is invalid; use NewE to handle invalid options
+// gracefully.
 func New(opts ...ClientOption) *Client {
+	c, err := NewE(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// NewE is like New but returns an error instead of panicking if any of opts
+// is invalid, i.e. because it is not supported as a ClientOption or because
+// WithURLPrefix has been given a malformed URL.
+func NewE(opts ...ClientOption) (*Client, error) {
 	c := &Client{
 		c: new(http.Client),
 	}
 
+	if err := c.apply(opts); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// With creates a new Client deriving from c that uses c's configuration -
+// including its transport and interceptors - extended by opts. Interceptors
+// given in opts run after those of c, so they can override their effect, i.e.
+// set a different header value.
+//
+// Deriving a client is cheap, which allows SDKs to expose a base client
+// with per-resource specializations. HTTPClientOptions given in opts modify
+// a copy of c's http.Client, so c is never affected by options given to
+// With. The degradation profile activated using SetProfile is shared with c
+// unless opts contain WithDegradationProfiles. Like New, With panics if any
+// of opts is invalid.
+func (c *Client) With(opts ...ClientOption) *Client {
+	hc := *c.c
+	d := *c
+	d.c = &hc
+	d.reqInterceptors = slices.Clip(c.reqInterceptors)
+	d.resInterceptors = slices.Clip(c.resInterceptors)
+	d.wrappers = slices.Clip(c.wrappers)
+
+	if err := d.apply(opts); err != nil {
+		panic(err)
+	}
+
+	return &d
+}
+
+// validatingOption is implemented by options - or by the interceptors held by
+// RequestInterceptorOptions - that validate their arguments when a Client is
+// created.
+type validatingOption interface {
+	validate() error
+}
+
+// invalidOption is a ClientOption reporting err when a Client is created.
+// Functions creating options return it if given invalid arguments.
+type invalidOption struct {
+	err error
+}
+
+func (invalidOption) clientOpt()        {}
+func (o invalidOption) validate() error { return o.err }
+
+// validate validates opt if it supports validation.
+func validate(opt ClientOption) error {
+	if o, ok := opt.(RequestInterceptorOption); ok {
+		if v, ok := o.RequestInterceptor.(validatingOption); ok {
+			return v.validate()
+		}
+	}
+
+	if v, ok := opt.(validatingOption); ok {
+		return v.validate()
+	}
+
+	return nil
+}
+
+// apply applies opts to c. It returns an error if any of opts is invalid.
+func (c *Client) apply(opts []ClientOption) error {
 	for _, opt := range opts {
-		switch o := opt.(type) {
-		case HTTPClientOption:
+		if err := validate(opt); err != nil {
+			return err
+		}
+
+		if o, ok := opt.(HTTPClientOption); ok {
 			o(c.c)
-		case RequestInterceptor:
-			c.reqInterceptors = append(c.reqInterceptors, o)
-		case ResponseInterceptor:
-			c.resInterceptors = append(c.resInterceptors, o)
-		default:
-			panic(fmt.Sprintf("unexpected option: %v", opt))
+			continue
+		}
+
+		if o, ok := opt.(transportOption); ok {
+			o.HTTPClientOption(c.c)
+			continue
+		}
+
+		if o, ok := opt.(clientConfigOption); ok {
+			o(c)
+			continue
+		}
+
+		if o, ok := opt.(withoutInterceptor); ok {
+			c.reqInterceptors = removeNamed(c.reqInterceptors, string(o))
+			c.resInterceptors = removeNamed(c.resInterceptors, string(o))
+			continue
+		}
+
+		var handled bool
+
+		if w, ok := opt.(roundTripWrapper); ok {
+			c.wrappers = append(c.wrappers, w)
+			handled = true
+		}
+
+		if i, ok := opt.(RequestInterceptor); ok {
+			c.reqInterceptors = addNamed(c.reqInterceptors, i)
+			handled = true
+		}
+
+		if i, ok := opt.(ResponseInterceptor); ok {
+			c.resInterceptors = addNamed(c.resInterceptors, i)
+			handled = true
+		}
+
+		if !handled {
+			return fmt.Errorf("unexpected option: %v", opt)
 		}
 	}
 
-	return c
+	return nil
 }
 
 // Get executes a HTTP GET request for url using ctx and opts. It returns the
@@ -73,6 +313,34 @@ func (c *Client) Post(ctx context.Context, url string, opts ...RequestOption) (*
 	return c.Execute(ctx, http.MethodPost, url, opts...)
 }
 
+// Put executes a HTTP PUT request for url using ctx and opts. The request
+// body is set using a RequestInterceptor, i.e. WithJSON.
+func (c *Client) Put(ctx context.Context, url string, opts ...RequestOption) (*http.Response, error) {
+	return c.Execute(ctx, http.MethodPut, url, opts...)
+}
+
+// Patch executes a HTTP PATCH request for url using ctx and opts. The request
+// body is set using a RequestInterceptor, i.e. WithJSON.
+func (c *Client) Patch(ctx context.Context, url string, opts ...RequestOption) (*http.Response, error) {
+	return c.Execute(ctx, http.MethodPatch, url, opts...)
+}
+
+// Delete executes a HTTP DELETE request for url using ctx and opts.
+func (c *Client) Delete(ctx context.Context, url string, opts ...RequestOption) (*http.Response, error) {
+	return c.Execute(ctx, http.MethodDelete, url, opts...)
+}
+
+// Head executes a HTTP HEAD request for url using ctx and opts.
+func (c *Client) Head(ctx context.Context, url string, opts ...RequestOption) (*http.Response, error) {
+	return c.Execute(ctx, http.MethodHead, url, opts...)
+}
+
+// Options executes a HTTP OPTIONS request for url using ctx and opts.
+func (c *Client) Options(ctx context.Context, url string, opts ...RequestOption) (*http.Response, error) {
+	return c.Execute(ctx, http.MethodOptions, url, opts...)
+}
+
+// Execute executes a HTTP request for url using method, ctx and opts.
 func (c *Client) Execute(ctx context.Context, method string, url string, opts ...RequestOption) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
@@ -82,46 +350,173 @@ func (c *Client) Execute(ctx context.Context, method string, url string, opts ..
 	return c.Do(req, opts...)
 }
 
-// Do executes req applying any opts and returns the received response as well
-// as any error.
+// Do executes req applying any opts - preceded by the options attached to
+// req's context using ContextWithOptions - and returns the received response
+// as well as any error. If a request ID has been assigned to req, i.e. using
+// WithRequestID, any error is returned wrapped in a *RequestIDError.
 func (c *Client) Do(req *http.Request, opts ...RequestOption) (*http.Response, error) {
-	var err error
+	return c.do(req, opts, false)
+}
 
-	for _, i := range c.reqInterceptors {
-		req, err = i.InterceptRequest(req)
+// applyRequestTimeout returns a copy of r whose context is cancelled after d
+// unless the returned stop function is called first. stop reports whether it
+// stopped the timeout before it expired. The returned CancelFunc must be
+// called once the request has been completed.
+func applyRequestTimeout(r *http.Request, d time.Duration) (*http.Request, func() bool, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(r.Context())
+	t := time.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+	return r.WithContext(ctx), t.Stop, func() { cancel(context.Canceled) }
+}
+
+// do implements Do. If keepBody is true, the body of the returned response is
+// left open for the caller to consume.
+func (c *Client) do(req *http.Request, opts []RequestOption, keepBody bool) (res *http.Response, err error) {
+	if ctxOpts := optionsFromContext(req.Context()); len(ctxOpts) > 0 {
+		opts = slices.Concat(ctxOpts, opts)
+	}
+
+	defer func() {
+		if err != nil {
+			err = annotateRequestID(req, err)
+		}
+	}()
+
+	if c.misuse != nil {
+		release, err := c.misuse.check(c, opts)
+		defer release()
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if c.clock != nil {
+		req = req.WithContext(ContextWithClock(req.Context(), c.clock))
+	}
+
+	if c.jsonCodec != nil {
+		req = req.WithContext(context.WithValue(req.Context(), jsonCodecKey{}, c.jsonCodec))
+	}
+
+	if c.decoders != nil {
+		req = req.WithContext(context.WithValue(req.Context(), decodersKey{}, c.decoders))
+	}
+
+	overridden := overriddenNames(opts)
+
+	reqInterceptors := make([]RequestInterceptor, 0, len(c.reqInterceptors)+len(opts))
+	for _, i := range c.reqInterceptors {
+		if !overridden[nameOf(i)] {
+			reqInterceptors = append(reqInterceptors, i)
+		}
+	}
 	for _, opt := range opts {
 		if i, ok := opt.(RequestInterceptor); ok {
-			req, err = i.InterceptRequest(req)
-			if err != nil {
-				return nil, err
+			reqInterceptors = append(reqInterceptors, i)
+		}
+	}
+	sortByRequestPhase(reqInterceptors)
+
+	for _, i := range reqInterceptors {
+		req, err = interceptRequest(i, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.degradation != nil {
+		var done context.CancelFunc
+		req, done, err = c.degradation.apply(req)
+		defer func() {
+			// A body left open for the caller is read under the profile's
+			// context, so it is only cancelled once the body is closed.
+			if err == nil && keepBody && res != nil && res.Body != nil {
+				res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: done}
+				return
 			}
+			done()
+		}()
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	res, err := c.c.Do(req)
+	if c.timeout > 0 {
+		var stop func() bool
+		var cancel context.CancelFunc
+		req, stop, cancel = applyRequestTimeout(req, c.timeout)
+		defer func() {
+			// Bodies left open for the caller are not limited by the timeout
+			// and are read until they are closed.
+			if err == nil && keepBody && res != nil && res.Body != nil && stop() {
+				res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+				return
+			}
+			cancel()
+		}()
+	}
+
+	applyDefaultUserAgent(req)
+
+	send := c.c.Do
+	wrappers := make([]roundTripWrapper, 0, len(c.wrappers)+len(opts)+1)
+	wrappers = append(wrappers, c.wrappers...)
+	for _, opt := range opts {
+		if w, ok := opt.(roundTripWrapper); ok {
+			wrappers = append(wrappers, w)
+		}
+	}
+	if c.events != nil {
+		// The emitter is the innermost wrapper, so every attempt made by
+		// WithRetry emits its own events.
+		wrappers = append(wrappers, c.events)
+	}
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		w, next := wrappers[i], send
+		send = func(r *http.Request) (*http.Response, error) {
+			return w.wrapRoundTrip(r, next)
+		}
+	}
+
+	res, err = send(req)
 	if err != nil {
+		if IsTimeout(err) || errors.Is(context.Cause(req.Context()), context.DeadlineExceeded) {
+			err = newRequestError(ErrTimeout, req, err)
+		}
 		return res, err
 	}
-	defer res.Body.Close()
+	if !keepBody {
+		// Interceptors may replace the body, i.e. to spool it to disk, so
+		// the final body is closed as well.
+		body := res.Body
+		defer func() {
+			body.Close()
+			if res != nil && res.Body != body {
+				res.Body.Close()
+			}
+		}()
+	}
 
+	resInterceptors := make([]ResponseInterceptor, 0, len(c.resInterceptors)+len(opts))
 	for _, i := range c.resInterceptors {
-		res, err = i.InterceptResponse(res)
-		if err != nil {
-			return res, err
+		if !overridden[nameOf(i)] {
+			resInterceptors = append(resInterceptors, i)
 		}
 	}
-
+	clientLevel := len(resInterceptors)
 	for _, opt := range opts {
 		if i, ok := opt.(ResponseInterceptor); ok {
-			res, err = i.InterceptResponse(res)
-			if err != nil {
-				return res, err
-			}
+			resInterceptors = append(resInterceptors, i)
+		}
+	}
+	if c.resOrder == RequestInterceptorsFirst {
+		resInterceptors = slices.Concat(resInterceptors[clientLevel:], resInterceptors[:clientLevel])
+	}
+	sortByPhase(resInterceptors)
+
+	for _, i := range resInterceptors {
+		res, err = interceptResponse(i, res)
+		if err != nil {
+			return res, err
 		}
 	}
 
@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/halimath/expect-go"
+)
+
+func TestTransportOptions(t *testing.T) {
+	c := New(
+		WithDialTimeout(time.Second),
+		WithTLSHandshakeTimeout(2*time.Second),
+	)
+
+	tr, ok := c.c.Transport.(*http.Transport)
+	expect.ExpectThat(t, ok).Is(expect.Equal(true))
+	expect.ExpectThat(t, tr.TLSHandshakeTimeout).Is(expect.Equal(2 * time.Second))
+	expect.ExpectThat(t, tr.DialContext).Is(expect.NotNil())
+	expect.ExpectThat(t, tr.MaxIdleConns).Is(expect.Equal(http.DefaultTransport.(*http.Transport).MaxIdleConns))
+	expect.ExpectThat(t, http.DefaultTransport.(*http.Transport).TLSHandshakeTimeout).Is(expect.Equal(10 * time.Second))
+}
+
+func TestWithDialTimeout_keepsDialContext(t *testing.T) {
+	var deadline time.Time
+	var called bool
+
+	tr := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			called = true
+			deadline, _ = ctx.Deadline()
+			return nil, errors.New("dial refused")
+		},
+	}
+
+	c := New(
+		WithTransport(tr),
+		WithDialTimeout(time.Second),
+	)
+
+	_, err := c.Get(context.Background(), "http://example.com/")
+	expect.ExpectThat(t, err).Is(expect.NotNil())
+	expect.ExpectThat(t, called).Is(expect.Equal(true))
+	expect.ExpectThat(t, time.Until(deadline) <= time.Second).Is(expect.Equal(true))
+	expect.ExpectThat(t, deadline.IsZero()).Is(expect.Equal(false))
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	hc := &http.Client{Transport: transport, Timeout: time.Minute}
+
+	c := New(WithHTTPClient(hc), WithTLSHandshakeTimeout(time.Second))
+
+	expect.ExpectThat(t, c.c != hc).Is(expect.Equal(true))
+	expect.ExpectThat(t, c.c.Timeout).Is(expect.Equal(time.Minute))
+	expect.ExpectThat(t, c.c.Transport.(*http.Transport).TLSHandshakeTimeout).Is(expect.Equal(time.Second))
+	expect.ExpectThat(t, hc.Transport.(*http.Transport).TLSHandshakeTimeout).Is(expect.Equal(transport.TLSHandshakeTimeout))
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("streamed"))
+	}))
+	defer testServer.Close()
+
+	c := New(WithURLPrefix(testServer.URL), WithRequestTimeout(50*time.Millisecond))
+
+	_, err := c.Get(context.Background(), "/slow")
+	expect.ExpectThat(t, errors.Is(err, ErrTimeout)).Is(expect.Equal(true))
+
+	hc := &http.Client{Transport: c.Transport()}
+	res, err := hc.Get(testServer.URL + "/stream")
+	expect.ExpectThat(t, err).Is(expect.NoError())
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	expect.ExpectThat(t, err).Is(expect.NoError())
+	expect.ExpectThat(t, string(body)).Is(expect.Equal("streamed"))
+}
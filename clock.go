@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"context"
+	"time"
+)
+
+// Clock abstracts the passing of time for all time dependent processing of a
+// Client, such as measuring durations or injecting latency. Tests can supply
+// a Clock using WithClock to control time instead of waiting for it to pass.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for d to elapse and then sends the current time on the
+	// returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock backed by package time. It is used unless a
+// different Clock is given using WithClock.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock creates a ClientOption making the Client use clock. The clock is
+// passed to interceptors and transports with the request's context and can
+// be obtained using ClockFromContext.
+func WithClock(clock Clock) ClientOption {
+	return clientConfigOption(func(c *Client) {
+		c.clock = clock
+	})
+}
+
+// clockKey is the context key used to store the Clock.
+type clockKey struct{}
+
+// ContextWithClock returns a copy of ctx carrying clock.
+func ContextWithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// ClockFromContext returns the Clock stored in ctx or SystemClock if ctx
+// carries no Clock. Interceptors and transports should use it for all time
+// dependent processing.
+func ClockFromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	return SystemClock
+}
+
+// sleep waits for d to elapse on clock. It returns early with the context's
+// error if ctx is done before.
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,169 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CoalescingKeyFunc computes the key used by WithCoalescing to identify
+// identical requests from r and its body. Requests with the same key are
+// coalesced. Returning an empty key excludes r from coalescing.
+type CoalescingKeyFunc func(r *http.Request, body []byte) string
+
+// credentialHeaders lists the headers carrying credentials. Requests only
+// share a response if they agree on all of them.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// CoalescePOSTByBody is a CoalescingKeyFunc coalescing POST requests sent to
+// the same URL with identical bodies and identical credentials, i.e. the
+// same Authorization, Proxy-Authorization and Cookie headers. All other
+// requests are excluded.
+func CoalescePOSTByBody(r *http.Request, body []byte) string {
+	if r.Method != http.MethodPost {
+		return ""
+	}
+
+	h := sha256.New()
+	for _, name := range credentialHeaders {
+		for _, v := range r.Header.Values(name) {
+			fmt.Fprintf(h, "%s: %q\n", name, v)
+		}
+	}
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return r.URL.String() + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// WithCoalescing creates a ClientOption that merges identical requests into a
+// single upstream call. This is meant for APIs that use POST for read-only
+// operations, such as search endpoints, which are not covered by caching.
+//
+// Requests are identified by the key computed by key. The first request with
+// a key is sent; any request with the same key issued within window after the
+// first one has been sent - while it is still in flight or after it has
+// completed - receives a copy of the same response or error. Responses are
+// buffered completely to be shared.
+//
+// The upstream call is not canceled when the context of the first request is
+// done, as other requests may still wait for it. Every request stops waiting
+// once its own context is done. Options replacing the transport must be given
+// before this option.
+func WithCoalescing(key CoalescingKeyFunc, window time.Duration) ClientOption {
+	return HTTPClientOption(func(c *http.Client) {
+		next := c.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		c.Transport = &coalescingTransport{
+			key:    key,
+			window: window,
+			next:   next,
+			calls:  make(map[string]*coalescedCall),
+		}
+	})
+}
+
+type coalescingTransport struct {
+	key    CoalescingKeyFunc
+	window time.Duration
+	next   http.RoundTripper
+
+	mutex sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall captures the outcome of an upstream call shared by all
+// coalesced requests.
+type coalescedCall struct {
+	started time.Time
+	done    chan struct{}
+	res     *http.Response
+	body    []byte
+	err     error
+}
+
+func (t *coalescingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	key := t.key(req, body)
+	if key == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	clock := ClockFromContext(ctx)
+	now := clock.Now()
+
+	t.mutex.Lock()
+	call, ok := t.calls[key]
+	if !ok || now.Sub(call.started) > t.window {
+		call = &coalescedCall{started: now, done: make(chan struct{})}
+		t.calls[key] = call
+		go t.send(key, call, req.Clone(context.WithoutCancel(ctx)))
+	}
+	t.mutex.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.done:
+	}
+
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	res := *call.res
+	res.Header = call.res.Header.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(call.body))
+	res.Request = req
+	return &res, nil
+}
+
+func (t *coalescingTransport) send(key string, call *coalescedCall, req *http.Request) {
+	defer close(call.done)
+	defer t.expire(key, call, ClockFromContext(req.Context()))
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		call.err = err
+		return
+	}
+
+	call.body, call.err = io.ReadAll(res.Body)
+	res.Body.Close()
+	call.res = res
+}
+
+// expire removes call once its window has passed so completed calls don't
+// accumulate.
+func (t *coalescingTransport) expire(key string, call *coalescedCall, clock Clock) {
+	go func() {
+		if remaining := t.window - clock.Now().Sub(call.started); remaining > 0 {
+			<-clock.After(remaining)
+		}
+
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		if t.calls[key] == call {
+			delete(t.calls, key)
+		}
+	}()
+}
@@ -0,0 +1,100 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestWithCoalescing(t *testing.T) {
+	var calls atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		b, _ := io.ReadAll(r.Body)
+		w.Write([]byte("result for " + string(b)))
+	}))
+	defer testServer.Close()
+
+	clock := httpclienttest.NewFakeClock(time.Now())
+
+	client := httpclient.New(
+		httpclient.WithCoalescing(httpclient.CoalescePOSTByBody, time.Minute),
+		httpclient.WithClock(clock),
+		httpclient.WithURLPrefix(testServer.URL),
+	)
+
+	search := func(query string) string {
+		var body string
+		_, err := client.Post(context.Background(), "/search",
+			httpclient.WithBody(strings.NewReader(query), "text/plain", int64(len(query))),
+			httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+				b, err := io.ReadAll(r.Body)
+				body = string(b)
+				return r, err
+			}),
+		)
+		ExpectThat(t, err).Is(NoError())
+		return body
+	}
+
+	ExpectThat(t, search("go")).Is(Equal("result for go"))
+	ExpectThat(t, search("go")).Is(Equal("result for go"))
+	ExpectThat(t, calls.Load()).Is(Equal(int32(1)))
+
+	ExpectThat(t, search("rust")).Is(Equal("result for rust"))
+	ExpectThat(t, calls.Load()).Is(Equal(int32(2)))
+
+	clock.Advance(2 * time.Minute)
+
+	ExpectThat(t, search("go")).Is(Equal("result for go"))
+	ExpectThat(t, calls.Load()).Is(Equal(int32(3)))
+
+	_, err := client.Get(context.Background(), "/search")
+	ExpectThat(t, err).Is(NoError())
+	_, err = client.Get(context.Background(), "/search")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, calls.Load()).Is(Equal(int32(5)))
+}
+
+func TestWithCoalescing_credentials(t *testing.T) {
+	var calls atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte("result for " + r.Header.Get("Authorization")))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithCoalescing(httpclient.CoalescePOSTByBody, time.Minute),
+		httpclient.WithURLPrefix(testServer.URL),
+	)
+
+	search := func(auth string) string {
+		var body string
+		_, err := client.Post(context.Background(), "/search",
+			httpclient.WithBody(strings.NewReader("go"), "text/plain", 2),
+			httpclient.WithRequestHeader("Authorization", auth),
+			httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+				b, err := io.ReadAll(r.Body)
+				body = string(b)
+				return r, err
+			}),
+		)
+		ExpectThat(t, err).Is(NoError())
+		return body
+	}
+
+	ExpectThat(t, search("Bearer alice")).Is(Equal("result for Bearer alice"))
+	ExpectThat(t, search("Bearer bob")).Is(Equal("result for Bearer bob"))
+	ExpectThat(t, search("Bearer alice")).Is(Equal("result for Bearer alice"))
+	ExpectThat(t, calls.Load()).Is(Equal(int32(2)))
+}
@@ -0,0 +1,36 @@
+package httpclient
+
+import (
+	"context"
+)
+
+// jsonCodec holds the functions used to marshal and unmarshal JSON.
+type jsonCodec struct {
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte, any) error
+}
+
+// WithJSONCodec creates a ClientOption replacing encoding/json with marshal
+// and unmarshal for all JSON processing performed by WithJSON and ForJSON,
+// i.e. to use a faster third party implementation:
+//
+//	httpclient.New(httpclient.WithJSONCodec(jsoniter.Marshal, jsoniter.Unmarshal))
+//
+// With a custom codec, ForJSON reads the whole response body - limited by
+// MaxJSONSize - before passing it to unmarshal, so StreamJSON has no effect.
+// DisallowUnknownFields has no effect either; configure the codec instead.
+func WithJSONCodec(marshal func(any) ([]byte, error), unmarshal func([]byte, any) error) ClientOption {
+	return clientConfigOption(func(c *Client) {
+		c.jsonCodec = &jsonCodec{marshal: marshal, unmarshal: unmarshal}
+	})
+}
+
+// jsonCodecKey is the context key used to store the jsonCodec.
+type jsonCodecKey struct{}
+
+// jsonCodecFromContext returns the jsonCodec stored in ctx or nil if the
+// Client uses encoding/json.
+func jsonCodecFromContext(ctx context.Context) *jsonCodec {
+	codec, _ := ctx.Value(jsonCodecKey{}).(*jsonCodec)
+	return codec
+}
@@ -0,0 +1,45 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithJSONCodec(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.Copy(w, r.Body)
+	}))
+	defer testServer.Close()
+
+	var marshaled, unmarshaled int
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithJSONCodec(
+			func(v any) ([]byte, error) {
+				marshaled++
+				return json.Marshal(v)
+			},
+			func(data []byte, v any) error {
+				unmarshaled++
+				return json.Unmarshal(data, v)
+			},
+		),
+	)
+
+	var got string
+	_, err := client.Post(context.Background(), "/", httpclient.WithJSON("hello, world"), httpclient.ForJSON(&got))
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, got).Is(Equal("hello, world"))
+	ExpectThat(t, marshaled).Is(Equal(1))
+	ExpectThat(t, unmarshaled).Is(Equal(1))
+}
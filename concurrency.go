@@ -0,0 +1,81 @@
+package httpclient
+
+import "net/http"
+
+// WithOptimisticConcurrency creates an Option implementing optimistic
+// concurrency control using entity tags. The ETag of responses to GET and
+// HEAD requests is stored in store keyed by the request's URL. PUT, PATCH
+// and DELETE requests for a URL whose ETag is known carry an If-Match header,
+// so the server rejects the change if the resource has been modified in the
+// meantime. Such a 412 Precondition Failed response is reported as an *Error
+// of kind ErrConflict:
+//
+//	_, err := client.Get(ctx, "/orders/1", httpclient.ForJSON(&order))
+//	// modify order
+//	_, err = client.Put(ctx, "/orders/1", httpclient.WithJSON(order))
+//	if errors.Is(err, httpclient.ErrConflict) {
+//		// reload order and try again
+//	}
+//
+// The ETag of successful responses to changes is stored as well, so a
+// sequence of changes works without reloading the resource in between. As
+// with WithConditionalRequests, ETags are only stored once the response has
+// been processed successfully by all other interceptors. Requests already
+// carrying an If-Match header are sent as is. store may be shared with
+// WithConditionalRequests.
+func WithOptimisticConcurrency(store ValidatorStore) Option {
+	return &optimisticConcurrency{store: store}
+}
+
+type optimisticConcurrency struct {
+	store ValidatorStore
+}
+
+func (*optimisticConcurrency) clientOpt() {}
+func (*optimisticConcurrency) reqOpt()    {}
+
+func (*optimisticConcurrency) Phase() Phase { return PhasePostValidate }
+
+func (o *optimisticConcurrency) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if !modifying(r) || r.Header.Get("If-Match") != "" {
+		return next(r)
+	}
+
+	v, ok := o.store.Get(r.URL.String())
+	if !ok || v.ETag == "" {
+		return next(r)
+	}
+
+	out := r.WithContext(r.Context())
+	out.Header = r.Header.Clone()
+	out.Header.Set("If-Match", v.ETag)
+
+	res, err := next(out)
+	if err != nil || res.StatusCode != http.StatusPreconditionFailed {
+		return res, err
+	}
+
+	res.Body.Close()
+	return res, newResponseError(ErrConflict, res, nil)
+}
+
+func (o *optimisticConcurrency) InterceptResponse(r *http.Response) (*http.Response, error) {
+	if r.Request == nil || r.Header.Get("ETag") == "" {
+		return r, nil
+	}
+
+	if (conditional(r.Request) && r.StatusCode == http.StatusOK) || (modifying(r.Request) && r.StatusCode >= 200 && r.StatusCode <= 299) {
+		o.store.Set(r.Request.URL.String(), Validators{
+			ETag:         r.Header.Get("ETag"),
+			LastModified: r.Header.Get("Last-Modified"),
+		})
+	}
+
+	return r, nil
+}
+
+// modifying reports whether r uses a method changing the resource for which
+// WithOptimisticConcurrency sends an If-Match header.
+func modifying(r *http.Request) bool {
+	return r.Method == http.MethodPut || r.Method == http.MethodPatch || r.Method == http.MethodDelete
+}
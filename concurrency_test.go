@@ -0,0 +1,54 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithOptimisticConcurrency(t *testing.T) {
+	version := 1
+	var ifMatch []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + strconv.Itoa(version) + `"`
+		if r.Method == http.MethodGet {
+			w.Header().Set("ETag", etag)
+			return
+		}
+
+		ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+		if r.Header.Get("If-Match") != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		version++
+		w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithOptimisticConcurrency(&httpclient.MemoryValidatorStore{}),
+	)
+
+	_, err := client.Get(context.Background(), "/orders/1")
+	ExpectThat(t, err).Is(NoError())
+
+	_, err = client.Put(context.Background(), "/orders/1")
+	ExpectThat(t, err).Is(NoError())
+
+	_, err = client.Patch(context.Background(), "/orders/1")
+	ExpectThat(t, err).Is(NoError())
+
+	version = 5
+	_, err = client.Put(context.Background(), "/orders/1")
+	ExpectThat(t, err).Is(Error(httpclient.ErrConflict))
+
+	ExpectThat(t, ifMatch).Is(DeepEqual([]string{`"1"`, `"2"`, `"3"`}))
+}
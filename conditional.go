@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Validators are the validators of a resource - its entity tag and
+// modification time - as sent by the server. They are used to make
+// conditional requests.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ValidatorStore stores the Validators of resources keyed by their URL. Use
+// MemoryValidatorStore or implement the interface to persist validators,
+// i.e. in a database shared by multiple pollers.
+type ValidatorStore interface {
+	// Get returns the validators stored for url.
+	Get(url string) (Validators, bool)
+
+	// Set stores v for url.
+	Set(url string, v Validators)
+}
+
+// MemoryValidatorStore is a ValidatorStore keeping validators in memory. The
+// zero value is ready to use. It is safe for concurrent use.
+type MemoryValidatorStore struct {
+	m sync.Map
+}
+
+func (s *MemoryValidatorStore) Get(url string) (Validators, bool) {
+	v, ok := s.m.Load(url)
+	if !ok {
+		return Validators{}, false
+	}
+	return v.(Validators), true
+}
+
+func (s *MemoryValidatorStore) Set(url string, v Validators) {
+	s.m.Store(url, v)
+}
+
+// WithConditionalRequests creates an Option making GET and HEAD requests
+// conditional using the validators stored in store. Requests for a URL whose
+// validators are known carry an If-None-Match and an If-Modified-Since
+// header. A 304 Not Modified response to such a request is reported as an
+// *Error of kind ErrNotModified, so decoders such as ForJSON don't run:
+//
+//	_, err := client.Get(ctx, "/config", httpclient.ForJSON(&cfg))
+//	if errors.Is(err, httpclient.ErrNotModified) {
+//		// keep using the current cfg
+//	}
+//
+// The validators of 200 OK responses are stored once the response has been
+// processed successfully by all other interceptors, so a response that
+// failed to decode is never reported as not modified later on. Requests
+// already carrying an If-None-Match or If-Modified-Since header are sent as
+// is. Unlike a cache, no response bodies are stored.
+func WithConditionalRequests(store ValidatorStore) Option {
+	return &conditionalRequests{store: store}
+}
+
+type conditionalRequests struct {
+	store ValidatorStore
+}
+
+func (*conditionalRequests) clientOpt() {}
+func (*conditionalRequests) reqOpt()    {}
+
+func (*conditionalRequests) Phase() Phase { return PhasePostValidate }
+
+func (c *conditionalRequests) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if !conditional(r) || r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+		return next(r)
+	}
+
+	v, ok := c.store.Get(r.URL.String())
+	if !ok || (v.ETag == "" && v.LastModified == "") {
+		return next(r)
+	}
+
+	out := r.WithContext(r.Context())
+	out.Header = r.Header.Clone()
+	if v.ETag != "" {
+		out.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		out.Header.Set("If-Modified-Since", v.LastModified)
+	}
+
+	res, err := next(out)
+	if err != nil || res.StatusCode != http.StatusNotModified {
+		return res, err
+	}
+
+	res.Body.Close()
+	return res, newResponseError(ErrNotModified, res, nil)
+}
+
+func (c *conditionalRequests) InterceptResponse(r *http.Response) (*http.Response, error) {
+	if r.StatusCode == http.StatusOK && r.Request != nil && conditional(r.Request) {
+		c.store.Set(r.Request.URL.String(), Validators{
+			ETag:         r.Header.Get("ETag"),
+			LastModified: r.Header.Get("Last-Modified"),
+		})
+	}
+	return r, nil
+}
+
+// conditional reports whether r uses a method for which conditional
+// requests are made.
+func conditional(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
@@ -0,0 +1,57 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithConditionalRequests(t *testing.T) {
+	var ifNoneMatch []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 12:00:00 GMT")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"config"}`))
+	}))
+	defer testServer.Close()
+
+	store := &httpclient.MemoryValidatorStore{}
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithConditionalRequests(store),
+	)
+
+	var v struct {
+		Name string `json:"name"`
+	}
+	_, err := client.Get(context.Background(), "/config", httpclient.ForJSON(&v))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, v.Name).Is(Equal("config"))
+
+	validators, ok := store.Get(testServer.URL + "/config")
+	ExpectThat(t, ok).Is(Equal(true))
+	ExpectThat(t, validators).Is(DeepEqual(httpclient.Validators{
+		ETag:         `"v1"`,
+		LastModified: "Wed, 01 May 2024 12:00:00 GMT",
+	}))
+
+	v.Name = ""
+	_, err = client.Get(context.Background(), "/config", httpclient.ForJSON(&v))
+	ExpectThat(t, err).Is(Error(httpclient.ErrNotModified))
+	ExpectThat(t, v.Name).Is(Equal(""))
+
+	_, err = client.Get(context.Background(), "/config", httpclient.WithRequestHeader("If-None-Match", `"v0"`))
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, ifNoneMatch).Is(DeepEqual([]string{"", `"v1"`, `"v0"`}))
+}
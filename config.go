@@ -0,0 +1,348 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration that is represented as a string such as "1.5s"
+// when marshaled to or unmarshaled from text, i.e. JSON or YAML. It is used
+// by Config.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config is a declarative configuration of a Client. It consists of plain
+// values only, so it can be unmarshaled from configuration files, i.e. JSON
+// or YAML, or loaded from environment variables using LoadEnv. Zero values
+// keep the respective defaults.
+type Config struct {
+	// BaseURL is used as a prefix for requests with relative URLs. See
+	// WithURLPrefix.
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty" env:"BASE_URL"`
+
+	// Timeout limits the time spent on a request. Bodies left open for the
+	// caller, i.e. streams, are not limited. See WithRequestTimeout.
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" env:"TIMEOUT"`
+
+	// DialTimeout limits the time spent establishing a connection. See
+	// WithDialTimeout.
+	DialTimeout Duration `json:"dialTimeout,omitempty" yaml:"dialTimeout,omitempty" env:"DIAL_TIMEOUT"`
+
+	// TLSHandshakeTimeout limits the time spent for the TLS handshake. See
+	// WithTLSHandshakeTimeout.
+	TLSHandshakeTimeout Duration `json:"tlsHandshakeTimeout,omitempty" yaml:"tlsHandshakeTimeout,omitempty" env:"TLS_HANDSHAKE_TIMEOUT"`
+
+	// Proxy is the URL of the proxy to send requests through. If empty, the
+	// proxy is taken from the environment as done by http.DefaultTransport.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty" env:"PROXY"`
+
+	// Headers are sent with every request. See WithDefaultHeaders.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" env:"HEADERS"`
+
+	// TLS configures TLS connections.
+	TLS TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty" env:"TLS"`
+
+	// Retry configures retries. See WithRetry.
+	Retry RetryConfig `json:"retry,omitempty" yaml:"retry,omitempty" env:"RETRY"`
+}
+
+// TLSConfig is the part of a Config configuring TLS connections.
+type TLSConfig struct {
+	// CAFile is the path of a PEM encoded file containing the certificates
+	// used to verify servers instead of the system's root certificates.
+	CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty" env:"CA_FILE"`
+
+	// CertFile and KeyFile are the paths of PEM encoded files containing a
+	// client certificate and its private key.
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty" env:"CERT_FILE"`
+	KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty" env:"KEY_FILE"`
+
+	// ServerName overrides the name used to verify server certificates.
+	ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty" env:"SERVER_NAME"`
+
+	// MinVersion is the minimum accepted TLS version, either "1.2" or "1.3".
+	MinVersion string `json:"minVersion,omitempty" yaml:"minVersion,omitempty" env:"MIN_VERSION"`
+
+	// InsecureSkipVerify disables the verification of server certificates.
+	// It should only be used for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" env:"INSECURE_SKIP_VERIFY"`
+}
+
+// RetryConfig is the part of a Config configuring retries. See RetryPolicy
+// for the meaning of its fields.
+type RetryConfig struct {
+	MaxAttempts      int      `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty" env:"MAX_ATTEMPTS"`
+	InitialBackoff   Duration `json:"initialBackoff,omitempty" yaml:"initialBackoff,omitempty" env:"INITIAL_BACKOFF"`
+	MaxBackoff       Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty" env:"MAX_BACKOFF"`
+	RetryStatusCodes []int    `json:"retryStatusCodes,omitempty" yaml:"retryStatusCodes,omitempty" env:"STATUS_CODES"`
+}
+
+// NewFromConfig creates a new Client configured by cfg. opts are applied
+// after the options derived from cfg, except for WithTransport and
+// WithHTTPClient, which are applied first, so the transport settings of cfg
+// - Proxy, TLS, DialTimeout and TLSHandshakeTimeout - apply to the transport
+// they provide. It returns an error if cfg contains invalid values, if the
+// files referenced by cfg.TLS can't be loaded, if any of opts is invalid or
+// if cfg contains transport settings but opts provide a transport other than
+// an *http.Transport, which the settings can't be applied to.
+func NewFromConfig(cfg Config, opts ...ClientOption) (*Client, error) {
+	cfgOpts, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+
+	var replacing, others []ClientOption
+	for _, opt := range opts {
+		if _, ok := opt.(transportOption); ok {
+			replacing = append(replacing, opt)
+		} else {
+			others = append(others, opt)
+		}
+	}
+
+	if len(replacing) > 0 && cfg.hasTransportSettings() {
+		hc := new(http.Client)
+		for _, opt := range replacing {
+			opt.(transportOption).HTTPClientOption(hc)
+		}
+		if _, ok := hc.Transport.(*http.Transport); hc.Transport != nil && !ok {
+			return nil, fmt.Errorf("transport settings of Config can't be applied to a %T", hc.Transport)
+		}
+	}
+
+	return NewE(slices.Concat(replacing, cfgOpts, others)...)
+}
+
+// hasTransportSettings reports whether cfg configures the transport.
+func (cfg Config) hasTransportSettings() bool {
+	return cfg.Proxy != "" || cfg.TLS != (TLSConfig{}) || cfg.DialTimeout > 0 || cfg.TLSHandshakeTimeout > 0
+}
+
+// LoadEnv sets the fields of cfg from environment variables named by prefix
+// followed by the names given by the fields' env tags, i.e. "API_TIMEOUT"
+// for prefix "API_". The fields of TLS and Retry use the names of these
+// fields as an additional prefix, i.e. "API_TLS_CA_FILE". Durations are
+// given as strings such as "1.5s", Headers as a comma separated list of
+// name=value pairs and RetryStatusCodes as a comma separated list. Fields
+// without a corresponding variable are left unchanged, so LoadEnv can
+// override values loaded from a configuration file.
+func (cfg *Config) LoadEnv(prefix string) error {
+	return loadEnv(reflect.ValueOf(cfg).Elem(), prefix)
+}
+
+// loadEnv sets the fields of the struct v as described for Config.LoadEnv.
+func loadEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		name := t.Field(i).Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		f := v.Field(i)
+		if f.Kind() == reflect.Struct {
+			if err := loadEnv(f, prefix+name+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s, ok := os.LookupEnv(prefix + name)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(f, s); err != nil {
+			return fmt.Errorf("invalid %s: %w", prefix+name, err)
+		}
+	}
+
+	return nil
+}
+
+// setEnvValue sets v to the value given by the environment variable value s.
+func setEnvValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+
+	case reflect.Slice:
+		l := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, p := range strings.Split(s, ",") {
+			if p = strings.TrimSpace(p); p == "" {
+				continue
+			}
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := setEnvValue(e, p); err != nil {
+				return err
+			}
+			l = reflect.Append(l, e)
+		}
+		v.Set(l)
+
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, p := range strings.Split(s, ",") {
+			if strings.TrimSpace(p) == "" {
+				continue
+			}
+			name, value, ok := strings.Cut(p, "=")
+			if !ok {
+				return fmt.Errorf("expected name=value but got %q", p)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(name)), reflect.ValueOf(strings.TrimSpace(value)))
+		}
+		v.Set(m)
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// options returns the ClientOptions derived from cfg.
+func (cfg Config) options() ([]ClientOption, error) {
+	var opts []ClientOption
+
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		opts = append(opts, HTTPClientOption(func(c *http.Client) {
+			modifyTransport(c, func(t *http.Transport) {
+				t.Proxy = http.ProxyURL(u)
+			})
+		}))
+	}
+
+	tlsConfig, err := cfg.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, HTTPClientOption(func(c *http.Client) {
+			modifyTransport(c, func(t *http.Transport) {
+				t.TLSClientConfig = tlsConfig
+			})
+		}))
+	}
+
+	if cfg.DialTimeout > 0 {
+		opts = append(opts, WithDialTimeout(time.Duration(cfg.DialTimeout)))
+	}
+
+	if cfg.TLSHandshakeTimeout > 0 {
+		opts = append(opts, WithTLSHandshakeTimeout(time.Duration(cfg.TLSHandshakeTimeout)))
+	}
+
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithRequestTimeout(time.Duration(cfg.Timeout)))
+	}
+
+	if cfg.Retry.MaxAttempts > 1 {
+		opts = append(opts, WithRetry(RetryPolicy{
+			MaxAttempts:      cfg.Retry.MaxAttempts,
+			InitialBackoff:   time.Duration(cfg.Retry.InitialBackoff),
+			MaxBackoff:       time.Duration(cfg.Retry.MaxBackoff),
+			RetryStatusCodes: cfg.Retry.RetryStatusCodes,
+		}))
+	}
+
+	if cfg.BaseURL != "" {
+		opts = append(opts, WithURLPrefix(cfg.BaseURL))
+	}
+
+	if len(cfg.Headers) > 0 {
+		h := make(http.Header, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			h.Set(k, v)
+		}
+		opts = append(opts, WithDefaultHeaders(h))
+	}
+
+	return opts, nil
+}
+
+// build returns the tls.Config described by c or nil if c is empty.
+func (c TLSConfig) build() (*tls.Config, error) {
+	if c == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	switch c.MinVersion {
+	case "":
+	case "1.2":
+		cfg.MinVersion = tls.VersionTLS12
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS version: %s", c.MinVersion)
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,106 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	var requests int
+	var received http.Header
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		received = r.Header.Clone()
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var cfg httpclient.Config
+	err := json.Unmarshal([]byte(`{
+		"baseURL": "`+testServer.URL+`",
+		"timeout": "5s",
+		"dialTimeout": "1s",
+		"headers": {"x-tenant": "acme"},
+		"retry": {"maxAttempts": 2, "initialBackoff": "1ms"}
+	}`), &cfg)
+	ExpectThat(t, err).Is(NoError())
+
+	client, err := httpclient.NewFromConfig(cfg)
+	ExpectThat(t, err).Is(NoError())
+
+	res, err := client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusNoContent))
+	ExpectThat(t, requests).Is(Equal(2))
+	ExpectThat(t, received.Get("X-Tenant")).Is(Equal("acme"))
+
+	_, err = httpclient.NewFromConfig(httpclient.Config{TLS: httpclient.TLSConfig{MinVersion: "1.0"}})
+	ExpectThat(t, err).Is(NotNil())
+
+	_, err = httpclient.NewFromConfig(httpclient.Config{TLS: httpclient.TLSConfig{CAFile: "does-not-exist.pem"}})
+	ExpectThat(t, err).Is(NotNil())
+}
+
+func TestConfig_LoadEnv(t *testing.T) {
+	t.Setenv("API_BASE_URL", "https://api.example.com")
+	t.Setenv("API_TIMEOUT", "5s")
+	t.Setenv("API_HEADERS", "X-Tenant=acme, X-Region=eu")
+	t.Setenv("API_TLS_INSECURE_SKIP_VERIFY", "true")
+	t.Setenv("API_RETRY_MAX_ATTEMPTS", "3")
+	t.Setenv("API_RETRY_STATUS_CODES", "502,503")
+
+	cfg := httpclient.Config{Proxy: "http://proxy:3128"}
+	err := cfg.LoadEnv("API_")
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, cfg.BaseURL).Is(Equal("https://api.example.com"))
+	ExpectThat(t, time.Duration(cfg.Timeout)).Is(Equal(5 * time.Second))
+	ExpectThat(t, cfg.Proxy).Is(Equal("http://proxy:3128"))
+	ExpectThat(t, cfg.Headers).Is(DeepEqual(map[string]string{"X-Tenant": "acme", "X-Region": "eu"}))
+	ExpectThat(t, cfg.TLS.InsecureSkipVerify).Is(Equal(true))
+	ExpectThat(t, cfg.Retry.MaxAttempts).Is(Equal(3))
+	ExpectThat(t, cfg.Retry.RetryStatusCodes).Is(DeepEqual([]int{502, 503}))
+
+	t.Setenv("API_RETRY_MAX_ATTEMPTS", "three")
+	err = cfg.LoadEnv("API_")
+	ExpectThat(t, err).Is(NotNil())
+	ExpectThat(t, err.Error()).Is(StringContaining("API_RETRY_MAX_ATTEMPTS"))
+}
+
+func TestNewFromConfig_transport(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	t.Run("settings applied to given client", func(t *testing.T) {
+		cfg := httpclient.Config{TLS: httpclient.TLSConfig{InsecureSkipVerify: true}}
+		hc := &http.Client{Transport: &http.Transport{}}
+
+		client, err := httpclient.NewFromConfig(cfg, httpclient.WithHTTPClient(hc))
+		ExpectThat(t, err).Is(NoError())
+
+		res, err := client.Get(context.Background(), testServer.URL)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusNoContent))
+	})
+
+	t.Run("unsupported transport", func(t *testing.T) {
+		cfg := httpclient.Config{DialTimeout: httpclient.Duration(time.Second)}
+		rt := testServer.Client().Transport.(*http.Transport)
+
+		_, err := httpclient.NewFromConfig(cfg, httpclient.WithTransport(struct{ http.RoundTripper }{rt}))
+		ExpectThat(t, err).Is(NotNil())
+	})
+}
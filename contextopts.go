@@ -0,0 +1,41 @@
+package httpclient
+
+import (
+	"context"
+	"slices"
+)
+
+// contextOptionsKey is the context key used to store request options.
+type contextOptionsKey struct{}
+
+// ContextWithOptions returns a copy of ctx carrying opts in addition to any
+// options already attached to ctx. Requests sent with a context carrying
+// options apply them as if they had been given to Do, before the options
+// given explicitly. This allows code higher in the stack, i.e. an HTTP
+// handler, to attach options like headers to all requests sent on its
+// behalf.
+//
+// The options apply to all requests sent using ctx or a context derived from
+// it, including requests sent by code called on the caller's behalf. Such
+// code sending requests of its own, i.e. to fetch tokens, should use
+// ContextWithoutOptions to prevent the options from leaking into them.
+func ContextWithOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	return context.WithValue(ctx, contextOptionsKey{}, slices.Concat(optionsFromContext(ctx), opts))
+}
+
+// ContextWithoutOptions returns a copy of ctx carrying no options, so
+// requests sent using it are not affected by options attached to ctx using
+// ContextWithOptions. Other values of ctx, i.e. its deadline or Clock, are
+// kept.
+func ContextWithoutOptions(ctx context.Context) context.Context {
+	if optionsFromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextOptionsKey{}, []RequestOption(nil))
+}
+
+// optionsFromContext returns the options attached to ctx.
+func optionsFromContext(ctx context.Context) []RequestOption {
+	opts, _ := ctx.Value(contextOptionsKey{}).([]RequestOption)
+	return opts
+}
@@ -0,0 +1,55 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestContextWithOptions(t *testing.T) {
+	var received http.Header
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	ctx := httpclient.ContextWithOptions(context.Background(), httpclient.WithRequestHeader("X-Tenant", "acme"))
+	ctx = httpclient.ContextWithOptions(ctx,
+		httpclient.WithRequestHeader("X-Trace", "trace-1"),
+		httpclient.WithRequestHeader("X-Mode", "context"),
+	)
+
+	_, err := client.Get(ctx, "/", httpclient.WithRequestHeader("X-Mode", "explicit"))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("X-Tenant")).Is(Equal("acme"))
+	ExpectThat(t, received.Get("X-Trace")).Is(Equal("trace-1"))
+	ExpectThat(t, received.Get("X-Mode")).Is(Equal("explicit"))
+}
+
+func TestContextWithoutOptions(t *testing.T) {
+	var received http.Header
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	ctx := httpclient.ContextWithOptions(context.Background(), httpclient.WithRequestHeader("X-Tenant", "acme"))
+
+	_, err := client.Get(httpclient.ContextWithoutOptions(ctx), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("X-Tenant")).Is(Equal(""))
+
+	_, err = client.Get(ctx, "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("X-Tenant")).Is(Equal("acme"))
+}
@@ -0,0 +1,229 @@
+package httpclient
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ForCSV creates a RequestOption that decodes a text/csv response body into
+// dest, appending one element per record. T must be a struct type. The first
+// record of the body is expected to contain the column names, which are
+// mapped to the fields of T using their "csv" struct tag:
+//
+//	type Order struct {
+//		ID      int       `csv:"id"`
+//		Total   float64   `csv:"total"`
+//		Placed  time.Time `csv:"placed" layout:"2006-01-02"`
+//		Comment string    `csv:"-"`
+//	}
+//
+// Fields without a tag are mapped to the column matching the field's name
+// ignoring case; columns without a matching field are ignored. Fields may be
+// strings, booleans, integers, floating point numbers, time.Time values,
+// which are parsed using the layout given in the "layout" tag defaulting to
+// time.RFC3339, or implement encoding.TextUnmarshaler.
+//
+// The body is decoded record by record while it is read and is consumed
+// afterwards. If the response has a different content type or decoding
+// fails, an *Error of kind ErrDecode is returned. Use ForCSVRows to process
+// records one at a time instead of collecting them.
+func ForCSV[T any](dest *[]T) RequestOption {
+	return ForCSVRows(func(v T) error {
+		*dest = append(*dest, v)
+		return nil
+	})
+}
+
+// ForCSVRows is like ForCSV but passes each decoded record to f instead of
+// collecting them, so even huge exports are never held in memory. Any error
+// returned by f stops decoding and is returned as is.
+func ForCSVRows[T any](f func(T) error) RequestOption {
+	return &forCSV{decode: func(r *csv.Reader) error {
+		header, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return csvDecodeError{err}
+		}
+
+		fields, err := csvFieldIndexes(reflect.TypeFor[T](), header)
+		if err != nil {
+			return csvDecodeError{err}
+		}
+
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return csvDecodeError{err}
+			}
+
+			var v T
+			rv := reflect.ValueOf(&v).Elem()
+			for col, idx := range fields {
+				if idx < 0 || col >= len(record) {
+					continue
+				}
+				if err := parseCSVValue(rv.Field(idx), rv.Type().Field(idx).Tag, record[col]); err != nil {
+					line, _ := r.FieldPos(col)
+					return csvDecodeError{fmt.Errorf("csv: line %d: column %s: %w", line, header[col], err)}
+				}
+			}
+
+			if err := f(v); err != nil {
+				return err
+			}
+		}
+	}}
+}
+
+// csvDecodeError marks errors produced while decoding CSV as opposed to errors
+// returned by the callback given to ForCSVRows.
+type csvDecodeError struct {
+	err error
+}
+
+func (e csvDecodeError) Error() string { return e.err.Error() }
+
+// forCSV is the RequestInterceptor and ResponseInterceptor created by
+// ForCSVRows.
+type forCSV struct {
+	decode func(*csv.Reader) error
+}
+
+func (*forCSV) clientOpt() {}
+func (*forCSV) reqOpt()    {}
+
+func (*forCSV) InterceptRequest(r *http.Request) (*http.Request, error) {
+	r.Header.Add("Accept", "text/csv")
+	return r, nil
+}
+
+func (c *forCSV) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct := r.Header.Get("Content-Type")
+	if !hasMediaType(ct, []string{"text/csv", "application/csv"}) {
+		return r, newResponseError(ErrDecode, r, fmt.Errorf("expected CSV response but got %s", ct))
+	}
+
+	cr := csv.NewReader(r.Body)
+	cr.ReuseRecord = true
+
+	if err := c.decode(cr); err != nil {
+		var de csvDecodeError
+		if errors.As(err, &de) {
+			return r, newResponseError(ErrDecode, r, de.err)
+		}
+		return r, err
+	}
+
+	return r, nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// csvFieldIndexes returns the index of the field of t each column of header
+// is mapped to or -1 for columns not mapped to a field.
+func csvFieldIndexes(t reflect.Type, header []string) ([]int, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: expected struct but got %s", t)
+	}
+
+	indexes := make([]int, len(header))
+	for col, name := range header {
+		indexes[col] = -1
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			tag := f.Tag.Get("csv")
+			if tag == "-" {
+				continue
+			}
+
+			if tag == name || (tag == "" && strings.EqualFold(f.Name, name)) {
+				indexes[col] = i
+				break
+			}
+		}
+	}
+
+	return indexes, nil
+}
+
+// parseCSVValue parses s into v.
+func parseCSVValue(v reflect.Value, tag reflect.StructTag, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) && v.Type() != timeType {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if s == "" && v.Kind() != reflect.String {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Struct:
+		if v.Type() != timeType {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		layout := tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestForCSV(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		switch r.URL.Path {
+		case "/invalid":
+			w.Write([]byte("id,total\n1,abc\n"))
+		default:
+			w.Write([]byte("id,total,placed,note,extra\n1,9.99,2024-03-01,first,x\n2,20,2024-03-02,,y\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	type order struct {
+		ID     int       `csv:"id"`
+		Total  float64   `csv:"total"`
+		Placed time.Time `csv:"placed" layout:"2006-01-02"`
+		Note   *string
+		Ignore string `csv:"-"`
+	}
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("ForCSV", func(t *testing.T) {
+		var orders []order
+		_, err := client.Get(context.Background(), "/", httpclient.ForCSV(&orders))
+
+		first := "first"
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, orders).Is(DeepEqual([]order{
+			{ID: 1, Total: 9.99, Placed: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Note: &first},
+			{ID: 2, Total: 20, Placed: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		}))
+	})
+
+	t.Run("ForCSVRows", func(t *testing.T) {
+		errStop := errors.New("stop")
+		var ids []int
+
+		_, err := client.Get(context.Background(), "/", httpclient.ForCSVRows(func(o order) error {
+			ids = append(ids, o.ID)
+			return errStop
+		}))
+
+		ExpectThat(t, err).Is(Error(errStop))
+		ExpectThat(t, ids).Is(DeepEqual([]int{1}))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var orders []order
+		_, err := client.Get(context.Background(), "/invalid", httpclient.ForCSV(&orders))
+
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+}
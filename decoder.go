@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BodyDecoder decodes the body of a response into a value. It is used by
+// ForBody for the media types it has been registered for using WithDecoder.
+type BodyDecoder interface {
+	DecodeBody(r *http.Response, v any) error
+}
+
+// BodyDecoderFunc is a function implementing BodyDecoder.
+type BodyDecoderFunc func(r *http.Response, v any) error
+
+func (f BodyDecoderFunc) DecodeBody(r *http.Response, v any) error {
+	return f(r, v)
+}
+
+// UnmarshalDecoder creates a BodyDecoder reading the whole response body and
+// passing it to unmarshal, i.e. xml.Unmarshal or yaml.Unmarshal. The body
+// remains readable after it has been decoded.
+func UnmarshalDecoder(unmarshal func([]byte, any) error) BodyDecoder {
+	return BodyDecoderFunc(func(r *http.Response, v any) error {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		r.Body = &multiReadCloser{bytes.NewReader(b), r.Body}
+
+		return unmarshal(b, v)
+	})
+}
+
+// registeredDecoder is a BodyDecoder registered for a media type.
+type registeredDecoder struct {
+	mediaType string
+	decoder   BodyDecoder
+}
+
+// defaultDecoders are the decoders used by ForBody unless configured
+// otherwise using WithDecoder. JSON is decoded like ForJSON does, honoring
+// the codec configured using WithJSONCodec.
+var defaultDecoders = []registeredDecoder{
+	{"application/json", BodyDecoderFunc(func(r *http.Response, v any) error {
+		_, err := (&forJSON{value: v}).decode(r)
+		return err
+	})},
+	{"application/xml", UnmarshalDecoder(xml.Unmarshal)},
+	{"text/xml", UnmarshalDecoder(xml.Unmarshal)},
+}
+
+// WithDecoder creates a ClientOption registering dec to decode response
+// bodies of mediaType, i.e. "application/yaml", for ForBody. A decoder
+// already registered for mediaType - including the default decoders for
+// application/json, application/xml and text/xml - is replaced.
+func WithDecoder(mediaType string, dec BodyDecoder) ClientOption {
+	mediaType = strings.ToLower(mediaType)
+
+	return clientConfigOption(func(c *Client) {
+		if c.decoders == nil {
+			c.decoders = defaultDecoders
+		}
+
+		c.decoders = slices.Clone(c.decoders)
+		if idx := slices.IndexFunc(c.decoders, func(d registeredDecoder) bool { return d.mediaType == mediaType }); idx >= 0 {
+			c.decoders[idx].decoder = dec
+			return
+		}
+		c.decoders = append(c.decoders, registeredDecoder{mediaType, dec})
+	})
+}
+
+// decodersKey is the context key used to store the registered decoders.
+type decodersKey struct{}
+
+// decodersFromContext returns the decoders stored in ctx or the default
+// decoders.
+func decodersFromContext(ctx context.Context) []registeredDecoder {
+	if d, ok := ctx.Value(decodersKey{}).([]registeredDecoder); ok {
+		return d
+	}
+	return defaultDecoders
+}
+
+// findDecoder returns the decoder registered for the media type of ct. Media
+// types using a structured syntax suffix, such as application/problem+json,
+// fall back to the decoder registered for the suffix' base type, i.e.
+// application/json.
+func findDecoder(decoders []registeredDecoder, ct string) (BodyDecoder, bool) {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return nil, false
+	}
+
+	candidates := []string{mt}
+	if _, suffix, ok := strings.Cut(mt, "+"); ok {
+		candidates = append(candidates, "application/"+suffix)
+	}
+
+	for _, candidate := range candidates {
+		for _, d := range decoders {
+			if d.mediaType == candidate {
+				return d.decoder, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// acceptHeader returns the value of an Accept header listing the media types
+// of decoders in order of preference using decreasing q-values.
+func acceptHeader(decoders []registeredDecoder) string {
+	step := 0.1
+	if len(decoders) > 10 {
+		step = 0.9 / float64(len(decoders)-1)
+	}
+
+	values := make([]string, len(decoders))
+	for i, d := range decoders {
+		if i == 0 {
+			values[i] = d.mediaType
+			continue
+		}
+		q := math.Round((1-float64(i)*step)*1000) / 1000
+		values[i] = d.mediaType + ";q=" + strconv.FormatFloat(q, 'f', -1, 64)
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// ForBody creates a RequestOption decoding the response body into value
+// using the decoder registered for the response's Content-Type using
+// WithDecoder. JSON and XML are supported by default, so a single call site
+// can handle APIs responding with either of them. If no decoder is registered
+// for the content type or decoding fails, an *Error of kind ErrDecode is
+// returned.
+//
+// Unless the request already has an Accept header, ForBody sets one listing
+// the media types of all registered decoders in the order they have been
+// registered - starting with the defaults - using decreasing q-values, so
+// content negotiation picks a type the client can decode.
+func ForBody(value any) RequestOption {
+	return &forBody{value: value}
+}
+
+// forBody is the RequestInterceptor and ResponseInterceptor created by
+// ForBody.
+type forBody struct {
+	value any
+}
+
+func (*forBody) clientOpt() {}
+func (*forBody) reqOpt()    {}
+
+func (*forBody) InterceptRequest(r *http.Request) (*http.Request, error) {
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", acceptHeader(decodersFromContext(r.Context())))
+	}
+	return r, nil
+}
+
+func (b *forBody) InterceptResponse(r *http.Response) (*http.Response, error) {
+	decoders := decodersFromContext(responseContext(r))
+
+	ct := r.Header.Get("Content-Type")
+	dec, ok := findDecoder(decoders, ct)
+	if !ok {
+		return r, newResponseError(ErrDecode, r, fmt.Errorf("no decoder for content type %q", ct))
+	}
+
+	if err := dec.DecodeBody(r, b.value); err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			return r, err
+		}
+		return r, newResponseError(ErrDecode, r, err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,102 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestForBody(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"name":"Alice"}`))
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Write([]byte(`{"name":"Bob"}`))
+		case "/xml":
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<user><name>Carol</name></user>`))
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`name=Dave`))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	for path, want := range map[string]string{"/json": "Alice", "/problem": "Bob", "/xml": "Carol"} {
+		t.Run(path, func(t *testing.T) {
+			var u user
+			_, err := client.Get(context.Background(), path, httpclient.ForBody(&u))
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, u.Name).Is(Equal(want))
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		var u user
+		_, err := client.Get(context.Background(), "/text", httpclient.ForBody(&u))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+
+	t.Run("customDecoder", func(t *testing.T) {
+		client := client.With(httpclient.WithDecoder("text/plain", httpclient.UnmarshalDecoder(func(b []byte, v any) error {
+			_, name, _ := strings.Cut(string(b), "=")
+			v.(*user).Name = name
+			return nil
+		})))
+
+		var u user
+		_, err := client.Get(context.Background(), "/text", httpclient.ForBody(&u))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, u.Name).Is(Equal("Dave"))
+
+		_, err = client.Get(context.Background(), "/json", httpclient.ForBody(&u))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, u.Name).Is(Equal("Alice"))
+	})
+
+	t.Run("decodeError", func(t *testing.T) {
+		var n int
+		_, err := client.Get(context.Background(), "/json", httpclient.ForBody(&n))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+}
+
+func TestForBody_accept(t *testing.T) {
+	var accept string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	var v map[string]any
+	_, err := client.Get(context.Background(), "/", httpclient.ForBody(&v))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, accept).Is(Equal("application/json, application/xml;q=0.9, text/xml;q=0.8"))
+
+	yamlClient := client.With(httpclient.WithDecoder("application/yaml", httpclient.UnmarshalDecoder(func([]byte, any) error { return nil })))
+	_, err = yamlClient.Get(context.Background(), "/", httpclient.ForBody(&v))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, accept).Is(Equal("application/json, application/xml;q=0.9, text/xml;q=0.8, application/yaml;q=0.7"))
+
+	_, err = client.Get(context.Background(), "/", httpclient.WithRequestHeader("Accept", "application/json"), httpclient.ForBody(&v))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, accept).Is(Equal("application/json"))
+}
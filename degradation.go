@@ -0,0 +1,192 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRequestShed is returned for requests rejected by the active
+// DegradationProfile because of their priority.
+var ErrRequestShed = errors.New("request shed by degradation profile")
+
+// RequestPriority defines the importance of a request. Degradation profiles
+// use it to reject less important requests during overload.
+type RequestPriority int
+
+const (
+	// RequestPriorityLow is used for optional requests, i.e. prefetching or
+	// enriching responses with non-essential data.
+	RequestPriorityLow RequestPriority = -1
+
+	// RequestPriorityNormal is the priority of requests that don't declare
+	// one.
+	RequestPriorityNormal RequestPriority = 0
+
+	// RequestPriorityCritical is used for requests that must be sent even
+	// under heavy degradation.
+	RequestPriorityCritical RequestPriority = 1
+)
+
+// requestPriorityKey is the context key used to store the RequestPriority.
+type requestPriorityKey struct{}
+
+// ContextWithRequestPriority returns a copy of ctx carrying p.
+func ContextWithRequestPriority(ctx context.Context, p RequestPriority) context.Context {
+	return context.WithValue(ctx, requestPriorityKey{}, p)
+}
+
+// RequestPriorityFromContext returns the RequestPriority stored in ctx or
+// RequestPriorityNormal if ctx carries none.
+func RequestPriorityFromContext(ctx context.Context) RequestPriority {
+	if p, ok := ctx.Value(requestPriorityKey{}).(RequestPriority); ok {
+		return p
+	}
+	return RequestPriorityNormal
+}
+
+// WithRequestPriority creates a RequestInterceptorOption assigning p to a
+// request.
+func WithRequestPriority(p RequestPriority) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		return r.WithContext(ContextWithRequestPriority(r.Context(), p)), nil
+	})
+}
+
+// retriesDisabledKey is the context key used to mark requests that must not
+// be retried.
+type retriesDisabledKey struct{}
+
+// RetriesDisabled reports whether retries have been disabled for requests
+// using ctx, i.e. by the active DegradationProfile. Code retrying or failing
+// over requests should send a single attempt only if it returns true.
+func RetriesDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(retriesDisabledKey{}).(bool)
+	return disabled
+}
+
+// DegradationProfile defines how a Client tightens its behaviour while the
+// profile is active.
+type DegradationProfile struct {
+	// Timeout limits the duration of every request. Deadlines of the request's
+	// context shorter than Timeout still apply. A value <= 0 leaves requests
+	// unlimited.
+	Timeout time.Duration
+
+	// DisableRetries marks requests as not to be retried. See RetriesDisabled.
+	DisableRetries bool
+
+	// MinPriority is the minimum RequestPriority of requests being sent.
+	// Requests with a lower priority fail with ErrRequestShed without being
+	// sent.
+	MinPriority RequestPriority
+}
+
+// WithDegradationProfiles creates a ClientOption registering profiles by name.
+// A profile becomes active when its name is passed to Client.SetProfile. No
+// profile is active initially.
+func WithDegradationProfiles(profiles map[string]DegradationProfile) ClientOption {
+	return clientConfigOption(func(c *Client) {
+		// The profiles are copied so that adding profiles to a Client derived
+		// using Client.With leaves the original Client unchanged.
+		d := &degradation{profiles: make(map[string]DegradationProfile, len(profiles))}
+		if c.degradation != nil {
+			maps.Copy(d.profiles, c.degradation.profiles)
+			d.active.Store(c.degradation.active.Load())
+		}
+		maps.Copy(d.profiles, profiles)
+		c.degradation = d
+	})
+}
+
+// SetProfile activates the degradation profile registered under name for all
+// requests sent afterwards. Passing an empty name deactivates any profile. It
+// returns an error if no profile has been registered under name. SetProfile
+// is safe for concurrent use while requests are being sent.
+func (c *Client) SetProfile(name string) error {
+	if name == "" {
+		if c.degradation != nil {
+			c.degradation.active.Store(nil)
+		}
+		return nil
+	}
+
+	if c.degradation == nil {
+		return fmt.Errorf("unknown degradation profile: %s", name)
+	}
+
+	p, ok := c.degradation.profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown degradation profile: %s", name)
+	}
+
+	c.degradation.active.Store(&activeProfile{name: name, profile: p})
+	return nil
+}
+
+// Profile returns the name of the active degradation profile or an empty
+// string if no profile is active.
+func (c *Client) Profile() string {
+	if c.degradation == nil {
+		return ""
+	}
+
+	if a := c.degradation.active.Load(); a != nil {
+		return a.name
+	}
+	return ""
+}
+
+// degradation holds the registered and the active degradation profiles.
+type degradation struct {
+	profiles map[string]DegradationProfile
+	active   atomic.Pointer[activeProfile]
+}
+
+type activeProfile struct {
+	name    string
+	profile DegradationProfile
+}
+
+// apply applies the active profile to r. The returned function must be
+// called once the request has been completed.
+func (d *degradation) apply(r *http.Request) (*http.Request, context.CancelFunc, error) {
+	a := d.active.Load()
+	if a == nil {
+		return r, func() {}, nil
+	}
+
+	ctx := r.Context()
+
+	if RequestPriorityFromContext(ctx) < a.profile.MinPriority {
+		return r, func() {}, fmt.Errorf("%w: %s", ErrRequestShed, a.name)
+	}
+
+	if a.profile.DisableRetries {
+		ctx = context.WithValue(ctx, retriesDisabledKey{}, true)
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if a.profile.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, a.profile.Timeout)
+	}
+
+	return r.WithContext(ctx), cancel, nil
+}
+
+// cancelOnClose is an io.ReadCloser cancelling the context the body is read
+// under once it has been closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
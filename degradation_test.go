@@ -0,0 +1,90 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestDegradationProfiles(t *testing.T) {
+	var deadline time.Time
+	var retriesDisabled bool
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithDegradationProfiles(map[string]httpclient.DegradationProfile{
+			"shed-optional": {
+				Timeout:        time.Second,
+				DisableRetries: true,
+				MinPriority:    httpclient.RequestPriorityNormal,
+			},
+		}),
+		httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			deadline, _ = r.Request.Context().Deadline()
+			retriesDisabled = httpclient.RetriesDisabled(r.Request.Context())
+			return r, nil
+		}),
+	)
+
+	get := func(opts ...httpclient.RequestOption) error {
+		_, err := client.Get(context.Background(), "/", opts...)
+		return err
+	}
+
+	ExpectThat(t, get(httpclient.WithRequestPriority(httpclient.RequestPriorityLow))).Is(NoError())
+	ExpectThat(t, deadline.IsZero()).Is(Equal(true))
+	ExpectThat(t, retriesDisabled).Is(Equal(false))
+
+	ExpectThat(t, client.SetProfile("unknown")).Is(NotNil())
+	ExpectThat(t, client.SetProfile("shed-optional")).Is(NoError())
+	ExpectThat(t, client.Profile()).Is(Equal("shed-optional"))
+
+	err := get(httpclient.WithRequestPriority(httpclient.RequestPriorityLow))
+	ExpectThat(t, errors.Is(err, httpclient.ErrRequestShed)).Is(Equal(true))
+
+	ExpectThat(t, get()).Is(NoError())
+	ExpectThat(t, time.Until(deadline) <= time.Second).Is(Equal(true))
+	ExpectThat(t, retriesDisabled).Is(Equal(true))
+
+	ExpectThat(t, client.SetProfile("")).Is(NoError())
+	ExpectThat(t, client.Profile()).Is(Equal(""))
+	ExpectThat(t, get(httpclient.WithRequestPriority(httpclient.RequestPriorityLow))).Is(NoError())
+}
+
+func TestDegradationProfiles_streamedBody(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("hello, world"))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithDegradationProfiles(map[string]httpclient.DegradationProfile{
+			"tight": {Timeout: time.Second},
+		}),
+	)
+	ExpectThat(t, client.SetProfile("tight")).Is(NoError())
+
+	hc := &http.Client{Transport: client.Transport()}
+	res, err := hc.Get(testServer.URL)
+	ExpectThat(t, err).Is(NoError())
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, string(body)).Is(Equal("hello, world"))
+}
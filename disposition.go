@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultDownloadFilename is the name used by Client.DownloadToDir if
+// neither the response nor the URL provide one.
+const defaultDownloadFilename = "download"
+
+// DownloadFilename returns the name of the file to store the body of r in.
+// The name is taken from the filename parameter of the Content-Disposition
+// header as defined by RFC 6266, preferring the extended filename* parameter
+// carrying non-ASCII names, or from the last segment of the path of the
+// request's URL. The name is sanitized for use as a file name: any directory
+// components are removed, so names like "../../etc/passwd" never escape the
+// destination directory, and characters not allowed in file names on common
+// systems are replaced with underscores. It returns an empty string if no
+// usable name is found.
+func DownloadFilename(r *http.Response) string {
+	if cd := r.Header.Get("Content-Disposition"); cd != "" {
+		if _, params, err := mime.ParseMediaType(cd); err == nil {
+			if name := sanitizeFilename(params["filename"]); name != "" {
+				return name
+			}
+		}
+	}
+
+	if r.Request != nil && r.Request.URL != nil {
+		return sanitizeFilename(path.Base(r.Request.URL.Path))
+	}
+
+	return ""
+}
+
+// sanitizeFilename returns the last path component of name with characters
+// not allowed in file names replaced or an empty string if nothing remains.
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "/" {
+		return ""
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	return strings.TrimRight(strings.TrimSpace(name), ".")
+}
+
+// DownloadToDir is like DownloadFile but stores the response body in dir
+// using the name determined by DownloadFilename, falling back to "download".
+// An existing file of that name is replaced once the download completed. It
+// returns the path of the stored file. WithDownloadSegments has no effect, as
+// the name is only known once the response has been received.
+func (c *Client) DownloadToDir(ctx context.Context, url, dir string, opts ...RequestOption) (string, error) {
+	res, err := c.openDownload(ctx, url, opts)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	name := DownloadFilename(res)
+	if name == "" {
+		name = defaultDownloadFilename
+	}
+
+	f, err := os.CreateTemp(dir, name+"-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := copyBody(f, res); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	p := filepath.Join(dir, name)
+	if err := os.Rename(f.Name(), p); err != nil {
+		return "", err
+	}
+
+	return p, nil
+}
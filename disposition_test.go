@@ -0,0 +1,65 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestDownloadFilename(t *testing.T) {
+	tests := map[string]struct {
+		path        string
+		disposition string
+		want        string
+	}{
+		"filename":  {"/download", `attachment; filename="report.pdf"`, "report.pdf"},
+		"extended":  {"/download", `attachment; filename="EURO rates.txt"; filename*=UTF-8''%e2%82%ac%20rates.txt`, "€ rates.txt"},
+		"traversal": {"/download", `attachment; filename="../../etc/passwd"`, "passwd"},
+		"windows":   {"/download", `attachment; filename="..\\..\\boot.ini"`, "boot.ini"},
+		"reserved":  {"/download", `attachment; filename="a:b?.txt"`, "a_b_.txt"},
+		"dots":      {"/files/archive.zip", `attachment; filename=".."`, "archive.zip"},
+		"url":       {"/files/archive.zip", "", "archive.zip"},
+		"invalid":   {"/files/archive.zip", `attachment; filename`, "archive.zip"},
+		"nothing":   {"/", "", ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := &http.Response{
+				Header:  make(http.Header),
+				Request: &http.Request{URL: &url.URL{Path: tc.path}},
+			}
+			if tc.disposition != "" {
+				res.Header.Set("Content-Disposition", tc.disposition)
+			}
+
+			ExpectThat(t, httpclient.DownloadFilename(res)).Is(Equal(tc.want))
+		})
+	}
+}
+
+func TestClient_DownloadToDir(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="../report.csv"`)
+		w.Write([]byte("a,b\n"))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+	dir := t.TempDir()
+
+	p, err := client.DownloadToDir(context.Background(), "/export", dir)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, p).Is(Equal(filepath.Join(dir, "report.csv")))
+
+	b, err := os.ReadFile(p)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, string(b)).Is(Equal("a,b\n"))
+}
@@ -4,5 +4,22 @@
 // requests.
 //
 // httpclient supports all options offered by http.Client with the exception of
-// a client-global timeout (httpclient uses context.Context for this).
+// http.Client.Timeout (httpclient uses context.Context for this, see
+// WithRequestTimeout).
+//
+// # Execution order
+//
+// Client.Do processes a request in a fixed order:
+//
+//  1. Request interceptors run ordered by their RequestPhase: those preparing
+//     the request, then query rewriters, then those authenticating it and
+//     finally those adding tracing information. Within a phase, client-level interceptors run
+//     before request-level ones, each in the order they have been given.
+//  2. Options observing the request as it is sent, such as WithLogging or
+//     WithRetry, wrap sending the request. Client-level options wrap
+//     request-level ones and options given first wrap those given later.
+//  3. Response interceptors run ordered by their Phase: PhasePreValidate,
+//     PhaseValidate and PhasePostValidate. Within a phase, client-level
+//     interceptors run before request-level ones, each in the order they have
+//     been given, unless changed using WithResponseInterceptorOrder.
 package httpclient
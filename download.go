@@ -0,0 +1,207 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Progress describes the state of a transfer reported to a ProgressFunc.
+type Progress struct {
+	// Transferred is the number of bytes transferred so far.
+	Transferred int64
+
+	// Total is the total number of bytes to transfer or -1 if unknown, i.e.
+	// because the response has no Content-Length.
+	Total int64
+
+	// Rate is the average number of bytes transferred per second.
+	Rate float64
+}
+
+// ProgressFunc is called with the current Progress of a transfer.
+type ProgressFunc func(Progress)
+
+// progressKey is the context key used to store a download ProgressFunc.
+type progressKey struct{}
+
+// WithDownloadProgress creates a RequestInterceptorOption reporting the
+// progress of a download performed by Client.Download or Client.DownloadFile
+// to f. f is called after every chunk written to the destination and once
+// the download completed.
+func WithDownloadProgress(f ProgressFunc) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		return r.WithContext(context.WithValue(r.Context(), progressKey{}, f)), nil
+	})
+}
+
+// Download sends a GET request for url using ctx and opts and streams the
+// response body to dst without buffering it. It returns the number of bytes
+// written. Responses with a status code other than 2xx are reported as an
+// *Error of kind ErrUnexpectedStatus and nothing is written. Use
+// WithDownloadProgress to observe the transfer and WithDownloadSegments to
+// download the body in concurrent segments if dst is an io.WriterAt.
+func (c *Client) Download(ctx context.Context, url string, dst io.Writer, opts ...RequestOption) (int64, error) {
+	if w, ok := dst.(io.WriterAt); ok {
+		if n := segmentsOf(opts); n > 1 {
+			return c.downloadSegmented(ctx, url, dst, w, n, opts)
+		}
+	}
+
+	return c.download(ctx, url, dst, opts)
+}
+
+// download implements Download streaming the response body to dst.
+func (c *Client) download(ctx context.Context, url string, dst io.Writer, opts []RequestOption) (int64, error) {
+	res, err := c.openDownload(ctx, url, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	return copyBody(dst, res)
+}
+
+// openDownload sends a GET request for url using ctx and opts and returns
+// the response with its body left open. Responses with a status code other
+// than 2xx are reported as an *Error of kind ErrUnexpectedStatus.
+func (c *Client) openDownload(ctx context.Context, url string, opts []RequestOption) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(req, opts, true)
+	if err != nil {
+		if res != nil {
+			res.Body.Close()
+		}
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err := newResponseError(ErrUnexpectedStatus, res, nil)
+		res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// copyBody copies the body of res to dst reporting the progress to the
+// ProgressFunc configured using WithDownloadProgress.
+func copyBody(dst io.Writer, res *http.Response) (int64, error) {
+	progress := newTransferProgress(responseContext(res), res.ContentLength)
+	if progress == nil {
+		return io.Copy(dst, res.Body)
+	}
+
+	n, err := io.Copy(&progressWriter{dst, progress}, res.Body)
+	if err == nil {
+		progress.report()
+	}
+	return n, err
+}
+
+// DownloadFile is like Download but writes the response body to the file at
+// path. The body is written to a temporary file in the same directory which
+// is renamed once the download completed, so an interrupted download never
+// replaces an existing file.
+func (c *Client) DownloadFile(ctx context.Context, url, path string, opts ...RequestOption) (int64, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	n, err := c.Download(ctx, url, f, opts...)
+	if err != nil {
+		f.Close()
+		return n, err
+	}
+
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+
+	return n, os.Rename(f.Name(), path)
+}
+
+// transferProgress tracks the progress of a transfer, which may be written
+// to concurrently, i.e. by the segments of a segmented download.
+type transferProgress struct {
+	mu          sync.Mutex
+	f           ProgressFunc
+	clock       Clock
+	start       time.Time
+	total       int64
+	transferred int64
+}
+
+// newTransferProgress creates a transferProgress for a transfer of total
+// bytes reporting to the ProgressFunc stored in ctx. It returns nil if no
+// ProgressFunc has been configured.
+func newTransferProgress(ctx context.Context, total int64) *transferProgress {
+	f, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	if f == nil {
+		return nil
+	}
+
+	return startProgress(f, ClockFromContext(ctx), total)
+}
+
+// startProgress creates a transferProgress for a transfer of total bytes
+// starting now and reporting to f.
+func startProgress(f ProgressFunc, clock Clock, total int64) *transferProgress {
+	return &transferProgress{
+		f:     f,
+		clock: clock,
+		start: clock.Now(),
+		total: total,
+	}
+}
+
+func (p *transferProgress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.transferred += n
+	p.reportLocked()
+}
+
+func (p *transferProgress) report() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reportLocked()
+}
+
+func (p *transferProgress) reportLocked() {
+	var rate float64
+	if elapsed := p.clock.Now().Sub(p.start); elapsed > 0 {
+		rate = float64(p.transferred) / elapsed.Seconds()
+	}
+
+	p.f(Progress{
+		Transferred: p.transferred,
+		Total:       p.total,
+		Rate:        rate,
+	})
+}
+
+// progressWriter is an io.Writer forwarding to w and adding the bytes
+// written to p.
+type progressWriter struct {
+	w io.Writer
+	p *transferProgress
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	if n > 0 {
+		pw.p.add(int64(n))
+	}
+	return n, err
+}
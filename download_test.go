@@ -0,0 +1,68 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestClient_Download(t *testing.T) {
+	content := strings.Repeat("0123456789", 10000)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, "data.txt", time.Time{}, strings.NewReader(content))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("writer", func(t *testing.T) {
+		var progress []httpclient.Progress
+		var buf bytes.Buffer
+
+		n, err := client.Download(context.Background(), "/data.txt", &buf, httpclient.WithDownloadProgress(func(p httpclient.Progress) {
+			progress = append(progress, p)
+		}))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, n).Is(Equal(int64(len(content))))
+		ExpectThat(t, buf.String()).Is(Equal(content))
+
+		last := progress[len(progress)-1]
+		ExpectThat(t, last.Transferred).Is(Equal(int64(len(content))))
+		ExpectThat(t, last.Total).Is(Equal(int64(len(content))))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data.txt")
+
+		_, err := client.DownloadFile(context.Background(), "/data.txt", path)
+		ExpectThat(t, err).Is(NoError())
+
+		b, err := os.ReadFile(path)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, string(b)).Is(Equal(content))
+	})
+
+	t.Run("unexpectedStatus", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data.txt")
+
+		_, err := client.DownloadFile(context.Background(), "/missing", path)
+		ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+
+		_, err = os.Stat(path)
+		ExpectThat(t, os.IsNotExist(err)).Is(Equal(true))
+	})
+}
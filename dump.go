@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// DefaultDumpBodyBytes is the number of body bytes dumped by WithDump if
+// DumpOptions.MaxBodyBytes is 0.
+const DefaultDumpBodyBytes = 64 << 10
+
+// streamingMediaTypes lists the media types of bodies that are not dumped
+// unless DumpOptions.Streams is set.
+var streamingMediaTypes = []string{"text/event-stream", "application/x-ndjson", "application/jsonl"}
+
+// DumpOptions customizes the dumps written by WithDump.
+type DumpOptions struct {
+	// Body enables dumping of request and response bodies. Bodies are read
+	// up to the dumped size before the request is sent or the response is
+	// returned.
+	Body bool
+
+	// MaxBodyBytes limits the number of body bytes being dumped. Bodies
+	// exceeding the limit are truncated in the dump. A value of 0 uses
+	// DefaultDumpBodyBytes; a value < 0 dumps bodies completely, which
+	// reads them completely into memory.
+	MaxBodyBytes int
+
+	// Streams enables dumping of bodies with a streaming media type, i.e.
+	// text/event-stream or application/x-ndjson. These are not dumped by
+	// default, as reading them blocks until enough events have been
+	// received.
+	Streams bool
+
+	// RedactHeaders lists the names of headers whose values are masked in the
+	// dump. Authorization, Proxy-Authorization, Cookie and Set-Cookie are
+	// always masked.
+	RedactHeaders []string
+}
+
+// dump writes wire dumps of requests and responses to an io.Writer. It wraps
+// the roundtrip so the dumped request is the one being sent, i.e. after all
+// request options have been applied.
+type dump struct {
+	w      io.Writer
+	opts   DumpOptions
+	redact map[string]struct{}
+	mutex  sync.Mutex
+}
+
+func (*dump) clientOpt() {}
+func (*dump) reqOpt()    {}
+
+func (d *dump) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if err := d.dumpRequest(r); err != nil {
+		return nil, err
+	}
+
+	res, err := next(r)
+	if err != nil {
+		return res, err
+	}
+
+	if err := d.dumpResponse(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (d *dump) dumpRequest(r *http.Request) error {
+	clone := r.Clone(r.Context())
+	clone.Header = d.redactHeaders(r.Header)
+
+	b, err := httputil.DumpRequestOut(clone, false)
+	if err != nil {
+		return err
+	}
+
+	if d.opts.Body && r.Body != nil {
+		var body []byte
+		body, r.Body, err = d.dumpBody(r.Body, r.Header)
+		if err != nil {
+			return err
+		}
+		b = append(b, body...)
+	}
+
+	return d.write(b)
+}
+
+func (d *dump) dumpResponse(r *http.Response) error {
+	clone := *r
+	clone.Header = d.redactHeaders(r.Header)
+
+	b, err := httputil.DumpResponse(&clone, false)
+	if err != nil {
+		return err
+	}
+
+	if d.opts.Body && r.Body != nil {
+		var body []byte
+		body, r.Body, err = d.dumpBody(r.Body, r.Header)
+		if err != nil {
+			return err
+		}
+		b = append(b, body...)
+	}
+
+	return d.write(b)
+}
+
+func (d *dump) write(b []byte) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	_, err := d.w.Write(append(b, '\n'))
+	return err
+}
+
+func (d *dump) redactHeaders(h http.Header) http.Header {
+	masked := h.Clone()
+	for name := range masked {
+		if _, ok := d.redact[name]; ok {
+			masked[name] = []string{redacted}
+		}
+	}
+	return masked
+}
+
+// dumpBody reads the part of body to be dumped and returns it together with a
+// ReadCloser yielding the full, unconsumed body. h are the headers of the
+// message body belongs to.
+func (d *dump) dumpBody(body io.ReadCloser, h http.Header) ([]byte, io.ReadCloser, error) {
+	if !d.opts.Streams && hasMediaType(h.Get("Content-Type"), streamingMediaTypes) {
+		return []byte("[streaming body not dumped]"), body, nil
+	}
+
+	maxBytes := d.opts.MaxBodyBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultDumpBodyBytes
+	}
+
+	if maxBytes < 0 {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return nil, body, err
+		}
+		body.Close()
+		return b, io.NopCloser(bytes.NewReader(b)), nil
+	}
+
+	// Read one byte more than dumped to detect truncation.
+	sample, restored, err := sampleBody(body, maxBytes+1)
+	if err != nil {
+		return nil, restored, err
+	}
+
+	if len(sample) > maxBytes {
+		return []byte(fmt.Sprintf("%s\n[... truncated]", sample[:maxBytes])), restored, nil
+	}
+
+	return []byte(sample), restored, nil
+}
+
+// WithDump creates an Option that writes wire dumps of all requests and
+// responses to w. The dumps follow the format produced by net/http/httputil.
+// Writes to w are serialized, so w may be shared between concurrent requests.
+//
+// Requests are dumped when they are sent, so the dump contains headers and
+// bodies set by any option no matter whether WithDump is given to New or to a
+// single request.
+func WithDump(w io.Writer, opts DumpOptions) Option {
+	d := &dump{
+		w:      w,
+		opts:   opts,
+		redact: make(map[string]struct{}, len(defaultRedactedHeaders)+len(opts.RedactHeaders)),
+	}
+
+	for _, h := range defaultRedactedHeaders {
+		d.redact[h] = struct{}{}
+	}
+
+	for _, h := range opts.RedactHeaders {
+		d.redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	return d
+}
@@ -0,0 +1,127 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithDump(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Token", "secret")
+		w.Write([]byte("a rather long response body"))
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+	)
+
+	_, err := client.Post(context.Background(), "/items",
+		httpclient.WithRequestHeader("Authorization", "Bearer secret"),
+		httpclient.WithJSON("hello"),
+		httpclient.WithDump(&buf, httpclient.DumpOptions{
+			Body:          true,
+			MaxBodyBytes:  8,
+			RedactHeaders: []string{"x-token"},
+		}),
+	)
+	ExpectThat(t, err).Is(NoError())
+
+	dump := buf.String()
+	ExpectThat(t, dump).
+		Has(StringContaining("POST /items HTTP/1.1\r\n")).
+		And(StringContaining("Authorization: REDACTED\r\n")).
+		And(StringContaining(`"hello"`)).
+		And(StringContaining("HTTP/1.1 200 OK\r\n")).
+		And(StringContaining("X-Token: REDACTED\r\n")).
+		And(StringContaining("a rather\n[... truncated]"))
+}
+
+func TestWithDump_clientLevel(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithDump(&buf, httpclient.DumpOptions{
+			Body:          true,
+			RedactHeaders: []string{"X-Api-Key"},
+		}),
+	)
+
+	_, err := client.Post(context.Background(), "/items",
+		httpclient.WithRequestHeader("X-Api-Key", "secret"),
+		httpclient.WithJSON("hello"),
+	)
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, buf.String()).
+		Has(StringContaining("POST /items HTTP/1.1\r\n")).
+		And(StringContaining("X-Api-Key: REDACTED\r\n")).
+		And(StringContaining("Content-Type: application/json\r\n")).
+		And(StringContaining(`"hello"`)).
+		And(StringContaining("HTTP/1.1 200 OK\r\n"))
+}
+
+func TestWithDump_bodyLimits(t *testing.T) {
+	body := strings.Repeat("x", httpclient.DefaultDumpBodyBytes+1)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	var buf bytes.Buffer
+	_, err := client.Get(context.Background(), "/", httpclient.WithDump(&buf, httpclient.DumpOptions{Body: true}))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, buf.String()).
+		Has(StringContaining(body[1:] + "\n[... truncated]"))
+
+	buf.Reset()
+	_, err = client.Get(context.Background(), "/", httpclient.WithDump(&buf, httpclient.DumpOptions{Body: true, MaxBodyBytes: -1}))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, buf.String()).
+		Has(StringContaining(body + "\n"))
+	ExpectThat(t, strings.Contains(buf.String(), "[... truncated]")).Is(Equal(false))
+}
+
+func TestWithDump_streams(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: hello\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithDump(&buf, httpclient.DumpOptions{Body: true}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	events, err := client.Subscribe(ctx, "/events")
+	ExpectThat(t, err).Is(NoError())
+
+	event := <-events
+	ExpectThat(t, event.Data).Is(Equal("hello"))
+	ExpectThat(t, buf.String()).Is(StringContaining("[streaming body not dumped]"))
+}
@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// WithEncoded creates a RequestInterceptorOption that uses value encoded
+// using marshal as the request's body with the given contentType. It works
+// like WithJSON for arbitrary encodings, so modules supporting further
+// content types - such as MessagePack or YAML - don't need to reimplement
+// the interceptor. The interceptor sets the Content-Type and Content-Length
+// headers as well as the request's GetBody function, so the request can be
+// retried. Any error produced by marshal is returned and aborts the request.
+func WithEncoded(marshal func(any) ([]byte, error), value any, contentType string) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		b, err := marshal(value)
+		if err != nil {
+			return r, err
+		}
+
+		r, err = withBody(bytes.NewReader(b), contentType, int64(len(b))).InterceptRequest(r)
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}
+		return r, err
+	})
+}
+
+// ForDecoded creates a RequestOption that decodes the response body into
+// value using unmarshal. It works like ForJSON for arbitrary encodings: it
+// adds an Accept request header accepting the first of contentTypes and
+// expects the response's content type to be any of contentTypes, ignoring
+// parameters such as a charset. If the response has a different content type
+// or unmarshaling the body fails, an *Error of kind ErrDecode is returned.
+// The response body remains readable after it has been decoded.
+func ForDecoded(unmarshal func([]byte, any) error, value any, contentTypes ...string) RequestOption {
+	return &forDecoded{unmarshal: unmarshal, value: value, contentTypes: contentTypes}
+}
+
+// forDecoded is the RequestInterceptor and ResponseInterceptor created by
+// ForDecoded.
+type forDecoded struct {
+	unmarshal    func([]byte, any) error
+	value        any
+	contentTypes []string
+}
+
+func (*forDecoded) clientOpt() {}
+func (*forDecoded) reqOpt()    {}
+
+func (d *forDecoded) InterceptRequest(r *http.Request) (*http.Request, error) {
+	if len(d.contentTypes) > 0 {
+		r.Header.Add("Accept", d.contentTypes[0])
+	}
+	return r, nil
+}
+
+func (d *forDecoded) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct := r.Header.Get("Content-Type")
+	if !hasMediaType(ct, d.contentTypes) {
+		return r, newResponseError(ErrDecode, r, fmt.Errorf("expected %s response but got %s", d.contentTypes[0], ct))
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return r, err
+	}
+
+	r.Body = &multiReadCloser{bytes.NewReader(b), r.Body}
+
+	if err := d.unmarshal(b, d.value); err != nil {
+		return r, newResponseError(ErrDecode, r, err)
+	}
+
+	return r, nil
+}
+
+// hasMediaType reports whether the media type of contentType is any of
+// mediaTypes. An empty list of mediaTypes accepts any content type.
+func hasMediaType(contentType string, mediaTypes []string) bool {
+	if len(mediaTypes) == 0 {
+		return true
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range mediaTypes {
+		if mt == t {
+			return true
+		}
+	}
+	return false
+}
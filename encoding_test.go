@@ -0,0 +1,58 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestEncoded(t *testing.T) {
+	type greeting struct {
+		Text string `xml:"text"`
+	}
+
+	var contentType, accept string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		accept = r.Header.Get("Accept")
+
+		if r.URL.Path == "/json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		io.Copy(w, r.Body)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("success", func(t *testing.T) {
+		var got greeting
+		_, err := client.Post(context.Background(), "/",
+			httpclient.WithEncoded(xml.Marshal, greeting{Text: "hello"}, "application/xml"),
+			httpclient.ForDecoded(xml.Unmarshal, &got, "application/xml", "text/xml"),
+		)
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, got.Text).Is(Equal("hello"))
+		ExpectThat(t, contentType).Is(Equal("application/xml"))
+		ExpectThat(t, accept).Is(Equal("application/xml"))
+	})
+
+	t.Run("unexpected content type", func(t *testing.T) {
+		var got greeting
+		_, err := client.Get(context.Background(), "/json", httpclient.ForDecoded(xml.Unmarshal, &got, "application/xml"))
+
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+}
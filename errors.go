@@ -0,0 +1,250 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"syscall"
+)
+
+var (
+	// ErrUnexpectedStatus is the Kind of errors returned for responses with
+	// a status code not accepted by a validating interceptor such as
+	// ExpectedStatusCode.
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+
+	// ErrDecode is the Kind of errors returned if decoding a response body,
+	// i.e. using ForJSON, fails.
+	ErrDecode = errors.New("decoding response failed")
+
+	// ErrTimeout is the Kind of errors returned for requests that timed out.
+	ErrTimeout = errors.New("timeout")
+
+	// ErrChecksumMismatch is the Kind of errors returned if a response body
+	// doesn't match its expected checksum. See WithChecksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrResponseTooLarge is the Kind of errors returned if a response body
+	// exceeds the limit configured using WithMaxResponseBytes.
+	ErrResponseTooLarge = errors.New("response too large")
+
+	// ErrNotModified is the Kind of errors returned for 304 Not Modified
+	// responses to requests made conditional by WithConditionalRequests.
+	ErrNotModified = errors.New("not modified")
+
+	// ErrConflict is the Kind of errors returned for 412 Precondition Failed
+	// responses to requests carrying an If-Match header set by
+	// WithOptimisticConcurrency.
+	ErrConflict = errors.New("conflict")
+)
+
+// DefaultErrorBodyLimit is the maximum number of bytes of a response body
+// captured in an *Error unless configured otherwise using
+// WithErrorBodyLimit.
+const DefaultErrorBodyLimit = 512
+
+// errorBodyLimitKey is the context key used to store the error body limit.
+type errorBodyLimitKey struct{}
+
+// WithErrorBodyLimit creates a RequestInterceptorOption setting the maximum
+// number of bytes of a response body captured in an *Error of kind
+// ErrUnexpectedStatus to n. A value <= 0 disables capturing the body.
+func WithErrorBodyLimit(n int64) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		return r.WithContext(context.WithValue(r.Context(), errorBodyLimitKey{}, n)), nil
+	})
+}
+
+// errorBodyLimit returns the error body limit configured for r.
+func errorBodyLimit(r *http.Request) int64 {
+	if r != nil {
+		if n, ok := r.Context().Value(errorBodyLimitKey{}).(int64); ok {
+			return n
+		}
+	}
+	return DefaultErrorBodyLimit
+}
+
+// Error describes a failed request. Its Kind is one of the Err* sentinel
+// errors declared by this package, i.e. ErrUnexpectedStatus or ErrDecode, so
+// errors can be tested using errors.Is, i.e.
+//
+//	if errors.Is(err, httpclient.ErrUnexpectedStatus) {
+//		var e *httpclient.Error
+//		errors.As(err, &e)
+//		log.Printf("%s responded with %d: %s", e.URL, e.StatusCode, e.Body)
+//	}
+type Error struct {
+	// Kind is the sentinel error categorizing the failure.
+	Kind error
+
+	// Method and URL identify the request. Any password contained in the
+	// URL is redacted.
+	Method string
+	URL    string
+
+	// Attempt is the attempt number taken from the request's ExecutionState.
+	Attempt int
+
+	// StatusCode is the status code of the response, if one has been
+	// received.
+	StatusCode int
+
+	// Body contains the first bytes of the response body for errors of kind
+	// ErrUnexpectedStatus, i.e. an error payload sent by the server. See
+	// WithErrorBodyLimit.
+	Body []byte
+
+	// Err is the error that caused the failure, if any.
+	Err error
+}
+
+func (e *Error) Error() string {
+	var msg string
+	switch {
+	case e.Kind == ErrUnexpectedStatus:
+		msg = fmt.Sprintf("%v: %d", e.Kind, e.StatusCode)
+	case e.Err != nil:
+		cause := e.Err
+		// A *url.Error repeats method and URL.
+		var urlErr *url.Error
+		if errors.As(cause, &urlErr) {
+			cause = urlErr.Err
+		}
+		msg = fmt.Sprintf("%v: %v", e.Kind, cause)
+	default:
+		msg = e.Kind.Error()
+	}
+
+	return fmt.Sprintf("%s %s: %s", e.Method, e.URL, msg)
+}
+
+// Unwrap returns Kind and Err.
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// newRequestError creates an *Error of kind for r.
+func newRequestError(kind error, r *http.Request, err error) *Error {
+	return &Error{
+		Kind:    kind,
+		Method:  r.Method,
+		URL:     r.URL.Redacted(),
+		Attempt: ExecutionStateFromContext(r.Context()).Attempt,
+		Err:     err,
+	}
+}
+
+// newResponseError creates an *Error of kind for res. For errors of kind
+// ErrUnexpectedStatus the first bytes of the body are captured; the body
+// remains readable in full.
+func newResponseError(kind error, res *http.Response, err error) *Error {
+	var e *Error
+	if res.Request != nil {
+		e = newRequestError(kind, res.Request, err)
+	} else {
+		e = &Error{Kind: kind, Err: err}
+	}
+	e.StatusCode = res.StatusCode
+
+	if kind == ErrUnexpectedStatus && res.Body != nil {
+		if n := errorBodyLimit(res.Request); n > 0 {
+			e.Body = captureBody(res, n)
+		}
+	}
+
+	return e
+}
+
+// UnexpectedStatusError creates an *Error of kind ErrUnexpectedStatus for res
+// capturing the first bytes of its body as configured using
+// WithErrorBodyLimit. Custom status validators use it to report responses
+// they reject just like ExpectedStatusCode does. The body of res remains
+// readable in full.
+func UnexpectedStatusError(res *http.Response) *Error {
+	return newResponseError(ErrUnexpectedStatus, res, nil)
+}
+
+// DecodeError creates an *Error of kind ErrDecode for res caused by err.
+// Decoders for content types not supported by this package, i.e. provided
+// by separate modules, use it to report failures just like ForJSON does.
+func DecodeError(res *http.Response, err error) *Error {
+	return newResponseError(ErrDecode, res, err)
+}
+
+// captureBody returns up to n bytes read from the body of res. The body is
+// replaced, so it can still be read from the start.
+func captureBody(res *http.Response, n int64) []byte {
+	b, _ := io.ReadAll(io.LimitReader(res.Body, n))
+	res.Body = &multiReadCloser{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+	return b
+}
+
+// IsTimeout reports whether err has been caused by a timeout, either by a
+// context deadline or by a network timeout.
+func IsTimeout(err error) bool {
+	if errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectionError reports whether err has been caused by a failure of the
+// underlying connection, such as a refused or reset connection, a failed DNS
+// lookup or a connection closed before the response has been received
+// completely. Errors of kind ErrDecode are not connection errors, even if
+// the body ended unexpectedly.
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, ErrDecode) {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// IsRetryable reports whether sending the request that failed with err again
+// may succeed. Timeouts and connection errors are considered retryable while
+// canceled requests are not. An *Error carrying the status code of a
+// response is retryable if the status code is one WithRetry retries by
+// default: 429, 502, 503 or 504. Errors not caused by the network, i.e.
+// errors returned by interceptors or of kind ErrDecode, are not retryable.
+//
+// IsRetryable does not consider whether the request is idempotent; this is
+// up to the caller.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrDecode) {
+		return false
+	}
+
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode != 0 {
+		return slices.Contains(defaultRetryStatusCodes, e.StatusCode)
+	}
+
+	return IsTimeout(err) || IsConnectionError(err)
+}
@@ -0,0 +1,153 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestErrorClassification(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	reset := httpclient.New(httpclient.WithFaultInjection(httpclient.FaultInjectionConfig{
+		ResetProbability: 1,
+		Rand:             func() float64 { return 0 },
+	}))
+
+	client := httpclient.New()
+
+	_, refusedErr := client.Get(context.Background(), closed.URL)
+	_, resetErr := reset.Get(context.Background(), slow.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, timeoutErr := client.Get(ctx, slow.URL)
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, canceledErr := client.Get(canceled, slow.URL)
+
+	interceptorErr := errors.New("interceptor failed")
+
+	tests := []struct {
+		name                          string
+		err                           error
+		timeout, connection, retrying bool
+	}{
+		{"refused", refusedErr, false, true, true},
+		{"reset", resetErr, false, true, true},
+		{"timeout", timeoutErr, true, false, true},
+		{"canceled", canceledErr, false, false, false},
+		{"interceptor", interceptorErr, false, false, false},
+		{"eof", io.EOF, false, false, false},
+		{"unexpectedEOF", io.ErrUnexpectedEOF, false, true, true},
+		{"decode", &httpclient.Error{Kind: httpclient.ErrDecode, StatusCode: http.StatusOK, Err: io.ErrUnexpectedEOF}, false, false, false},
+		{"status503", &httpclient.Error{Kind: httpclient.ErrUnexpectedStatus, StatusCode: http.StatusServiceUnavailable}, false, false, true},
+		{"status429", &httpclient.Error{Kind: httpclient.ErrUnexpectedStatus, StatusCode: http.StatusTooManyRequests}, false, false, true},
+		{"status500", &httpclient.Error{Kind: httpclient.ErrUnexpectedStatus, StatusCode: http.StatusInternalServerError}, false, false, false},
+		{"nil", nil, false, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ExpectThat(t, httpclient.IsTimeout(test.err)).Is(Equal(test.timeout))
+			ExpectThat(t, httpclient.IsConnectionError(test.err)).Is(Equal(test.connection))
+			ExpectThat(t, httpclient.IsRetryable(test.err)).Is(Equal(test.retrying))
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/text":
+			w.Write([]byte("not json"))
+		default:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":"conflict"}`))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("unexpectedStatus", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/items", httpclient.ExpectedStatusCode(http.StatusOK))
+
+		ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+		var e *httpclient.Error
+		ExpectThat(t, errors.As(err, &e)).Is(Equal(true))
+		ExpectThat(t, e.Method).Is(Equal(http.MethodGet))
+		ExpectThat(t, e.URL).Is(Equal(testServer.URL + "/items"))
+		ExpectThat(t, e.Attempt).Is(Equal(1))
+		ExpectThat(t, e.StatusCode).Is(Equal(http.StatusConflict))
+		ExpectThat(t, string(e.Body)).Is(Equal(`{"error":"conflict"}`))
+		ExpectThat(t, err.Error()).Is(Equal("GET " + testServer.URL + "/items: unexpected status code: 409"))
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		var v any
+		_, err := client.Get(context.Background(), "/text", httpclient.ForJSON(&v))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Get(ctx, "/slow")
+		ExpectThat(t, err).Is(Error(httpclient.ErrTimeout))
+		ExpectThat(t, err).Is(Error(context.DeadlineExceeded))
+		ExpectThat(t, httpclient.IsRetryable(err)).Is(Equal(true))
+	})
+}
+
+func TestWithErrorBodyLimit(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid value for field name"))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithErrorBodyLimit(7),
+	)
+
+	var e *httpclient.Error
+
+	_, err := client.Get(context.Background(), "/", httpclient.ExpectedStatusCode(http.StatusOK))
+	ExpectThat(t, errors.As(err, &e)).Is(Equal(true))
+	ExpectThat(t, string(e.Body)).Is(Equal("invalid"))
+
+	_, err = client.Get(context.Background(), "/", httpclient.WithErrorBodyLimit(0), httpclient.ExpectedStatusCode(http.StatusOK))
+	ExpectThat(t, errors.As(err, &e)).Is(Equal(true))
+	ExpectThat(t, len(e.Body)).Is(Equal(0))
+
+	var full []byte
+	_, err = client.Get(context.Background(), "/", httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+		if r.StatusCode >= 400 {
+			err := httpclient.UnexpectedStatusError(r)
+			full, _ = io.ReadAll(r.Body)
+			return r, err
+		}
+		return r, nil
+	}))
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+	ExpectThat(t, string(full)).Is(Equal("invalid value for field name"))
+}
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// EventKind defines the kind of an Event.
+type EventKind int
+
+const (
+	// EventRequestStarted is emitted when a request is sent.
+	EventRequestStarted EventKind = iota
+
+	// EventRetryStarted is emitted instead of EventRequestStarted when a
+	// request is sent as a retry of a previous attempt, as denoted by its
+	// ExecutionState.
+	EventRetryStarted
+
+	// EventResponseReceived is emitted when a response has been received.
+	EventResponseReceived
+
+	// EventCacheHit is emitted instead of EventResponseReceived when the
+	// response has been served from a cache, as reported by FromCache.
+	EventCacheHit
+
+	// EventRequestFailed is emitted when sending a request failed without
+	// receiving a response.
+	EventRequestFailed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventRequestStarted:
+		return "request started"
+	case EventRetryStarted:
+		return "retry started"
+	case EventResponseReceived:
+		return "response received"
+	case EventCacheHit:
+		return "cache hit"
+	case EventRequestFailed:
+		return "request failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a step in the lifecycle of a request sent by a Client.
+type Event struct {
+	Kind EventKind
+
+	// Time is the point in time the event occurred.
+	Time time.Time
+
+	// Method and URL identify the request. Any password contained in the
+	// URL is redacted.
+	Method string
+	URL    string
+
+	// PathTemplate is the path template the request's URL has been expanded
+	// from using WithPathParams, if any.
+	PathTemplate string
+
+	// RequestID is the ID assigned to the request, i.e. using WithRequestID.
+	RequestID string
+
+	// Attempt is the attempt number taken from the request's ExecutionState.
+	Attempt int
+
+	// StatusCode is the status code of the received response. It is only set
+	// for EventResponseReceived and EventCacheHit.
+	StatusCode int
+
+	// Duration is the time spent sending the request and receiving the
+	// response headers. It is set for all but the start events.
+	Duration time.Duration
+
+	// Err is the error that caused an EventRequestFailed.
+	Err error
+}
+
+// WithEvents creates a ClientOption that enables the channel returned by
+// Client.Events. The channel buffers up to size events. Events are dropped
+// when the buffer is full, so slow consumers never block requests. Events are
+// emitted for every attempt sent, including the retries made by WithRetry.
+func WithEvents(size int) ClientOption {
+	return clientConfigOption(func(c *Client) {
+		c.events = &eventEmitter{ch: make(chan Event, size)}
+	})
+}
+
+// Events returns the channel receiving lifecycle events of all requests sent
+// by c. It returns nil unless c has been created using WithEvents. The
+// channel is never closed.
+func (c *Client) Events() <-chan Event {
+	if c.events == nil {
+		return nil
+	}
+	return c.events.ch
+}
+
+// eventEmitter emits events for each roundtrip.
+type eventEmitter struct {
+	ch chan Event
+}
+
+func (e *eventEmitter) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	state := ExecutionStateFromContext(r.Context())
+	clock := ClockFromContext(r.Context())
+	start := clock.Now()
+
+	ev := Event{
+		Kind:         EventRequestStarted,
+		Time:         start,
+		Method:       r.Method,
+		URL:          r.URL.Redacted(),
+		PathTemplate: PathTemplateFromContext(r.Context()),
+		RequestID:    RequestIDFromContext(r.Context()),
+		Attempt:      state.Attempt,
+	}
+	if state.IsRetry() {
+		ev.Kind = EventRetryStarted
+	}
+	e.emit(ev)
+
+	res, err := next(r)
+
+	ev.Time = clock.Now()
+	ev.Duration = ev.Time.Sub(start)
+
+	switch {
+	case err != nil:
+		ev.Kind = EventRequestFailed
+		ev.Err = err
+	case FromCache(res):
+		ev.Kind = EventCacheHit
+		ev.StatusCode = res.StatusCode
+	default:
+		ev.Kind = EventResponseReceived
+		ev.StatusCode = res.StatusCode
+	}
+	e.emit(ev)
+
+	return res, err
+}
+
+func (e *eventEmitter) emit(ev Event) {
+	select {
+	case e.ch <- ev:
+	default:
+	}
+}
@@ -0,0 +1,93 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestClient_Events(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cached" {
+			w.Header().Set(httpclient.FromCacheHeader, "1")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithEvents(3),
+	)
+
+	retry := httpclient.ContextWithExecutionState(context.Background(), httpclient.ExecutionState{Attempt: 2})
+
+	_, err := client.Get(context.Background(), "/items")
+	ExpectThat(t, err).Is(NoError())
+	_, err = client.Get(retry, "/cached")
+	ExpectThat(t, err).Is(NoError())
+
+	var kinds []httpclient.EventKind
+	for len(client.Events()) > 0 {
+		ev := <-client.Events()
+		kinds = append(kinds, ev.Kind)
+		ExpectThat(t, ev.Method).Is(Equal(http.MethodGet))
+	}
+
+	// The fourth event has been dropped as the buffer was full.
+	ExpectThat(t, kinds).Is(DeepEqual([]httpclient.EventKind{
+		httpclient.EventRequestStarted,
+		httpclient.EventResponseReceived,
+		httpclient.EventRetryStarted,
+	}))
+
+	_, err = client.Get(context.Background(), "http://127.0.0.1:0/")
+	ExpectThat(t, err).Is(NotNil())
+	<-client.Events()
+	ev := <-client.Events()
+	ExpectThat(t, ev.Kind).Is(Equal(httpclient.EventRequestFailed))
+	ExpectThat(t, ev.Err).Is(NotNil())
+
+	ExpectThat(t, httpclient.New().Events() == nil).Is(Equal(true))
+}
+
+func TestClient_Events_withRetry(t *testing.T) {
+	var calls atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRetry(httpclient.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		httpclient.WithEvents(10),
+	)
+
+	_, err := client.Get(context.Background(), "/items")
+	ExpectThat(t, err).Is(NoError())
+
+	var kinds []httpclient.EventKind
+	for len(client.Events()) > 0 {
+		kinds = append(kinds, (<-client.Events()).Kind)
+	}
+
+	ExpectThat(t, kinds).Is(DeepEqual([]httpclient.EventKind{
+		httpclient.EventRequestStarted,
+		httpclient.EventResponseReceived,
+		httpclient.EventRetryStarted,
+		httpclient.EventResponseReceived,
+		httpclient.EventRetryStarted,
+		httpclient.EventResponseReceived,
+	}))
+}
@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// ExecutionState describes how the current execution of a request relates to
+// other executions of the same logical request. Retrying or hedging code
+// stores the state in the request's context using ContextWithExecutionState
+// so interceptors can treat repeated executions differently, i.e. by not
+// counting them twice.
+type ExecutionState struct {
+	// Attempt is the 1-based number of the attempt. Values greater than 1
+	// denote retries.
+	Attempt int
+
+	// Hedged is set for executions issued in parallel to a still pending
+	// execution of the same request.
+	Hedged bool
+}
+
+// IsRetry reports whether s describes a retry of a previous attempt.
+func (s ExecutionState) IsRetry() bool {
+	return s.Attempt > 1
+}
+
+// executionStateKey is the context key used to store the ExecutionState.
+type executionStateKey struct{}
+
+// ContextWithExecutionState returns a copy of ctx carrying s.
+func ContextWithExecutionState(ctx context.Context, s ExecutionState) context.Context {
+	return context.WithValue(ctx, executionStateKey{}, s)
+}
+
+// ExecutionStateFromContext returns the ExecutionState stored in ctx. If ctx
+// carries no state, the state of an initial, non-hedged attempt is returned.
+func ExecutionStateFromContext(ctx context.Context) ExecutionState {
+	if s, ok := ctx.Value(executionStateKey{}).(ExecutionState); ok {
+		return s
+	}
+	return ExecutionState{Attempt: 1}
+}
+
+// FromCacheHeader is the response header used to mark responses that have
+// been served from a cache rather than from the origin server. The header is
+// compatible with the one set by widely used caching transports.
+const FromCacheHeader = "X-From-Cache"
+
+// MarkFromCache marks r as being served from a cache.
+func MarkFromCache(r *http.Response) {
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set(FromCacheHeader, "1")
+}
+
+// FromCache reports whether r has been served from a cache.
+func FromCache(r *http.Response) bool {
+	return r.Header.Get(FromCacheHeader) == "1"
+}
@@ -0,0 +1,50 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestExecutionState(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cached" {
+			w.Header().Set(httpclient.FromCacheHeader, "1")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var state httpclient.ExecutionState
+	var cached bool
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			state = httpclient.ExecutionStateFromContext(r.Request.Context())
+			cached = httpclient.FromCache(r)
+			return r, nil
+		}),
+	)
+
+	t.Run("initial", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, state).Is(Equal(httpclient.ExecutionState{Attempt: 1}))
+		ExpectThat(t, state.IsRetry()).Is(Equal(false))
+		ExpectThat(t, cached).Is(Equal(false))
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		ctx := httpclient.ContextWithExecutionState(context.Background(), httpclient.ExecutionState{Attempt: 2, Hedged: true})
+		_, err := client.Get(ctx, "/cached")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, state.IsRetry()).Is(Equal(true))
+		ExpectThat(t, state.Hedged).Is(Equal(true))
+		ExpectThat(t, cached).Is(Equal(true))
+	})
+}
@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// FaultInjectionConfig configures the faults injected by WithFaultInjection.
+// All probabilities are given in the range [0, 1]; a probability of 0
+// disables the corresponding fault.
+type FaultInjectionConfig struct {
+	// Latency is added before a request is sent with a probability of
+	// LatencyProbability.
+	Latency            time.Duration
+	LatencyProbability float64
+
+	// ResetProbability is the probability of failing a request with a
+	// connection reset error without sending it.
+	ResetProbability float64
+
+	// ErrorProbability is the probability of answering a request with a
+	// response using ErrorStatus without sending it. ErrorStatus defaults to
+	// 503 Service Unavailable.
+	ErrorProbability float64
+	ErrorStatus      int
+
+	// TruncateProbability is the probability of truncating a response body
+	// after half of its content. Reading past the truncation point fails with
+	// io.ErrUnexpectedEOF.
+	TruncateProbability float64
+
+	// Rand returns pseudo-random numbers in [0, 1) used to decide whether a
+	// fault is injected. It defaults to rand.Float64 and can be replaced to get
+	// deterministic results.
+	Rand func() float64
+}
+
+// WithFaultInjection creates a ClientOption that wraps the client's transport
+// to inject faults as configured by cfg. This is meant for verifying the
+// behaviour of clients in the presence of failures, i.e. in integration
+// tests. Options replacing the transport must be given before this option.
+func WithFaultInjection(cfg FaultInjectionConfig) ClientOption {
+	if cfg.ErrorStatus == 0 {
+		cfg.ErrorStatus = http.StatusServiceUnavailable
+	}
+
+	if cfg.Rand == nil {
+		cfg.Rand = rand.Float64
+	}
+
+	return HTTPClientOption(func(c *http.Client) {
+		next := c.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		c.Transport = &faultInjectingTransport{cfg: cfg, next: next}
+	})
+}
+
+type faultInjectingTransport struct {
+	cfg  FaultInjectionConfig
+	next http.RoundTripper
+}
+
+func (t *faultInjectingTransport) inject(probability float64) bool {
+	return probability > 0 && t.cfg.Rand() < probability
+}
+
+func (t *faultInjectingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.inject(t.cfg.LatencyProbability) {
+		if err := sleep(req.Context(), ClockFromContext(req.Context()), t.cfg.Latency); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.inject(t.cfg.ResetProbability) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &net.OpError{
+			Op:  "read",
+			Net: "tcp",
+			Err: os.NewSyscallError("read", syscall.ECONNRESET),
+		}
+	}
+
+	if t.inject(t.cfg.ErrorProbability) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		body := "injected fault"
+		return &http.Response{
+			Status:        strconv.Itoa(t.cfg.ErrorStatus) + " " + http.StatusText(t.cfg.ErrorStatus),
+			StatusCode:    t.cfg.ErrorStatus,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+
+	if t.inject(t.cfg.TruncateProbability) {
+		b, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		res.Body = io.NopCloser(io.MultiReader(bytes.NewReader(b[:len(b)/2]), errReader{io.ErrUnexpectedEOF}))
+	}
+
+	return res, nil
+}
+
+// errReader is an io.Reader that always fails with err.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
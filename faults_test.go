@@ -0,0 +1,73 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithFaultInjection(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	}))
+	defer testServer.Close()
+
+	always := func() float64 { return 0 }
+
+	t.Run("reset", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithFaultInjection(httpclient.FaultInjectionConfig{
+			ResetProbability: 1,
+			Rand:             always,
+		}))
+
+		_, err := client.Get(context.Background(), testServer.URL)
+		ExpectThat(t, errors.Is(err, syscall.ECONNRESET)).Is(Equal(true))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithFaultInjection(httpclient.FaultInjectionConfig{
+			ErrorProbability: 1,
+			ErrorStatus:      http.StatusBadGateway,
+			Rand:             always,
+		}))
+
+		res, err := client.Get(context.Background(), testServer.URL)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusBadGateway))
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithFaultInjection(httpclient.FaultInjectionConfig{
+			TruncateProbability: 1,
+			Rand:                always,
+		}))
+
+		var body []byte
+		_, err := client.Get(context.Background(), testServer.URL,
+			httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+				var err error
+				body, err = io.ReadAll(r.Body)
+				return r, err
+			}),
+		)
+		ExpectThat(t, err).Is(Error(io.ErrUnexpectedEOF))
+		ExpectThat(t, string(body)).Is(Equal("01234"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithFaultInjection(httpclient.FaultInjectionConfig{
+			Rand: always,
+		}))
+
+		res, err := client.Get(context.Background(), testServer.URL)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusOK))
+	})
+}
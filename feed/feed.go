@@ -0,0 +1,371 @@
+// Package feed decodes RSS 2.0 and Atom 1.0 feeds received using an
+// httpclient.Client into a unified Feed. Source fetches feeds using
+// conditional requests, so polling a feed transfers it only if it has
+// changed.
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/halimath/httpclient"
+)
+
+// Accept is the value of the Accept header sent by ForFeed.
+const Accept = "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8"
+
+// contentTypes lists the content types accepted for feeds.
+var contentTypes = []string{"application/rss+xml", "application/atom+xml", "application/xml", "text/xml"}
+
+// Feed is a feed decoded from either RSS or Atom.
+type Feed struct {
+	// Format is either "rss" or "atom".
+	Format string
+
+	Title       string
+	Description string
+	Link        string
+
+	// Updated is the time the feed has been updated last or the zero time
+	// if the feed doesn't tell. All times are given in UTC.
+	Updated time.Time
+
+	Items []Item
+}
+
+// Item is an item of an RSS feed or an entry of an Atom feed.
+type Item struct {
+	// ID is the RSS guid or the Atom id of the item.
+	ID string
+
+	Title   string
+	Link    string
+	Author  string
+	Summary string
+
+	// Content is the full content of the item, taken from the Atom content
+	// element or the RSS content:encoded extension.
+	Content string
+
+	Published time.Time
+	Updated   time.Time
+}
+
+// ForFeed creates an httpclient.Option that decodes an RSS or Atom response
+// body into f, detecting the format from the document's root element. It
+// adds an Accept header preferring feed content types and fails with an
+// *httpclient.Error of kind httpclient.ErrDecode if the response has a
+// content type other than application/rss+xml, application/atom+xml,
+// application/xml or text/xml, or if the body is no valid feed. The response
+// body remains readable after it has been decoded.
+func ForFeed(f *Feed) httpclient.Option {
+	return &forFeed{
+		RequestInterceptorOption: httpclient.WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+			if r.Header.Get("Accept") == "" {
+				r.Header.Set("Accept", Accept)
+			}
+			return r, nil
+		}),
+		feed: f,
+	}
+}
+
+// forFeed embeds the RequestInterceptorOption setting the Accept header,
+// which makes it an httpclient.Option, and adds the ResponseInterceptor
+// decoding the body.
+type forFeed struct {
+	httpclient.RequestInterceptorOption
+	feed *Feed
+}
+
+func (f *forFeed) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !slices.Contains(contentTypes, ct) {
+		return r, httpclient.DecodeError(r, fmt.Errorf("expected feed response but got %s", r.Header.Get("Content-Type")))
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return r, err
+	}
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(b), r.Body}
+
+	feed, err := Parse(b)
+	if err != nil {
+		return r, httpclient.DecodeError(r, err)
+	}
+
+	*f.feed = *feed
+	return r, nil
+}
+
+// Source is a feed fetched using conditional requests. ETag and
+// LastModified are updated whenever the feed is fetched, so they can be
+// stored to continue polling a feed after a restart.
+type Source struct {
+	URL          string
+	ETag         string
+	LastModified string
+}
+
+// Fetch fetches the feed using c and opts. The request carries an
+// If-None-Match and an If-Modified-Since header based on the values
+// received before. It returns the feed and true if the feed has been
+// received or nil and false if the server reported it as not modified.
+// Responses with a status code other than 200 and 304 are reported as an
+// *httpclient.Error of kind httpclient.ErrUnexpectedStatus.
+func (s *Source) Fetch(ctx context.Context, c *httpclient.Client, opts ...httpclient.RequestOption) (*Feed, bool, error) {
+	var feed *Feed
+
+	opts = append(opts[:len(opts):len(opts)],
+		httpclient.WithRequestHeader("Accept", Accept),
+		httpclient.ExpectedStatusCode(http.StatusOK, http.StatusNotModified),
+		httpclient.InPhaseFunc(httpclient.PhasePostValidate, func(r *http.Response) (*http.Response, error) {
+			if r.StatusCode != http.StatusOK {
+				return r, nil
+			}
+			feed = new(Feed)
+			return (&forFeed{feed: feed}).InterceptResponse(r)
+		}),
+	)
+	if s.ETag != "" {
+		opts = append(opts, httpclient.WithRequestHeader("If-None-Match", s.ETag))
+	}
+	if s.LastModified != "" {
+		opts = append(opts, httpclient.WithRequestHeader("If-Modified-Since", s.LastModified))
+	}
+
+	res, err := c.Get(ctx, s.URL, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, false, nil
+	}
+
+	s.ETag = res.Header.Get("ETag")
+	s.LastModified = res.Header.Get("Last-Modified")
+
+	return feed, true, nil
+}
+
+// Parse parses an RSS 2.0 or Atom 1.0 document.
+func Parse(b []byte) (*Feed, error) {
+	root, err := rootElement(b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		var doc rssDocument
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		return doc.feed(), nil
+
+	case "feed":
+		var doc atomFeed
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		return doc.feed(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported feed format: %s", root)
+	}
+}
+
+// rootElement returns the local name of the root element of the XML
+// document b.
+func rootElement(b []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return "", errors.New("empty document")
+			}
+			return "", err
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		PubDate       string    `xml:"pubDate"`
+		Items         []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	GUID        string `xml:"guid"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+}
+
+func (d *rssDocument) feed() *Feed {
+	f := &Feed{
+		Format:      "rss",
+		Title:       strings.TrimSpace(d.Channel.Title),
+		Description: strings.TrimSpace(d.Channel.Description),
+		Link:        strings.TrimSpace(d.Channel.Link),
+		Updated:     parseTime(d.Channel.LastBuildDate),
+	}
+	if f.Updated.IsZero() {
+		f.Updated = parseTime(d.Channel.PubDate)
+	}
+
+	for _, i := range d.Channel.Items {
+		author := i.Author
+		if author == "" {
+			author = i.Creator
+		}
+
+		published := parseTime(i.PubDate)
+		f.Items = append(f.Items, Item{
+			ID:        strings.TrimSpace(i.GUID),
+			Title:     strings.TrimSpace(i.Title),
+			Link:      strings.TrimSpace(i.Link),
+			Author:    strings.TrimSpace(author),
+			Summary:   strings.TrimSpace(i.Description),
+			Content:   strings.TrimSpace(i.Content),
+			Published: published,
+			Updated:   published,
+		})
+	}
+
+	return f
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",innerxml"`
+}
+
+// text returns the text of t. XHTML content is returned as is, while text
+// and HTML content is unescaped.
+func (t atomText) text() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Value)
+	}
+
+	var s string
+	if err := xml.Unmarshal([]byte("<t>"+t.Value+"</t>"), &s); err != nil {
+		return strings.TrimSpace(t.Value)
+	}
+	return strings.TrimSpace(s)
+}
+
+type atomFeed struct {
+	Title    atomText    `xml:"http://www.w3.org/2005/Atom title"`
+	Subtitle atomText    `xml:"http://www.w3.org/2005/Atom subtitle"`
+	Links    []atomLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Updated  string      `xml:"http://www.w3.org/2005/Atom updated"`
+	Entries  []atomEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"http://www.w3.org/2005/Atom id"`
+	Title     atomText   `xml:"http://www.w3.org/2005/Atom title"`
+	Links     []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+	Authors   []string   `xml:"http://www.w3.org/2005/Atom author>name"`
+	Summary   atomText   `xml:"http://www.w3.org/2005/Atom summary"`
+	Content   atomText   `xml:"http://www.w3.org/2005/Atom content"`
+	Published string     `xml:"http://www.w3.org/2005/Atom published"`
+	Updated   string     `xml:"http://www.w3.org/2005/Atom updated"`
+}
+
+func (d *atomFeed) feed() *Feed {
+	f := &Feed{
+		Format:      "atom",
+		Title:       d.Title.text(),
+		Description: d.Subtitle.text(),
+		Link:        alternateLink(d.Links),
+		Updated:     parseTime(d.Updated),
+	}
+
+	for _, e := range d.Entries {
+		f.Items = append(f.Items, Item{
+			ID:        strings.TrimSpace(e.ID),
+			Title:     e.Title.text(),
+			Link:      alternateLink(e.Links),
+			Author:    strings.TrimSpace(strings.Join(e.Authors, ", ")),
+			Summary:   e.Summary.text(),
+			Content:   e.Content.text(),
+			Published: parseTime(e.Published),
+			Updated:   parseTime(e.Updated),
+		})
+	}
+
+	return f
+}
+
+// alternateLink returns the href of the alternate link, which is the
+// default relation if none is given.
+func alternateLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+// timeLayouts lists the layouts used to parse dates in feeds, covering RFC
+// 822 dates used by RSS - including common deviations - and RFC 3339 dates
+// used by Atom.
+var timeLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// parseTime parses s using any of timeLayouts and returns it in UTC. It
+// returns the zero time if s can't be parsed.
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
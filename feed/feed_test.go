@@ -0,0 +1,154 @@
+package feed_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/feed"
+)
+
+const rss = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Example</title>
+    <link>https://example.com/</link>
+    <description>An example feed</description>
+    <lastBuildDate>Wed, 01 May 2024 12:00:00 +0000</lastBuildDate>
+    <item>
+      <guid>1</guid>
+      <title>First post</title>
+      <link>https://example.com/1</link>
+      <author>alice@example.com</author>
+      <description>Summary</description>
+      <content:encoded><![CDATA[<p>Content</p>]]></content:encoded>
+      <pubDate>Wed, 1 May 2024 10:00:00 +0000</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+const atom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example</title>
+  <subtitle>An example feed</subtitle>
+  <link href="https://example.com/feed" rel="self"/>
+  <link href="https://example.com/"/>
+  <updated>2024-05-01T12:00:00Z</updated>
+  <entry>
+    <id>urn:1</id>
+    <title type="html">First &lt;em&gt;post&lt;/em&gt;</title>
+    <link href="https://example.com/1" rel="alternate"/>
+    <author><name>Alice</name></author>
+    <summary>Summary</summary>
+    <content type="html">&lt;p&gt;Content&lt;/p&gt;</content>
+    <published>2024-05-01T10:00:00Z</published>
+    <updated>2024-05-01T11:00:00Z</updated>
+  </entry>
+</feed>`
+
+func TestForFeed(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(rss))
+		case "/atom":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.Write([]byte(atom))
+		default:
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(`<html/>`))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("rss", func(t *testing.T) {
+		var f feed.Feed
+		_, err := client.Get(context.Background(), "/rss", feed.ForFeed(&f))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, f).Is(DeepEqual(feed.Feed{
+			Format:      "rss",
+			Title:       "Example",
+			Description: "An example feed",
+			Link:        "https://example.com/",
+			Updated:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			Items: []feed.Item{{
+				ID:        "1",
+				Title:     "First post",
+				Link:      "https://example.com/1",
+				Author:    "alice@example.com",
+				Summary:   "Summary",
+				Content:   "<p>Content</p>",
+				Published: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Updated:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			}},
+		}))
+	})
+
+	t.Run("atom", func(t *testing.T) {
+		var f feed.Feed
+		_, err := client.Get(context.Background(), "/atom", feed.ForFeed(&f))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, f).Is(DeepEqual(feed.Feed{
+			Format:      "atom",
+			Title:       "Example",
+			Description: "An example feed",
+			Link:        "https://example.com/",
+			Updated:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			Items: []feed.Item{{
+				ID:        "urn:1",
+				Title:     "First <em>post</em>",
+				Link:      "https://example.com/1",
+				Author:    "Alice",
+				Summary:   "Summary",
+				Content:   "<p>Content</p>",
+				Published: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Updated:   time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+			}},
+		}))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		var f feed.Feed
+		_, err := client.Get(context.Background(), "/html", feed.ForFeed(&f))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+}
+
+func TestSource_Fetch(t *testing.T) {
+	var requests int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 12:00:00 GMT")
+		w.Write([]byte(rss))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New()
+	src := feed.Source{URL: testServer.URL}
+
+	f, modified, err := src.Fetch(context.Background(), client)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, modified).Is(Equal(true))
+	ExpectThat(t, f.Title).Is(Equal("Example"))
+	ExpectThat(t, src.ETag).Is(Equal(`"v1"`))
+	ExpectThat(t, src.LastModified).Is(Equal("Wed, 01 May 2024 12:00:00 GMT"))
+
+	f, modified, err = src.Fetch(context.Background(), client)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, modified).Is(Equal(false))
+	ExpectThat(t, f == nil).Is(Equal(true))
+	ExpectThat(t, requests).Is(Equal(2))
+}
@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// WithForm creates a RequestInterceptorOption that uses values encoded as
+// application/x-www-form-urlencoded as the request's body, i.e. to submit
+// credentials to an OAuth 2.0 token endpoint. Like WithJSON, the interceptor
+// sets the Content-Type and Content-Length headers as well as the request's
+// GetBody function, so the request can be retried. If the request had a
+// previous non-nil Body this value is closed before.
+func WithForm(values url.Values) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		return withForm(r, values)
+	})
+}
+
+// WithFormStruct creates a RequestInterceptorOption that encodes the exported
+// fields of the struct v (or a pointer to it) as the request's form body.
+// Fields are encoded as described for WithQueryStruct; the body is set as
+// described for WithForm. Any error produced while encoding v is returned
+// when the request is executed.
+func WithFormStruct(v any) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		values, err := encodeQueryStruct(v)
+		if err != nil {
+			return r, err
+		}
+
+		return withForm(r, values)
+	})
+}
+
+func withForm(r *http.Request, values url.Values) (*http.Request, error) {
+	b := []byte(values.Encode())
+
+	r, err := withBody(bytes.NewReader(b), "application/x-www-form-urlencoded", int64(len(b))).InterceptRequest(r)
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return r, err
+}
@@ -0,0 +1,64 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithForm(t *testing.T) {
+	var contentType string
+	var contentLength int64
+	var form url.Values
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		contentLength = r.ContentLength
+		r.ParseForm()
+		form = r.PostForm
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("values", func(t *testing.T) {
+		_, err := client.Post(context.Background(), "/", httpclient.WithForm(url.Values{
+			"grant_type": {"client_credentials"},
+			"scope":      {"read write"},
+		}))
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, contentType).Is(Equal("application/x-www-form-urlencoded"))
+		ExpectThat(t, contentLength).Is(Equal(int64(len("grant_type=client_credentials&scope=read+write"))))
+		ExpectThat(t, form).Is(DeepEqual(url.Values{
+			"grant_type": {"client_credentials"},
+			"scope":      {"read write"},
+		}))
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		type login struct {
+			Username string `url:"username"`
+			Password string `url:"password"`
+			Remember bool   `url:"remember,omitempty"`
+		}
+
+		_, err := client.Post(context.Background(), "/", httpclient.WithFormStruct(login{Username: "jdoe", Password: "secret"}))
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, form).Is(DeepEqual(url.Values{
+			"username": {"jdoe"},
+			"password": {"secret"},
+		}))
+	})
+
+	t.Run("invalid struct", func(t *testing.T) {
+		_, err := client.Post(context.Background(), "/", httpclient.WithFormStruct("foo"))
+		ExpectThat(t, err).Is(NotNil())
+	})
+}
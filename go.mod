@@ -1,9 +1,10 @@
 module github.com/halimath/httpclient
 
-go 1.18
+go 1.23
 
 require (
-	github.com/deckarep/golang-set/v2 v2.1.0 // indirect
-	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7 // indirect
-	github.com/mccutchen/go-httpbin/v2 v2.4.1 // indirect
+	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7
+	github.com/mccutchen/go-httpbin/v2 v2.4.1
 )
+
+require github.com/deckarep/golang-set/v2 v2.1.0 // indirect
@@ -0,0 +1,115 @@
+// Package googlehttpclient integrates httpclient with Google Cloud
+// authentication. Requests are authenticated using access tokens or ID
+// tokens obtained from Application Default Credentials, i.e. the metadata
+// server when running on Google Cloud, a service account key referenced by
+// GOOGLE_APPLICATION_CREDENTIALS or the credentials created by
+// "gcloud auth application-default login". It is provided as a separate
+// module so that applications not calling Google Cloud don't depend on
+// golang.org/x/oauth2 and google.golang.org/api.
+package googlehttpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/halimath/httpclient"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/idtoken"
+)
+
+// DefaultScope is the OAuth 2.0 scope requested by WithAccessToken if no
+// scopes are given.
+const DefaultScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// WithAccessToken creates an Option authenticating requests with an OAuth
+// 2.0 access token for scopes obtained from Application Default Credentials,
+// as required by Google APIs. scopes default to DefaultScope. Tokens are
+// cached and refreshed before they expire. ctx is used to fetch tokens, so
+// it must not be canceled while the option is in use.
+func WithAccessToken(ctx context.Context, scopes ...string) (httpclient.RequestInterceptorOption, error) {
+	if len(scopes) == 0 {
+		scopes = []string{DefaultScope}
+	}
+
+	creds, err := google.FindDefaultCredentials(ctx, scopes...)
+	if err != nil {
+		return httpclient.RequestInterceptorOption{}, fmt.Errorf("googlehttpclient: %w", err)
+	}
+
+	return WithTokenSource(creds.TokenSource), nil
+}
+
+// WithIDToken creates an Option authenticating requests with an OpenID
+// Connect ID token for audience obtained from Application Default
+// Credentials, as required by Cloud Run services, Cloud Functions and
+// endpoints protected by Identity-Aware Proxy. audience is the URL of the
+// Cloud Run service or the OAuth client ID of the IAP-protected resource.
+// Tokens are cached and refreshed before they expire. ctx is used to fetch
+// tokens, so it must not be canceled while the option is in use.
+//
+// ID tokens can only be obtained for service accounts, either from the
+// metadata server or a service account key file.
+func WithIDToken(ctx context.Context, audience string) (httpclient.RequestInterceptorOption, error) {
+	ts, err := idtoken.NewTokenSource(ctx, audience)
+	if err != nil {
+		return httpclient.RequestInterceptorOption{}, fmt.Errorf("googlehttpclient: %w", err)
+	}
+
+	return WithTokenSource(ts), nil
+}
+
+// WithTokenSource creates an Option authenticating requests with the tokens
+// returned by ts, i.e. to use credentials not found by Application Default
+// Credentials. The Authorization header is set in
+// httpclient.RequestPhaseAuth, replacing any existing one. Wrap ts using
+// oauth2.ReuseTokenSource unless it caches tokens itself.
+func WithTokenSource(ts oauth2.TokenSource) httpclient.RequestInterceptorOption {
+	return httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, func(r *http.Request) (*http.Request, error) {
+		tok, err := ts.Token()
+		if err != nil {
+			return r, fmt.Errorf("googlehttpclient: failed to obtain token: %w", err)
+		}
+
+		tok.SetAuthHeader(r)
+		return r, nil
+	})
+}
+
+// CachedTokenSource creates an oauth2.TokenSource returning tokens stored in
+// cache for key as long as they are valid, falling back to ts and storing
+// the tokens it returns. Tokens are kept in memory once obtained, so cache
+// is only consulted when a new token is needed. Use it with WithTokenSource and an
+// httpclient.FileTokenCache to reuse tokens across runs of short-lived
+// processes. Choose key to identify the credentials and scopes or audience
+// of ts.
+func CachedTokenSource(cache httpclient.TokenCache, key string, ts oauth2.TokenSource) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &cachedTokenSource{cache: cache, key: key, ts: ts})
+}
+
+type cachedTokenSource struct {
+	cache httpclient.TokenCache
+	key   string
+	ts    oauth2.TokenSource
+}
+
+func (c *cachedTokenSource) Token() (*oauth2.Token, error) {
+	if t, ok := c.cache.Get(c.key); ok {
+		tok := &oauth2.Token{AccessToken: t.Value, TokenType: "Bearer", Expiry: t.Expiry}
+		if tok.Valid() {
+			return tok, nil
+		}
+	}
+
+	tok, err := c.ts.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok.Type() == "Bearer" {
+		c.cache.Set(c.key, httpclient.Token{Value: tok.AccessToken, Expiry: tok.Expiry})
+	}
+
+	return tok, nil
+}
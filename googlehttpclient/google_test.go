@@ -0,0 +1,75 @@
+package googlehttpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/googlehttpclient"
+	"golang.org/x/oauth2"
+)
+
+type failingTokenSource struct{}
+
+func (failingTokenSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("no token")
+}
+
+func TestWithTokenSource(t *testing.T) {
+	var auth string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		googlehttpclient.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})),
+	)
+
+	_, err := client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, auth).Is(Equal("Bearer token"))
+
+	_, err = client.Get(context.Background(), "/", googlehttpclient.WithTokenSource(failingTokenSource{}))
+	ExpectThat(t, err).Is(NotNil())
+}
+
+func TestWithIDToken_invalidCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	ExpectThat(t, os.WriteFile(path, []byte(`{"type":"authorized_user"}`), 0o600)).Is(NoError())
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+	_, err := googlehttpclient.WithIDToken(context.Background(), "https://service.run.app")
+	ExpectThat(t, err).Is(NotNil())
+}
+
+type countingTokenSource struct {
+	calls int
+}
+
+func (c *countingTokenSource) Token() (*oauth2.Token, error) {
+	c.calls++
+	return &oauth2.Token{AccessToken: "token", TokenType: "Bearer", Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestCachedTokenSource(t *testing.T) {
+	cache, err := httpclient.NewFileTokenCache(filepath.Join(t.TempDir(), "tokens"), make([]byte, 32))
+	ExpectThat(t, err).Is(NoError())
+
+	var ts countingTokenSource
+	for range 2 {
+		tok, err := googlehttpclient.CachedTokenSource(cache, "adc", &ts).Token()
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, tok.AccessToken).Is(Equal("token"))
+	}
+
+	ExpectThat(t, ts.calls).Is(Equal(1))
+}
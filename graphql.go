@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WithGraphQL creates a RequestInterceptorOption that uses query and
+// variables as the JSON encoded body of a GraphQL request as described by
+// the GraphQL over HTTP specification. Send it using Post and decode the
+// response using ForGraphQL:
+//
+//	var data struct {
+//		User struct {
+//			Name string `json:"name"`
+//		} `json:"user"`
+//	}
+//	_, err := client.Post(ctx, "/graphql",
+//		httpclient.WithGraphQL(`query($id: ID!) { user(id: $id) { name } }`, map[string]any{"id": "42"}),
+//		httpclient.ForGraphQL(&data),
+//	)
+//
+// A nil variables map is omitted from the request.
+func WithGraphQL(query string, variables map[string]any) RequestInterceptorOption {
+	return WithJSON(graphQLRequest{Query: query, Variables: variables})
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLLocation is a location in a GraphQL document.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLErrorEntry is a single error reported in the errors field of a
+// GraphQL response.
+type GraphQLErrorEntry struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+// GraphQLError is returned by ForGraphQL if a GraphQL response reports
+// errors.
+type GraphQLError struct {
+	Errors []GraphQLErrorEntry
+}
+
+func (e *GraphQLError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, entry := range e.Errors {
+		msgs[i] = entry.Message
+	}
+	return fmt.Sprintf("graphql: %s", strings.Join(msgs, "; "))
+}
+
+// ForGraphQL creates a RequestOption decoding the data field of a GraphQL
+// response into data. If the response's errors field is not empty, a
+// *GraphQLError is returned; as GraphQL responses may contain partial data,
+// data is decoded nevertheless. The response is decoded like ForJSON does,
+// so a response with a different content type or a body that can't be
+// decoded causes an *Error of kind ErrDecode. Both application/json and
+// application/graphql-response+json are accepted.
+func ForGraphQL(data any) RequestOption {
+	return &forGraphQL{data}
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage     `json:"data"`
+	Errors []GraphQLErrorEntry `json:"errors"`
+}
+
+// forGraphQL is the RequestInterceptor and ResponseInterceptor created by
+// ForGraphQL.
+type forGraphQL struct {
+	data any
+}
+
+func (*forGraphQL) clientOpt() {}
+func (*forGraphQL) reqOpt()    {}
+
+func (*forGraphQL) InterceptRequest(r *http.Request) (*http.Request, error) {
+	r.Header.Add("Accept", "application/graphql-response+json, application/json")
+	return r, nil
+}
+
+func (g *forGraphQL) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct := r.Header.Get("Content-Type")
+	if !hasMediaType(ct, []string{"application/json", "application/graphql-response+json"}) {
+		return r, newResponseError(ErrDecode, r, fmt.Errorf("expected GraphQL response but got %s", ct))
+	}
+
+	var res graphQLResponse
+	r, err := (&forJSON{value: &res}).decode(r)
+	if err != nil {
+		return r, err
+	}
+
+	if len(res.Data) > 0 && string(res.Data) != "null" {
+		unmarshal := json.Unmarshal
+		if codec := jsonCodecFromContext(responseContext(r)); codec != nil {
+			unmarshal = codec.unmarshal
+		}
+		if err := unmarshal(res.Data, g.data); err != nil {
+			return r, newResponseError(ErrDecode, r, err)
+		}
+	}
+
+	if len(res.Errors) > 0 {
+		return r, &GraphQLError{Errors: res.Errors}
+	}
+
+	return r, nil
+}
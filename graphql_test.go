@@ -0,0 +1,70 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestGraphQL(t *testing.T) {
+	var request struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "application/graphql-response+json")
+		if request.Variables["id"] == "unknown" {
+			w.Write([]byte(`{"data":{"user":null},"errors":[{"message":"user not found","path":["user"],"locations":[{"line":1,"column":20}]}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"user":{"name":"Alice"}}}`))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	type data struct {
+		User *struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var d data
+		_, err := client.Post(context.Background(), "/graphql",
+			httpclient.WithGraphQL("query($id: ID!) { user(id: $id) { name } }", map[string]any{"id": "42"}),
+			httpclient.ForGraphQL(&d),
+		)
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, d.User.Name).Is(Equal("Alice"))
+		ExpectThat(t, request.Query).Is(Equal("query($id: ID!) { user(id: $id) { name } }"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var d data
+		_, err := client.Post(context.Background(), "/graphql",
+			httpclient.WithGraphQL("query($id: ID!) { user(id: $id) { name } }", map[string]any{"id": "unknown"}),
+			httpclient.ForGraphQL(&d),
+		)
+
+		var gqlErr *httpclient.GraphQLError
+		ExpectThat(t, errors.As(err, &gqlErr)).Is(Equal(true))
+		ExpectThat(t, gqlErr.Error()).Is(Equal("graphql: user not found"))
+		ExpectThat(t, gqlErr.Errors).Is(DeepEqual([]httpclient.GraphQLErrorEntry{{
+			Message:   "user not found",
+			Path:      []any{"user"},
+			Locations: []httpclient.GraphQLLocation{{Line: 1, Column: 20}},
+		}}))
+		ExpectThat(t, d.User == nil).Is(Equal(true))
+	})
+}
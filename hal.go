@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// HALLink is a link of a HAL (JSON Hypertext Application Language) resource.
+type HALLink struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// HALLinks maps relation types to the links of a HAL resource. HAL allows a
+// relation to be given as a single link object or as an array of link
+// objects; both are decoded into a slice.
+type HALLinks map[string][]HALLink
+
+func (l *HALLinks) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	links := make(HALLinks, len(raw))
+	for rel, r := range raw {
+		var many []HALLink
+		if err := json.Unmarshal(r, &many); err == nil {
+			links[rel] = many
+			continue
+		}
+
+		var one HALLink
+		if err := json.Unmarshal(r, &one); err != nil {
+			return fmt.Errorf("hal: link %s: %w", rel, err)
+		}
+		links[rel] = []HALLink{one}
+	}
+
+	*l = links
+	return nil
+}
+
+// ErrNoLink is returned by FollowLink if a response contains no link with
+// the requested relation type.
+var ErrNoLink = errors.New("no such link")
+
+// halLinksKey is the context key used to store the HALLinks of a response.
+type halLinksKey struct{}
+
+// ForHAL creates a RequestOption that decodes a HAL response into v like
+// ForJSON does and retains the response's _links, so they can be followed
+// using Client.FollowLink or obtained using HALLinksOf. Both
+// application/hal+json and application/json responses are accepted.
+func ForHAL(v any) RequestOption {
+	return &forHAL{v}
+}
+
+// forHAL is the RequestInterceptor and ResponseInterceptor created by
+// ForHAL.
+type forHAL struct {
+	value any
+}
+
+func (*forHAL) clientOpt() {}
+func (*forHAL) reqOpt()    {}
+
+func (*forHAL) InterceptRequest(r *http.Request) (*http.Request, error) {
+	r.Header.Add("Accept", "application/hal+json, application/json")
+	return r, nil
+}
+
+func (h *forHAL) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct := r.Header.Get("Content-Type")
+	if !hasMediaType(ct, []string{"application/hal+json", "application/json"}) {
+		return r, newResponseError(ErrDecode, r, fmt.Errorf("expected HAL response but got %s", ct))
+	}
+
+	var links struct {
+		Links HALLinks `json:"_links"`
+	}
+	r, err := (&forJSON{value: &links}).decode(r)
+	if err != nil {
+		return r, err
+	}
+
+	if h.value != nil {
+		if r, err = (&forJSON{value: h.value}).decode(r); err != nil {
+			return r, err
+		}
+	}
+
+	if r.Request != nil {
+		r.Request = r.Request.WithContext(context.WithValue(r.Request.Context(), halLinksKey{}, links.Links))
+	}
+
+	return r, nil
+}
+
+// HALLinksOf returns the links of the HAL resource contained in r, which
+// must have been decoded using ForHAL. It returns nil otherwise.
+func HALLinksOf(r *http.Response) HALLinks {
+	if r == nil || r.Request == nil {
+		return nil
+	}
+	links, _ := r.Request.Context().Value(halLinksKey{}).(HALLinks)
+	return links
+}
+
+// FollowLink sends a GET request for the first link with relation type rel
+// of the HAL resource contained in r, which must have been decoded using
+// ForHAL, using ctx and opts:
+//
+//	res, err := client.Get(ctx, "/orders/42", httpclient.ForHAL(&order))
+//	// ...
+//	_, err = client.FollowLink(ctx, res, "customer", httpclient.ForHAL(&customer))
+//
+// Relative links are resolved against the URL of the request that produced
+// r. ErrNoLink is returned if r contains no such link. Templated links are
+// not supported and cause an error.
+func (c *Client) FollowLink(ctx context.Context, r *http.Response, rel string, opts ...RequestOption) (*http.Response, error) {
+	links := HALLinksOf(r)[rel]
+	if len(links) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoLink, rel)
+	}
+
+	link := links[0]
+	if link.Templated {
+		return nil, fmt.Errorf("hal: link %s is templated: %s", rel, link.Href)
+	}
+
+	u, err := url.Parse(link.Href)
+	if err != nil {
+		return nil, fmt.Errorf("hal: link %s: %w", rel, err)
+	}
+	if r.Request.URL != nil {
+		u = r.Request.URL.ResolveReference(u)
+	}
+
+	return c.Get(ctx, u.String(), opts...)
+}
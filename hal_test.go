@@ -0,0 +1,47 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestHAL(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+		switch r.URL.Path {
+		case "/orders/42":
+			w.Write([]byte(`{"total":30,"_links":{"self":{"href":"/orders/42"},"customer":{"href":"/customers/7"},"items":[{"href":"/items/1"},{"href":"/items/2"}],"find":{"href":"/orders{?id}","templated":true}}}`))
+		case "/customers/7":
+			w.Write([]byte(`{"name":"Alice","_links":{"self":{"href":"/customers/7"}}}`))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	var order struct {
+		Total int `json:"total"`
+	}
+	res, err := client.Get(context.Background(), "/orders/42", httpclient.ForHAL(&order))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, order.Total).Is(Equal(30))
+	ExpectThat(t, httpclient.HALLinksOf(res)["items"]).Is(DeepEqual([]httpclient.HALLink{{Href: "/items/1"}, {Href: "/items/2"}}))
+
+	var customer struct {
+		Name string `json:"name"`
+	}
+	_, err = client.FollowLink(context.Background(), res, "customer", httpclient.ForHAL(&customer))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, customer.Name).Is(Equal("Alice"))
+
+	_, err = client.FollowLink(context.Background(), res, "invoice")
+	ExpectThat(t, err).Is(Error(httpclient.ErrNoLink))
+
+	_, err = client.FollowLink(context.Background(), res, "find")
+	ExpectThat(t, err).Is(NotNil())
+}
@@ -0,0 +1,283 @@
+// Package har implements recording and replaying of HTTP traffic using the
+// HTTP Archive (HAR) 1.2 format. HAR files can be inspected with browser
+// developer tools and are a common format to exchange recorded sessions.
+//
+// Only the subset of the format needed to faithfully represent requests and
+// responses sent by an httpclient.Client is implemented.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// Version is the HAR format version produced by this package.
+const Version = "1.2"
+
+// Redacted is the value recorded in place of redacted header, cookie and
+// query parameter values.
+const Redacted = "REDACTED"
+
+// Read reads a HAR in JSON format from r.
+func Read(r io.Reader) (*HAR, error) {
+	var h HAR
+	if err := json.NewDecoder(r).Decode(&h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// ReadFile reads a HAR in JSON format from the file named path.
+func ReadFile(path string) (*HAR, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Write writes h in JSON format to w.
+func Write(w io.Writer, h *HAR) error {
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// WriteFile writes h in JSON format to the file named path.
+func WriteFile(path string, h *HAR) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := Write(f, h); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// HAR is the root object of a HAR file.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log contains all recorded entries.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator describes the application that created the log.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry describes a single request/response exchange.
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	// Time is the total duration of the exchange in milliseconds.
+	Time     float64  `json:"time"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+	Cache    struct{} `json:"cache"`
+	Timings  Timings  `json:"timings"`
+}
+
+// Request describes a recorded request.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// Response describes a recorded response.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// Cookie describes a cookie sent with a request or received with a
+// response.
+type Cookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+// NameValue is a generic name/value pair used for headers and query
+// parameters.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData describes the body of a request. Bodies which are not valid UTF-8
+// are stored base64 encoded with Encoding set to "base64". As HAR does not
+// define an encoding for request bodies, Encoding is stored using the custom
+// field name "_encoding".
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"_encoding,omitempty"`
+}
+
+// Bytes returns the decoded body bytes.
+func (p PostData) Bytes() ([]byte, error) {
+	return decode(p.Text, p.Encoding)
+}
+
+// Content describes the body of a response. Bodies which are not valid UTF-8
+// are stored base64 encoded with Encoding set to "base64".
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Bytes returns the decoded body bytes.
+func (c Content) Bytes() ([]byte, error) {
+	return decode(c.Text, c.Encoding)
+}
+
+// Timings contains the durations of the exchange's phases in milliseconds.
+// Phases that do not apply are set to -1.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// headers converts h to a list sorted by name, so recordings of the same
+// exchange are identical.
+func headers(h http.Header, redact map[string]struct{}) []NameValue {
+	l := nameValues(h)
+	for i := range l {
+		if _, ok := redact[l[i].Name]; ok {
+			l[i].Value = Redacted
+		}
+	}
+	return l
+}
+
+// queryString converts the query of u to a list sorted by name, so
+// recordings of the same exchange are identical.
+func queryString(u *url.URL) []NameValue {
+	return nameValues(u.Query())
+}
+
+// nameValues converts m to a list of name/value pairs sorted by name. Values
+// of the same name keep their order.
+func nameValues(m map[string][]string) []NameValue {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	l := make([]NameValue, 0, len(m))
+	for _, name := range names {
+		for _, v := range m[name] {
+			l = append(l, NameValue{Name: name, Value: v})
+		}
+	}
+	return l
+}
+
+// cookies converts cs, replacing the values with Redacted if redact is set.
+func cookies(cs []*http.Cookie, redact bool) []Cookie {
+	l := make([]Cookie, 0, len(cs))
+	for _, c := range cs {
+		hc := Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if redact {
+			hc.Value = Redacted
+		}
+		if !c.Expires.IsZero() {
+			e := c.Expires
+			hc.Expires = &e
+		}
+		l = append(l, hc)
+	}
+	return l
+}
+
+func content(body []byte, mimeType string) Content {
+	c := Content{
+		Size:     int64(len(body)),
+		MimeType: mimeType,
+	}
+	c.Text, c.Encoding = encode(body)
+	return c
+}
+
+func postData(body []byte, mimeType string) *PostData {
+	p := PostData{MimeType: mimeType}
+	p.Text, p.Encoding = encode(body)
+	return &p
+}
+
+// encode returns body as text. Bodies which are not valid UTF-8 are base64
+// encoded.
+func encode(body []byte) (text, encoding string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decode(text, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
+
+// millis converts d to fractional milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,329 @@
+package har
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/halimath/httpclient"
+)
+
+// defaultRedactedHeaders lists the headers that are always redacted.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Recorder records all exchanges sent through it as HAR entries. The values of
+// sensitive headers and query parameters as well as passwords contained in
+// URLs are redacted when an entry is recorded, so recorded HARs can be shared
+// safely. A Recorder is safe for concurrent use.
+type Recorder struct {
+	redact      map[string]struct{}
+	redactQuery map[string]struct{}
+	mutex       sync.Mutex
+	entries     []Entry
+}
+
+// RecorderOption customizes a Recorder.
+type RecorderOption func(*Recorder)
+
+// RedactHeaders adds headers whose values are recorded as Redacted.
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie are always
+// redacted; redacting Cookie and Set-Cookie also redacts the values of the
+// recorded cookies.
+func RedactHeaders(names ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, n := range names {
+			r.redact[http.CanonicalHeaderKey(n)] = struct{}{}
+		}
+	}
+}
+
+// RedactQueryParams adds query parameters whose values are recorded as
+// Redacted, i.e. "api_key". Parameter names are matched case-sensitively.
+// Requests replayed from such a recording must be normalized the same way,
+// i.e. using NormalizeRequest.
+func RedactQueryParams(names ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, n := range names {
+			r.redactQuery[n] = struct{}{}
+		}
+	}
+}
+
+// NewRecorder creates a new, empty Recorder customized with opts.
+func NewRecorder(opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		redact:      make(map[string]struct{}, len(defaultRedactedHeaders)),
+		redactQuery: make(map[string]struct{}),
+	}
+
+	for _, h := range defaultRedactedHeaders {
+		r.redact[h] = struct{}{}
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Option returns a ClientOption that wraps the client's transport so that all
+// exchanges are recorded by r. Options replacing the transport must be given
+// before this option to be recorded.
+func (r *Recorder) Option() httpclient.ClientOption {
+	return httpclient.HTTPClientOption(func(c *http.Client) {
+		c.Transport = r.RoundTripper(c.Transport)
+	})
+}
+
+// RoundTripper wraps next with a RoundTripper recording all exchanges. If next
+// is nil, http.DefaultTransport is used.
+//
+// Request and response bodies are buffered completely in order to record
+// them.
+func (r *Recorder) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &recordingTransport{recorder: r, next: next}
+}
+
+// HAR returns a HAR containing all entries recorded so far.
+func (r *Recorder) HAR() *HAR {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+
+	return &HAR{
+		Log: Log{
+			Version: Version,
+			Creator: Creator{Name: "github.com/halimath/httpclient"},
+			Entries: entries,
+		},
+	}
+}
+
+// WriteTo writes the recorded HAR as JSON to w.
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, r.HAR()); err != nil {
+		return 0, err
+	}
+
+	return buf.WriteTo(w)
+}
+
+// WriteFile writes the recorded HAR as JSON to the file named path.
+func (r *Recorder) WriteFile(path string) error {
+	return WriteFile(path, r.HAR())
+}
+
+func (r *Recorder) add(e Entry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = append(r.entries, e)
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tm timer
+	tm.start = time.Now()
+
+	out := req.Clone(httptrace.WithClientTrace(req.Context(), tm.trace()))
+	if req.Body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	res, err := t.next.RoundTrip(out)
+	if err != nil {
+		return res, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+	tm.end = time.Now()
+
+	t.recorder.add(t.recorder.entry(req, reqBody, res, resBody, &tm))
+
+	return res, nil
+}
+
+func (r *Recorder) entry(req *http.Request, reqBody []byte, res *http.Response, resBody []byte, tm *timer) Entry {
+	return Entry{
+		StartedDateTime: tm.start,
+		Time:            millis(tm.end.Sub(tm.start)),
+		Request:         request(req, reqBody, r.redact, r.redactQuery),
+		Response: Response{
+			Status:      res.StatusCode,
+			StatusText:  http.StatusText(res.StatusCode),
+			HTTPVersion: res.Proto,
+			Cookies:     cookies(res.Cookies(), r.redacted("Set-Cookie")),
+			Headers:     headers(res.Header, r.redact),
+			Content:     content(resBody, res.Header.Get("Content-Type")),
+			RedirectURL: res.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    int64(len(resBody)),
+		},
+		Timings: tm.timings(),
+	}
+}
+
+func (r *Recorder) redacted(header string) bool {
+	_, ok := r.redact[header]
+	return ok
+}
+
+// request converts req with the given body to a Request, redacting the
+// values of the headers in redact, the values of the query parameters in
+// redactQuery and any password contained in the URL.
+func request(req *http.Request, body []byte, redact, redactQuery map[string]struct{}) Request {
+	_, redactCookies := redact["Cookie"]
+
+	u := redactURL(req.URL, redactQuery)
+
+	r := Request{
+		Method:      req.Method,
+		URL:         u.Redacted(),
+		HTTPVersion: req.Proto,
+		Cookies:     cookies(req.Cookies(), redactCookies),
+		Headers:     headers(req.Header, redact),
+		QueryString: queryString(u),
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+
+	if r.HTTPVersion == "" {
+		r.HTTPVersion = "HTTP/1.1"
+	}
+
+	if req.Body != nil {
+		r.PostData = postData(body, req.Header.Get("Content-Type"))
+	}
+
+	return r
+}
+
+// redactURL returns a copy of u with the values of the query parameters in
+// redact replaced with Redacted. u is returned unchanged if it contains none
+// of them.
+func redactURL(u *url.URL, redact map[string]struct{}) *url.URL {
+	q := u.Query()
+
+	redacted := false
+	for name, values := range q {
+		if _, ok := redact[name]; ok {
+			for i := range values {
+				values[i] = Redacted
+			}
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return u
+	}
+
+	c := *u
+	c.RawQuery = q.Encode()
+	return &c
+}
+
+// timer captures the points in time of the phases of an exchange.
+type timer struct {
+	mutex                                               sync.Mutex
+	start, end                                          time.Time
+	dnsStart, dnsDone, connectStart, connectDone        time.Time
+	tlsStart, tlsDone, gotConn, wroteRequest, firstByte time.Time
+}
+
+func (t *timer) set(p *time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	*p = time.Now()
+}
+
+func (t *timer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.set(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone) },
+		ConnectStart:         func(string, string) { t.set(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { t.set(&t.connectDone) },
+		TLSHandshakeStart:    func() { t.set(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.set(&t.tlsDone) },
+		GotConn:              func(httptrace.GotConnInfo) { t.set(&t.gotConn) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.set(&t.firstByte) },
+	}
+}
+
+func (t *timer) timings() Timings {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	span := func(from, to time.Time) float64 {
+		if from.IsZero() || to.IsZero() {
+			return -1
+		}
+		return millis(to.Sub(from))
+	}
+
+	timings := Timings{
+		Blocked: -1,
+		DNS:     span(t.dnsStart, t.dnsDone),
+		Connect: span(t.connectStart, t.connectDone),
+		SSL:     span(t.tlsStart, t.tlsDone),
+		Send:    span(t.gotConn, t.wroteRequest),
+		Wait:    span(t.wroteRequest, t.firstByte),
+		Receive: span(t.firstByte, t.end),
+	}
+
+	// Connect includes the TLS handshake in HAR.
+	if timings.Connect >= 0 && timings.SSL >= 0 {
+		timings.Connect += timings.SSL
+	}
+
+	// All of send, wait and receive are required to be non-negative.
+	if timings.Send < 0 {
+		timings.Send = 0
+	}
+	if timings.Wait < 0 {
+		timings.Wait = 0
+	}
+	if timings.Receive < 0 {
+		timings.Receive = 0
+	}
+
+	return timings
+}
@@ -0,0 +1,143 @@
+package har_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/har"
+)
+
+func TestRecorder(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello, world"))
+	}))
+	defer testServer.Close()
+
+	rec := har.NewRecorder()
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		rec.Option(),
+	)
+
+	_, err := client.Post(context.Background(), "/greet?lang=en", httpclient.WithJSON("hi"))
+	ExpectThat(t, err).Is(NoError())
+
+	var buf bytes.Buffer
+	_, err = rec.WriteTo(&buf)
+	ExpectThat(t, err).Is(NoError())
+
+	var got har.HAR
+	ExpectThat(t, json.Unmarshal(buf.Bytes(), &got)).Is(NoError())
+
+	ExpectThat(t, got.Log.Version).Is(Equal("1.2"))
+	ExpectThat(t, got.Log.Entries).Is(Len(1))
+
+	e := got.Log.Entries[0]
+	ExpectThat(t, e.Request.Method).Is(Equal(http.MethodPost))
+	ExpectThat(t, e.Request.URL).Is(Equal(testServer.URL + "/greet?lang=en"))
+	ExpectThat(t, e.Request.QueryString).Is(DeepEqual([]har.NameValue{{Name: "lang", Value: "en"}}))
+	ExpectThat(t, e.Request.PostData).Is(DeepEqual(&har.PostData{MimeType: "application/json", Text: `"hi"`}))
+	ExpectThat(t, e.Response.Status).Is(Equal(http.StatusOK))
+	ExpectThat(t, e.Response.Content.Text).Is(Equal("hello, world"))
+	ExpectThat(t, e.Response.Content.MimeType).Is(Equal("text/plain"))
+	ExpectThat(t, e.Timings.Connect >= 0).Is(Equal(true))
+}
+
+func TestRecorder_redactionAndBinaryBodies(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		w.Header().Set("X-Trace", "abc")
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	}))
+	defer testServer.Close()
+
+	rec := har.NewRecorder(har.RedactHeaders("x-api-key"))
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		rec.Option(),
+	)
+
+	binary := []byte{0xff, 0x00, 0xfe}
+
+	_, err := client.Post(context.Background(), "/upload?b=2&a=1&a=0",
+		httpclient.WithRequestHeader("Authorization", "Bearer secret"),
+		httpclient.WithRequestHeader("X-Api-Key", "secret"),
+		httpclient.WithBody(bytes.NewReader(binary), "application/octet-stream", int64(len(binary))),
+	)
+	ExpectThat(t, err).Is(NoError())
+
+	e := rec.HAR().Log.Entries[0]
+
+	ExpectThat(t, e.Request.QueryString).Is(DeepEqual([]har.NameValue{
+		{Name: "a", Value: "1"},
+		{Name: "a", Value: "0"},
+		{Name: "b", Value: "2"},
+	}))
+
+	names := make([]string, 0, len(e.Request.Headers))
+	for _, h := range e.Request.Headers {
+		names = append(names, h.Name)
+		if h.Name == "Authorization" || h.Name == "X-Api-Key" {
+			ExpectThat(t, h.Value).Is(Equal(har.Redacted))
+		}
+	}
+	ExpectThat(t, sort.StringsAreSorted(names)).Is(Equal(true))
+
+	for _, h := range e.Response.Headers {
+		if h.Name == "Set-Cookie" {
+			ExpectThat(t, h.Value).Is(Equal(har.Redacted))
+		}
+	}
+	ExpectThat(t, e.Response.Cookies[0].Value).Is(Equal(har.Redacted))
+
+	ExpectThat(t, e.Request.PostData.Encoding).Is(Equal("base64"))
+	body, err := e.Request.PostData.Bytes()
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, body).Is(DeepEqual(binary))
+
+	replaying := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		har.NewReplayer(rec.HAR()).Option(),
+	)
+
+	_, err = replaying.Post(context.Background(), "/upload?b=2&a=1&a=0",
+		httpclient.WithBody(bytes.NewReader(binary), "application/octet-stream", int64(len(binary))),
+	)
+	ExpectThat(t, err).Is(NoError())
+}
+
+func TestRecorder_redactURL(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	rec := har.NewRecorder(har.RedactQueryParams("api_key"))
+	client := httpclient.New(rec.Option())
+
+	u, err := url.Parse(testServer.URL + "/items?api_key=secret&page=2")
+	ExpectThat(t, err).Is(NoError())
+	u.User = url.UserPassword("jane", "secret")
+
+	_, err = client.Get(context.Background(), u.String())
+	ExpectThat(t, err).Is(NoError())
+
+	e := rec.HAR().Log.Entries[0]
+	ExpectThat(t, strings.Contains(e.Request.URL, "secret")).Is(Equal(false))
+	ExpectThat(t, e.Request.QueryString).Is(DeepEqual([]har.NameValue{
+		{Name: "api_key", Value: har.Redacted},
+		{Name: "page", Value: "2"},
+	}))
+}
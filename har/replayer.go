@@ -0,0 +1,163 @@
+package har
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/halimath/httpclient"
+)
+
+// ErrNoMatch is returned by a Replayer's RoundTrip method when no recorded
+// entry matches the request.
+var ErrNoMatch = errors.New("no matching HAR entry")
+
+// Replayer implements a http.RoundTripper that serves responses from the
+// entries of a HAR instead of sending requests over the network. A request
+// matches an entry if method, URL and body are equal. Multiple entries
+// matching the same request are served in recorded order; once all of them
+// have been served the last one is repeated.
+//
+// A Replayer is safe for concurrent use.
+type Replayer struct {
+	normalizers []func(*Request)
+	mutex       sync.Mutex
+	entries     []Entry
+	served      []bool
+}
+
+// ReplayerOption customizes a Replayer.
+type ReplayerOption func(*Replayer)
+
+// NormalizeRequest adds a function that is applied to each incoming request
+// before it is matched against the recorded entries. Use it to apply the same
+// transformations that have been applied to the entries after recording, i.e.
+// to remove secrets from URLs or bodies.
+func NormalizeRequest(f func(*Request)) ReplayerOption {
+	return func(r *Replayer) {
+		r.normalizers = append(r.normalizers, f)
+	}
+}
+
+// NewReplayer creates a Replayer serving the entries of h customized with
+// opts.
+func NewReplayer(h *HAR, opts ...ReplayerOption) *Replayer {
+	r := &Replayer{
+		entries: h.Log.Entries,
+		served:  make([]bool, len(h.Log.Entries)),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Option returns a ClientOption using r as the client's transport.
+func (r *Replayer) Option() httpclient.ClientOption {
+	return httpclient.WithTransport(r)
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	url := req.URL.Redacted()
+
+	if len(r.normalizers) > 0 {
+		in := request(req, body, nil, nil)
+		for _, n := range r.normalizers {
+			n(&in)
+		}
+
+		url = in.URL
+		body = nil
+		if in.PostData != nil {
+			var err error
+			if body, err = in.PostData.Bytes(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	e, ok := r.match(req.Method, url, body)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, req.Method, req.URL)
+	}
+
+	return response(req, e)
+}
+
+func (r *Replayer) match(method, url string, body []byte) (Entry, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	last := -1
+	for i, e := range r.entries {
+		if e.Request.Method != method || e.Request.URL != url {
+			continue
+		}
+
+		var recorded []byte
+		if e.Request.PostData != nil {
+			var err error
+			if recorded, err = e.Request.PostData.Bytes(); err != nil {
+				continue
+			}
+		}
+		if !bytes.Equal(recorded, body) {
+			continue
+		}
+
+		if !r.served[i] {
+			r.served[i] = true
+			return e, true
+		}
+		last = i
+	}
+
+	if last < 0 {
+		return Entry{}, false
+	}
+
+	return r.entries[last], true
+}
+
+func response(req *http.Request, e Entry) (*http.Response, error) {
+	body, err := e.Response.Content.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &http.Response{
+		Status:        strconv.Itoa(e.Response.Status) + " " + e.Response.StatusText,
+		StatusCode:    e.Response.Status,
+		Proto:         e.Response.HTTPVersion,
+		Header:        make(http.Header, len(e.Response.Headers)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+
+	if major, minor, ok := http.ParseHTTPVersion(res.Proto); ok {
+		res.ProtoMajor, res.ProtoMinor = major, minor
+	}
+
+	for _, h := range e.Response.Headers {
+		res.Header.Add(h.Name, h.Value)
+	}
+
+	return res, nil
+}
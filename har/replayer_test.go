@@ -0,0 +1,71 @@
+package har_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/har"
+)
+
+func TestReplayer(t *testing.T) {
+	h := &har.HAR{
+		Log: har.Log{
+			Entries: []har.Entry{
+				{
+					Request: har.Request{Method: http.MethodGet, URL: "http://example.com/items"},
+					Response: har.Response{
+						Status:      http.StatusOK,
+						HTTPVersion: "HTTP/1.1",
+						Headers:     []har.NameValue{{Name: "Content-Type", Value: "text/plain"}},
+						Content:     har.Content{Text: "first"},
+					},
+				},
+				{
+					Request: har.Request{Method: http.MethodGet, URL: "http://example.com/items"},
+					Response: har.Response{
+						Status:  http.StatusOK,
+						Content: har.Content{Text: "c2Vjb25k", Encoding: "base64"},
+					},
+				},
+				{
+					Request: har.Request{
+						Method:   http.MethodPost,
+						URL:      "http://example.com/items",
+						PostData: &har.PostData{MimeType: "application/json", Text: `"new"`},
+					},
+					Response: har.Response{Status: http.StatusCreated},
+				},
+			},
+		},
+	}
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix("http://example.com"),
+		har.NewReplayer(h).Option(),
+	)
+
+	ctx := context.Background()
+
+	for _, want := range []string{"first", "second", "second"} {
+		var body string
+		res, err := client.Get(ctx, "/items", httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			b, err := io.ReadAll(r.Body)
+			body = string(b)
+			return r, err
+		}))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusOK))
+		ExpectThat(t, body).Is(Equal(want))
+	}
+
+	res, err := client.Post(ctx, "/items", httpclient.WithJSON("new"))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusCreated))
+
+	_, err = client.Post(ctx, "/items", httpclient.WithJSON("other"))
+	ExpectThat(t, err).Is(Error(har.ErrNoMatch))
+}
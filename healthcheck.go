@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthCheck configures the probes sent by LoadBalancer.CheckHealth.
+type HealthCheck struct {
+	// Path is appended to the URL of each endpoint to build the URL the
+	// probes are sent to, i.e. "/healthz".
+	Path string
+
+	// Interval is the time between two probes of an endpoint. It defaults to
+	// 10 seconds.
+	Interval time.Duration
+
+	// Timeout limits the duration of each probe. It defaults to Interval.
+	Timeout time.Duration
+
+	// UnhealthyThreshold is the number of consecutive failed probes after
+	// which an endpoint is ejected. It defaults to 3.
+	UnhealthyThreshold int
+
+	// HealthyThreshold is the number of consecutive successful probes after
+	// which an ejected endpoint is readmitted. It defaults to 2.
+	HealthyThreshold int
+
+	// Transport is used to send the probes. It defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// CheckHealth probes the endpoints of lb in the background until ctx is done.
+// Endpoints added to lb, i.e. by resolving DNS SRV records, are probed from
+// the next round on.
+// Probes are GET requests to the endpoint's URL with hc.Path appended. They
+// succeed if they are answered with a 2xx status code. Endpoints failing
+// hc.UnhealthyThreshold probes in a row are ejected and receive no requests
+// until they pass hc.HealthyThreshold probes in a row. All endpoints are
+// considered healthy when the checks start.
+//
+// The interval between probes is measured using the Clock stored in ctx, so
+// tests can drive the checks with a fake clock. CheckHealth must not be
+// called again for lb before ctx of the previous call is done.
+func (lb *LoadBalancer) CheckHealth(ctx context.Context, hc HealthCheck) {
+	if hc.Interval <= 0 {
+		hc.Interval = 10 * time.Second
+	}
+	if hc.Timeout <= 0 {
+		hc.Timeout = hc.Interval
+	}
+	if hc.UnhealthyThreshold <= 0 {
+		hc.UnhealthyThreshold = 3
+	}
+	if hc.HealthyThreshold <= 0 {
+		hc.HealthyThreshold = 2
+	}
+	if hc.Transport == nil {
+		hc.Transport = http.DefaultTransport
+	}
+
+	checker := &healthChecker{
+		hc:     hc,
+		client: &http.Client{Transport: hc.Transport},
+		clock:  ClockFromContext(ctx),
+		streak: make(map[*Endpoint]int),
+	}
+
+	go checker.run(ctx, lb)
+}
+
+type healthChecker struct {
+	hc     HealthCheck
+	client *http.Client
+	clock  Clock
+
+	// streak counts the consecutive probe results contradicting an
+	// endpoint's current state.
+	mutex  sync.Mutex
+	streak map[*Endpoint]int
+}
+
+func (c *healthChecker) run(ctx context.Context, lb *LoadBalancer) {
+	for {
+		// Errors resolving the endpoints are ignored; the endpoints are
+		// resolved again in the next round.
+		endpoints, _ := lb.current(ctx)
+
+		var wg sync.WaitGroup
+		for _, e := range endpoints {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.record(e, c.probe(ctx, e))
+			}()
+		}
+		wg.Wait()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.clock.After(c.hc.Interval):
+		}
+	}
+}
+
+// probe sends a single probe to e and reports whether it succeeded.
+func (c *healthChecker) probe(ctx context.Context, e *Endpoint) bool {
+	ctx, cancel := context.WithTimeout(ctx, c.hc.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.URL+c.hc.Path, nil)
+	if err != nil {
+		return false
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return false
+	}
+	res.Body.Close()
+
+	return res.StatusCode >= 200 && res.StatusCode < 300
+}
+
+// record updates the state of e based on the result of a probe.
+func (c *healthChecker) record(e *Endpoint, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if ok == e.Healthy() {
+		c.streak[e] = 0
+		return
+	}
+
+	c.streak[e]++
+
+	threshold := c.hc.UnhealthyThreshold
+	if ok {
+		threshold = c.hc.HealthyThreshold
+	}
+
+	if c.streak[e] >= threshold {
+		e.ejected.Store(!ok)
+		c.streak[e] = 0
+	}
+}
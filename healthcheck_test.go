@@ -0,0 +1,70 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestLoadBalancer_CheckHealth(t *testing.T) {
+	var failing atomic.Bool
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" && failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer flaky.Close()
+
+	flakyEndpoint := &httpclient.Endpoint{URL: flaky.URL}
+	lb := httpclient.NewLoadBalancer(httpclient.RoundRobin(), flakyEndpoint)
+
+	clock := httpclienttest.NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(httpclient.ContextWithClock(context.Background(), clock))
+	defer cancel()
+
+	// probed waits for the checker to complete a round of probes, after
+	// which it waits for the clock to trigger the next round.
+	probed := func() {
+		for clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	failing.Store(true)
+	lb.CheckHealth(ctx, httpclient.HealthCheck{
+		Path:               "/healthz",
+		Interval:           time.Second,
+		UnhealthyThreshold: 2,
+		HealthyThreshold:   2,
+	})
+
+	probed()
+	ExpectThat(t, flakyEndpoint.Healthy()).Is(Equal(true))
+	clock.Advance(time.Second)
+	probed()
+	ExpectThat(t, flakyEndpoint.Healthy()).Is(Equal(false))
+
+	client := httpclient.New(httpclient.WithLoadBalancer(lb))
+	_, err := client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(Error(httpclient.ErrNoEndpoint))
+
+	failing.Store(false)
+	clock.Advance(time.Second)
+	probed()
+	ExpectThat(t, flakyEndpoint.Healthy()).Is(Equal(false))
+	clock.Advance(time.Second)
+	probed()
+	ExpectThat(t, flakyEndpoint.Healthy()).Is(Equal(true))
+
+	_, err = client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+}
@@ -0,0 +1,122 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// WithHostOptions creates a ClientOption applying opts only to requests sent
+// to a host matching hostPattern. This allows a single Client to use
+// different authentication, logging or validation for each of the upstreams
+// it talks to, i.e.
+//
+//	httpclient.New(
+//		httpclient.WithHostOptions("api.example.com", httpclient.WithRequestHeader("Authorization", apiToken)),
+//		httpclient.WithHostOptions("*.internal.example.com", httpclient.ExpectedStatusCode(http.StatusOK)),
+//	)
+//
+// hostPattern is matched against the host name of the request's URL - not
+// including the port - using path.Match ignoring case, so "*.example.com"
+// matches all subdomains of example.com. The host of requests with relative
+// URLs is only known once options like WithURLPrefix have been applied, so
+// these must be given before WithHostOptions.
+//
+// opts may contain request interceptors, response interceptors and options
+// observing requests as they are sent, such as WithLogging. Options
+// configuring the http.Client or the Client itself, such as WithTransport or
+// WithEvents, apply to all hosts and are reported as invalid when used with
+// WithHostOptions, as is a malformed hostPattern. Response interceptors keep
+// their Phase and are matched against the host of the request that produced
+// the response. Likewise, request interceptors keep their RequestPhase.
+func WithHostOptions(hostPattern string, opts ...ClientOption) ClientOption {
+	hostPattern = strings.ToLower(hostPattern)
+	if _, err := path.Match(hostPattern, ""); err != nil {
+		return invalidOption{fmt.Errorf("invalid host pattern %q: %w", hostPattern, err)}
+	}
+
+	for _, opt := range opts {
+		if err := validate(opt); err != nil {
+			return invalidOption{err}
+		}
+
+		_, isWrapper := opt.(roundTripWrapper)
+		_, isRequestInterceptor := opt.(RequestInterceptor)
+		_, isResponseInterceptor := opt.(ResponseInterceptor)
+		if !isWrapper && !isRequestInterceptor && !isResponseInterceptor {
+			return invalidOption{fmt.Errorf("unexpected host option: %v", opt)}
+		}
+	}
+
+	return clientConfigOption(func(c *Client) {
+		m := hostMatcher(hostPattern)
+
+		for _, opt := range opts {
+			if w, ok := opt.(roundTripWrapper); ok {
+				c.wrappers = append(c.wrappers, &hostRoundTripWrapper{m, w})
+			}
+
+			if i, ok := opt.(RequestInterceptor); ok {
+				c.reqInterceptors = append(c.reqInterceptors, &hostRequestInterceptor{m, i, requestPhaseOf(i)})
+			}
+
+			if i, ok := opt.(ResponseInterceptor); ok {
+				c.resInterceptors = append(c.resInterceptors, &hostResponseInterceptor{m, i, phaseOf(i)})
+			}
+		}
+	})
+}
+
+// hostMatcher is a host pattern as accepted by WithHostOptions.
+type hostMatcher string
+
+func (m hostMatcher) matches(r *http.Request) bool {
+	if r == nil || r.URL == nil {
+		return false
+	}
+	ok, _ := path.Match(string(m), strings.ToLower(r.URL.Hostname()))
+	return ok
+}
+
+type hostRequestInterceptor struct {
+	hostMatcher
+	i     RequestInterceptor
+	phase RequestPhase
+}
+
+func (h *hostRequestInterceptor) RequestPhase() RequestPhase { return h.phase }
+
+func (h *hostRequestInterceptor) InterceptRequest(r *http.Request) (*http.Request, error) {
+	if !h.matches(r) {
+		return r, nil
+	}
+	return h.i.InterceptRequest(r)
+}
+
+type hostResponseInterceptor struct {
+	hostMatcher
+	i     ResponseInterceptor
+	phase Phase
+}
+
+func (h *hostResponseInterceptor) Phase() Phase { return h.phase }
+
+func (h *hostResponseInterceptor) InterceptResponse(r *http.Response) (*http.Response, error) {
+	if !h.matches(r.Request) {
+		return r, nil
+	}
+	return h.i.InterceptResponse(r)
+}
+
+type hostRoundTripWrapper struct {
+	hostMatcher
+	w roundTripWrapper
+}
+
+func (h *hostRoundTripWrapper) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if !h.matches(r) {
+		return next(r)
+	}
+	return h.w.wrapRoundTrip(r, next)
+}
@@ -0,0 +1,59 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestWithHostOptions(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Token", r.Header.Get("Authorization"))
+		if strings.EqualFold(r.Host, "legacy.example.com") {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	client := httpclient.New(
+		httpclient.WithTransport(httpclienttest.HandlerTransport(handler)),
+		httpclient.WithHostOptions("api.example.com", httpclient.WithRequestHeader("Authorization", "api-token")),
+		httpclient.WithHostOptions("*.example.com", httpclient.ExpectedStatusCode(http.StatusOK)),
+	)
+
+	res, err := client.Get(context.Background(), "http://api.example.com/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.Header.Get("X-Token")).Is(Equal("api-token"))
+
+	res, err = client.Get(context.Background(), "http://other.test/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.Header.Get("X-Token")).Is(Equal(""))
+
+	_, err = client.Get(context.Background(), "http://LEGACY.example.com/")
+	ExpectThat(t, err).Is(NotNil())
+}
+
+func TestWithHostOptions_requestPhase(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Signature", r.Header.Get("X-Signature"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := httpclient.New(
+		httpclient.WithTransport(httpclienttest.HandlerTransport(handler)),
+		httpclient.WithHostOptions("api.example.com", httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, func(r *http.Request) (*http.Request, error) {
+			r.Header.Set("X-Signature", "signed:"+r.Header.Get("X-Data"))
+			return r, nil
+		})),
+	)
+
+	res, err := client.Get(context.Background(), "http://api.example.com/", httpclient.WithRequestHeader("X-Data", "payload"))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.Header.Get("X-Signature")).Is(Equal("signed:payload"))
+}
@@ -0,0 +1,14 @@
+module github.com/halimath/httpclient/htmlhttpclient
+
+go 1.23.0
+
+require (
+	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7
+	github.com/halimath/httpclient v0.1.0
+	golang.org/x/net v0.38.0
+)
+
+require (
+	github.com/deckarep/golang-set/v2 v2.1.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
+)
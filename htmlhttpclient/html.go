@@ -0,0 +1,95 @@
+// Package htmlhttpclient integrates httpclient with golang.org/x/net/html to
+// parse HTML responses, i.e. for scraping or checking links. It is provided
+// as a separate module so that applications not parsing HTML don't depend on
+// it.
+package htmlhttpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/halimath/httpclient"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// ContentType is the content type of HTML documents.
+const ContentType = "text/html"
+
+// Decoder is an httpclient.BodyDecoder parsing HTML documents into a
+// **html.Node. Register it using httpclient.WithDecoder to parse HTML
+// responses using httpclient.ForBody:
+//
+//	c := httpclient.New(httpclient.WithDecoder(htmlhttpclient.ContentType, htmlhttpclient.Decoder))
+//
+//	var doc *html.Node
+//	_, err := c.Get(ctx, "/", httpclient.ForBody(&doc))
+//
+// The body is converted to UTF-8 using the charset given in the Content-Type
+// header or declared by the document itself. The body remains readable after
+// it has been parsed.
+var Decoder httpclient.BodyDecoder = httpclient.BodyDecoderFunc(decode)
+
+func decode(r *http.Response, v any) error {
+	doc, ok := v.(**html.Node)
+	if !ok {
+		return fmt.Errorf("cannot decode HTML into %T", v)
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(b), r.Body}
+
+	utf8, err := charset.NewReader(bytes.NewReader(b), r.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+
+	*doc, err = html.Parse(utf8)
+	return err
+}
+
+// ForHTML creates an httpclient.Option that parses the response body into
+// doc. It adds an Accept header requesting ContentType and fails with an
+// *httpclient.Error of kind httpclient.ErrDecode if the response has a
+// content type other than text/html or application/xhtml+xml or if the body
+// can't be parsed. See Decoder.
+func ForHTML(doc **html.Node) httpclient.Option {
+	return &forHTML{
+		RequestInterceptorOption: httpclient.WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+			r.Header.Add("Accept", ContentType)
+			return r, nil
+		}),
+		doc: doc,
+	}
+}
+
+// forHTML embeds the RequestInterceptorOption setting the Accept header,
+// which makes it an httpclient.Option, and adds the ResponseInterceptor
+// parsing the body.
+type forHTML struct {
+	httpclient.RequestInterceptorOption
+	doc **html.Node
+}
+
+func (h *forHTML) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct != ContentType && ct != "application/xhtml+xml" {
+		return r, httpclient.DecodeError(r, fmt.Errorf("expected HTML response but got %s", r.Header.Get("Content-Type")))
+	}
+
+	if err := decode(r, h.doc); err != nil {
+		return r, httpclient.DecodeError(r, err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,80 @@
+package htmlhttpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/htmlhttpclient"
+	"golang.org/x/net/html"
+)
+
+// links returns the href attributes of all a elements below n.
+func links(n *html.Node) []string {
+	var hrefs []string
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && d.Data == "a" {
+			for _, a := range d.Attr {
+				if a.Key == "href" {
+					hrefs = append(hrefs, a.Val)
+				}
+			}
+		}
+	}
+	return hrefs
+}
+
+func TestForHTML(t *testing.T) {
+	var accept string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+
+		switch r.URL.Path {
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+			w.Write([]byte("<html><body><a href=\"/caf\xe9\">Caf\xe9</a></body></html>"))
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><body><a href="/a">A</a><p><a href="/b">B</a></p></body></html>`))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("utf8", func(t *testing.T) {
+		var doc *html.Node
+		_, err := client.Get(context.Background(), "/", htmlhttpclient.ForHTML(&doc))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, accept).Is(Equal(htmlhttpclient.ContentType))
+		ExpectThat(t, links(doc)).Is(DeepEqual([]string{"/a", "/b"}))
+	})
+
+	t.Run("charset", func(t *testing.T) {
+		var doc *html.Node
+		_, err := client.Get(context.Background(), "/latin1", htmlhttpclient.ForHTML(&doc))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, links(doc)).Is(DeepEqual([]string{"/café"}))
+	})
+
+	t.Run("contentType", func(t *testing.T) {
+		var doc *html.Node
+		_, err := client.Get(context.Background(), "/json", htmlhttpclient.ForHTML(&doc))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+
+	t.Run("decoder", func(t *testing.T) {
+		client := client.With(httpclient.WithDecoder(htmlhttpclient.ContentType, htmlhttpclient.Decoder))
+
+		var doc *html.Node
+		_, err := client.Get(context.Background(), "/", httpclient.ForBody(&doc))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, links(doc)).Is(DeepEqual([]string{"/a", "/b"}))
+	})
+}
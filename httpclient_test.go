@@ -54,7 +54,7 @@ func TestPackage(t *testing.T) {
 				"Accept":          {"application/json"},
 				"Accept-Encoding": {"gzip"},
 				"Host":            {strings.Replace(testServer.URL, "http://", "", -1)},
-				"User-Agent":      {"Go-http-client/1.1"},
+				"User-Agent":      {httpclient.DefaultUserAgent},
 			},
 			URL: testServer.URL + "/get",
 		}))
@@ -75,7 +75,7 @@ func TestPackage(t *testing.T) {
 				"Accept":          {"application/json"},
 				"Accept-Encoding": {"gzip"},
 				"Host":            {strings.Replace(testServer.URL, "http://", "", -1)},
-				"User-Agent":      {"Go-http-client/1.1"},
+				"User-Agent":      {httpclient.DefaultUserAgent},
 				"Content-Type":    {"application/json"},
 				"Content-Length":  {"14"},
 			},
@@ -94,3 +94,70 @@ type httpbinMethodResponse struct {
 	// "json": null,
 	URL string `json:"url"`
 }
+
+func TestClient_With(t *testing.T) {
+	var received http.Header
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	base := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRequestHeader("X-Resource", "base"),
+	)
+	users := base.With(
+		httpclient.WithRequestHeader("X-Resource", "users"),
+		httpclient.WithDefaultHeader("X-Version", "2"),
+	)
+
+	_, err := users.Get(context.Background(), "/users")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("X-Resource")).Is(Equal("users"))
+	ExpectThat(t, received.Get("X-Version")).Is(Equal("2"))
+
+	_, err = base.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("X-Resource")).Is(Equal("base"))
+	ExpectThat(t, received.Get("X-Version")).Is(Equal(""))
+}
+
+func TestNewE(t *testing.T) {
+	_, err := httpclient.NewE(httpclient.WithURLPrefix("http://example.com"))
+	ExpectThat(t, err).Is(NoError())
+
+	_, err = httpclient.NewE(httpclient.WithURLPrefix("example.com/api"))
+	ExpectThat(t, err).Is(NotNil())
+
+	_, err = httpclient.NewE(httpclient.WithHostOptions("[", httpclient.WithRequestHeader("X-Test", "1")))
+	ExpectThat(t, err).Is(NotNil())
+
+	_, err = httpclient.NewE(httpclient.WithHostOptions("example.com", httpclient.WithEvents(1)))
+	ExpectThat(t, err).Is(NotNil())
+}
+
+func TestClient_verbs(t *testing.T) {
+	var method string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	verbs := map[string]func(context.Context, string, ...httpclient.RequestOption) (*http.Response, error){
+		http.MethodPut:     client.Put,
+		http.MethodPatch:   client.Patch,
+		http.MethodDelete:  client.Delete,
+		http.MethodHead:    client.Head,
+		http.MethodOptions: client.Options,
+	}
+
+	for want, send := range verbs {
+		_, err := send(context.Background(), "/")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, method).Is(Equal(want))
+	}
+}
@@ -0,0 +1,49 @@
+package httpclienttest_test
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestFakeClock(t *testing.T) {
+	mock := httpclienttest.NewMockTransport()
+	mock.On(httpclienttest.MatchPath("/slow")).Respond(http.StatusOK, "")
+
+	clock := httpclienttest.NewFakeClock(time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC))
+
+	client := httpclient.New(
+		httpclient.WithTransport(mock),
+		httpclient.WithFaultInjection(httpclient.FaultInjectionConfig{
+			Latency:            time.Hour,
+			LatencyProbability: 1,
+			Rand:               func() float64 { return 0 },
+		}),
+		httpclient.WithClock(clock),
+		httpclient.WithEvents(2),
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Get(context.Background(), "http://example.com/slow")
+		done <- err
+	}()
+
+	for clock.Waiters() == 0 {
+		runtime.Gosched()
+	}
+	clock.Advance(time.Hour)
+
+	ExpectThat(t, <-done).Is(NoError())
+
+	<-client.Events()
+	ev := <-client.Events()
+	ExpectThat(t, ev.Duration).Is(Equal(time.Hour))
+	ExpectThat(t, ev.Time).Is(Equal(time.Date(2022, 9, 1, 13, 0, 0, 0, time.UTC)))
+}
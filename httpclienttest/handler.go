@@ -0,0 +1,58 @@
+package httpclienttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/halimath/httpclient"
+)
+
+// HandlerBaseURL is the URL prefix applied by NewClient to requests with a
+// relative URL.
+const HandlerBaseURL = "http://handler.test"
+
+// NewClient creates a Client sending all requests directly to h without any
+// network involved. Requests with a relative URL are prefixed with
+// HandlerBaseURL. opts are applied after the transport has been configured,
+// so they may wrap it, i.e. to record exchanges.
+func NewClient(h http.Handler, opts ...httpclient.ClientOption) *httpclient.Client {
+	return httpclient.New(append([]httpclient.ClientOption{
+		httpclient.WithTransport(HandlerTransport(h)),
+		httpclient.WithURLPrefix(HandlerBaseURL),
+	}, opts...)...)
+}
+
+// HandlerTransport creates a http.RoundTripper serving all requests by
+// invoking h in-memory. The request passed to h looks like a request received
+// by a server: RequestURI, Host and RemoteAddr are set and the body is never
+// nil. The response is buffered completely before it is returned, so h must
+// not rely on streaming.
+func HandlerTransport(h http.Handler) http.RoundTripper {
+	return handlerTransport{h}
+}
+
+type handlerTransport struct {
+	h http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	in := req.Clone(req.Context())
+	in.RequestURI = req.URL.RequestURI()
+	in.RemoteAddr = "192.0.2.1:1234"
+	if in.Host == "" {
+		in.Host = req.URL.Host
+	}
+	if in.Body == nil {
+		in.Body = http.NoBody
+	}
+	if in.Proto == "" {
+		in.Proto, in.ProtoMajor, in.ProtoMinor = "HTTP/1.1", 1, 1
+	}
+
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, in)
+
+	res := rec.Result()
+	res.Request = req
+	return res, nil
+}
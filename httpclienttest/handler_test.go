@@ -0,0 +1,43 @@
+package httpclienttest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestNewClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		var u map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"name": u["name"], "host": r.Host, "uri": r.RequestURI})
+	})
+
+	client := httpclienttest.NewClient(mux, httpclient.ExpectedStatusCode(http.StatusCreated))
+
+	var got map[string]string
+	_, err := client.Post(context.Background(), "/users?notify=true",
+		httpclient.WithJSON(map[string]string{"name": "gopher"}),
+		httpclient.ForJSON(&got),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, got).Is(DeepEqual(map[string]string{
+		"name": "gopher",
+		"host": "handler.test",
+		"uri":  "/users?notify=true",
+	}))
+
+	_, err = client.Get(context.Background(), "/users")
+	ExpectThat(t, err).Is(NotNil())
+}
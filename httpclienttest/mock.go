@@ -0,0 +1,292 @@
+// Package httpclienttest provides utilities for testing code built on
+// httpclient.
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ErrUnexpectedRequest is returned by MockTransport when a request matches
+// none of the registered expectations.
+var ErrUnexpectedRequest = errors.New("unexpected request")
+
+// Matcher defines the interface for types matching requests.
+type Matcher interface {
+	// Match reports whether r matches.
+	Match(r *http.Request) bool
+
+	// String describes the matcher for diagnostic messages.
+	String() string
+}
+
+type matcherFunc struct {
+	desc  string
+	match func(*http.Request) bool
+}
+
+func (m matcherFunc) Match(r *http.Request) bool { return m.match(r) }
+func (m matcherFunc) String() string             { return m.desc }
+
+// MatcherFunc creates a Matcher from match using desc as its description.
+func MatcherFunc(desc string, match func(*http.Request) bool) Matcher {
+	return matcherFunc{desc: desc, match: match}
+}
+
+// MatchMethod matches requests using method.
+func MatchMethod(method string) Matcher {
+	return MatcherFunc("method "+method, func(r *http.Request) bool {
+		return r.Method == method
+	})
+}
+
+// MatchPath matches requests whose URL path matches pattern using the syntax
+// of path.Match.
+func MatchPath(pattern string) Matcher {
+	return MatcherFunc("path "+pattern, func(r *http.Request) bool {
+		ok, _ := path.Match(pattern, r.URL.Path)
+		return ok
+	})
+}
+
+// MatchURL matches requests whose full URL matches the regular expression
+// pattern. It panics if pattern is not a valid regular expression.
+func MatchURL(pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return MatcherFunc("url "+pattern, func(r *http.Request) bool {
+		return re.MatchString(r.URL.String())
+	})
+}
+
+// MatchHeader matches requests carrying header with value.
+func MatchHeader(header, value string) Matcher {
+	return MatcherFunc(fmt.Sprintf("header %s: %s", header, value), func(r *http.Request) bool {
+		for _, v := range r.Header.Values(header) {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// MatchJSONBody matches requests whose body can be unmarshaled into a T for
+// which pred returns true.
+func MatchJSONBody[T any](pred func(T) bool) Matcher {
+	return MatcherFunc(fmt.Sprintf("JSON body %T", *new(T)), func(r *http.Request) bool {
+		if r.Body == nil {
+			return false
+		}
+
+		var v T
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			return false
+		}
+		return pred(v)
+	})
+}
+
+// Expectation describes an expected request and the response to send for
+// it.
+type Expectation struct {
+	matchers []Matcher
+	header   http.Header
+	handler  http.Handler
+	err      error
+	times    int
+	calls    int
+}
+
+// Respond sets the response to be sent to a response with status and body.
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	return e.RespondWith(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+}
+
+// RespondJSON sets the response to be sent to a response with status and v
+// marshaled to JSON as body. It panics if v cannot be marshaled.
+func (e *Expectation) RespondJSON(status int, v any) *Expectation {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return e.WithResponseHeader("Content-Type", "application/json").Respond(status, string(b))
+}
+
+// RespondWith sets h to produce the response for matching requests.
+func (e *Expectation) RespondWith(h http.Handler) *Expectation {
+	e.handler = h
+	return e
+}
+
+// RespondError sets err to be returned for matching requests instead of a
+// response, i.e. to simulate connection errors.
+func (e *Expectation) RespondError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// WithResponseHeader adds a header to the response sent for matching
+// requests.
+func (e *Expectation) WithResponseHeader(header, value string) *Expectation {
+	e.header.Add(header, value)
+	return e
+}
+
+// Times sets the number of times the expectation is expected to match. Once
+// matched n times, the expectation does not match any further requests. By
+// default an expectation matches an unlimited number of times and is
+// expected to match at least once.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) String() string {
+	descs := make([]string, len(e.matchers))
+	for i, m := range e.matchers {
+		descs[i] = m.String()
+	}
+	return strings.Join(descs, ", ")
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times > 0 && e.calls >= e.times
+}
+
+func (e *Expectation) satisfied() bool {
+	if e.times > 0 {
+		return e.calls == e.times
+	}
+	return e.calls > 0
+}
+
+// MockTransport implements a http.RoundTripper serving requests based on
+// registered expectations. Pass it to a Client using httpclient.WithTransport.
+// A MockTransport is safe for concurrent use.
+type MockTransport struct {
+	mutex        sync.Mutex
+	expectations []*Expectation
+}
+
+// NewMockTransport creates a new MockTransport without any expectations.
+func NewMockTransport() *MockTransport {
+	return &MockTransport{}
+}
+
+// On registers an expectation for requests matching all of matchers.
+// Expectations are evaluated in registration order. Use the methods of the
+// returned Expectation to define the response.
+func (m *MockTransport) On(matchers ...Matcher) *Expectation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	e := &Expectation{
+		matchers: matchers,
+		header:   make(http.Header),
+	}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e := m.match(req, body)
+	if e == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnexpectedRequest, req.Method, req.URL)
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	rec := httptest.NewRecorder()
+	for k, vs := range e.header {
+		rec.Header()[k] = vs
+	}
+
+	if e.handler != nil {
+		resetBody(req, body)
+		e.handler.ServeHTTP(rec, req)
+	}
+
+	res := rec.Result()
+	res.Request = req
+	return res, nil
+}
+
+func (m *MockTransport) match(req *http.Request, body []byte) *Expectation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, e := range m.expectations {
+		if e.exhausted() {
+			continue
+		}
+
+		matches := true
+		for _, matcher := range e.matchers {
+			resetBody(req, body)
+			if !matcher.Match(req) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			e.calls++
+			return e
+		}
+	}
+
+	return nil
+}
+
+// AssertExpectations reports an error to t for every expectation that has not
+// been matched the expected number of times.
+func (m *MockTransport) AssertExpectations(t testing.TB) {
+	t.Helper()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, e := range m.expectations {
+		if e.satisfied() {
+			continue
+		}
+
+		if e.times > 0 {
+			t.Errorf("expected request matching [%s] %d time(s) but got %d", e, e.times, e.calls)
+		} else {
+			t.Errorf("expected request matching [%s] but got none", e)
+		}
+	}
+}
+
+func resetBody(req *http.Request, body []byte) {
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+}
@@ -0,0 +1,69 @@
+package httpclienttest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestMockTransport(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	mock := httpclienttest.NewMockTransport()
+	mock.On(
+		httpclienttest.MatchMethod(http.MethodPost),
+		httpclienttest.MatchPath("/users"),
+		httpclienttest.MatchJSONBody(func(u user) bool { return u.Name == "gopher" }),
+	).RespondJSON(http.StatusCreated, user{Name: "gopher"}).Times(1)
+
+	mock.On(
+		httpclienttest.MatchMethod(http.MethodGet),
+		httpclienttest.MatchPath("/users/*"),
+		httpclienttest.MatchHeader("Accept", "application/json"),
+	).RespondJSON(http.StatusOK, user{Name: "gopher"})
+
+	mock.On(httpclienttest.MatchPath("/never"))
+
+	client := httpclient.New(
+		httpclient.WithTransport(mock),
+		httpclient.WithURLPrefix("http://example.com"),
+	)
+
+	ctx := context.Background()
+
+	var created user
+	res, err := client.Post(ctx, "/users", httpclient.WithJSON(user{Name: "gopher"}), httpclient.ForJSON(&created))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusCreated))
+	ExpectThat(t, created).Is(Equal(user{Name: "gopher"}))
+
+	_, err = client.Post(ctx, "/users", httpclient.WithJSON(user{Name: "gopher"}))
+	ExpectThat(t, err).Is(Error(httpclienttest.ErrUnexpectedRequest))
+
+	var got user
+	_, err = client.Get(ctx, "/users/1", httpclient.ForJSON(&got))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, got).Is(Equal(user{Name: "gopher"}))
+
+	var tb recordingTB
+	mock.AssertExpectations(&tb)
+	ExpectThat(t, tb.errors).Is(DeepEqual([]string{"expected request matching [path /never] but got none"}))
+}
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (*recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,157 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/halimath/httpclient"
+)
+
+// Exchange captures a request sent by a Client together with the response
+// or error received.
+type Exchange struct {
+	Request      *http.Request
+	RequestBody  []byte
+	Response     *http.Response
+	ResponseBody []byte
+	Err          error
+}
+
+// RecordingInterceptor captures all exchanges of a Client and provides
+// assertion helpers to verify them. Exchanges are captured at the transport
+// level, so the recorded requests reflect the modifications of all
+// interceptors. A RecordingInterceptor is safe for concurrent use.
+//
+// In contrast to har.Recorder, which redacts secrets and converts exchanges
+// into HAR entries meant to be stored, a RecordingInterceptor keeps the
+// original *http.Request and *http.Response values including all headers,
+// so tests can assert on exactly what has been sent.
+type RecordingInterceptor struct {
+	mutex     sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecordingInterceptor creates a new RecordingInterceptor with no
+// exchanges captured.
+func NewRecordingInterceptor() *RecordingInterceptor {
+	return &RecordingInterceptor{}
+}
+
+// Option returns the ClientOption installing r. It must be given after any
+// option replacing the transport, as exchanges are captured by wrapping the
+// transport configured at that point.
+func (r *RecordingInterceptor) Option() httpclient.ClientOption {
+	return httpclient.HTTPClientOption(func(c *http.Client) {
+		next := c.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		c.Transport = &recordingTransport{recorder: r, next: next}
+	})
+}
+
+// Exchanges returns all exchanges captured so far in the order they have been
+// sent.
+func (r *RecordingInterceptor) Exchanges() []Exchange {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	l := make([]Exchange, len(r.exchanges))
+	copy(l, r.exchanges)
+	return l
+}
+
+// AssertRequested reports an error to t if no request with method has been
+// sent to url. url is compared to the full request URL or, if it starts with
+// a slash, to the request's path and query.
+func (r *RecordingInterceptor) AssertRequested(t testing.TB, method, url string) {
+	t.Helper()
+
+	for _, e := range r.Exchanges() {
+		if e.Request.Method != method {
+			continue
+		}
+
+		if e.Request.URL.String() == url || (strings.HasPrefix(url, "/") && e.Request.URL.RequestURI() == url) {
+			return
+		}
+	}
+
+	t.Errorf("expected %s %s to be requested", method, url)
+}
+
+// LastRequestBodyJSON unmarshals the body of the last captured request into
+// v. It fails t immediately if no request has been captured or the body cannot
+// be unmarshaled.
+func (r *RecordingInterceptor) LastRequestBodyJSON(t testing.TB, v any) {
+	t.Helper()
+
+	exchanges := r.Exchanges()
+	if len(exchanges) == 0 {
+		t.Fatal("expected a request to be sent but got none")
+	}
+
+	if err := json.Unmarshal(exchanges[len(exchanges)-1].RequestBody, v); err != nil {
+		t.Fatalf("failed to unmarshal request body: %v", err)
+	}
+}
+
+func (r *RecordingInterceptor) add(e Exchange) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.exchanges = append(r.exchanges, e)
+}
+
+type recordingTransport struct {
+	recorder *RecordingInterceptor
+	next     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var e Exchange
+
+	if req.Body != nil {
+		var err error
+		e.RequestBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(e.RequestBody))
+	}
+
+	e.Request = req.Clone(req.Context())
+	if req.Body != nil {
+		e.Request.Body = io.NopCloser(bytes.NewReader(e.RequestBody))
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		e.Err = err
+		t.recorder.add(e)
+		return res, err
+	}
+
+	e.ResponseBody, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(e.ResponseBody))
+	e.Response = res
+
+	// A response whose body could not be read completely is recorded with
+	// the error but not passed on, as a RoundTripper must not return both.
+	if err != nil {
+		e.Err = err
+		t.recorder.add(e)
+		return nil, err
+	}
+
+	t.recorder.add(e)
+
+	return res, nil
+}
@@ -0,0 +1,73 @@
+package httpclienttest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestRecordingInterceptor(t *testing.T) {
+	mock := httpclienttest.NewMockTransport()
+	mock.On(httpclienttest.MatchPath("/users")).Respond(http.StatusCreated, "")
+
+	rec := httpclienttest.NewRecordingInterceptor()
+	client := httpclient.New(
+		httpclient.WithTransport(mock),
+		rec.Option(),
+		httpclient.WithURLPrefix("http://example.com"),
+	)
+
+	_, err := client.Post(context.Background(), "/users?notify=true", httpclient.WithJSON(map[string]string{"name": "gopher"}))
+	ExpectThat(t, err).Is(NoError())
+
+	rec.AssertRequested(t, http.MethodPost, "/users?notify=true")
+	rec.AssertRequested(t, http.MethodPost, "http://example.com/users?notify=true")
+
+	var body map[string]string
+	rec.LastRequestBodyJSON(t, &body)
+	ExpectThat(t, body).Is(DeepEqual(map[string]string{"name": "gopher"}))
+
+	exchanges := rec.Exchanges()
+	ExpectThat(t, exchanges).Is(Len(1))
+	ExpectThat(t, exchanges[0].Response.StatusCode).Is(Equal(http.StatusCreated))
+}
+
+// failingBodyTransport responds with a body failing with errBody after the
+// first bytes.
+type failingBodyTransport struct{}
+
+var errBody = errors.New("connection lost")
+
+func (failingBodyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errBody))),
+		Request:    r,
+	}, nil
+}
+
+func TestRecordingInterceptor_bodyError(t *testing.T) {
+	rec := httpclienttest.NewRecordingInterceptor()
+	client := httpclient.New(
+		httpclient.WithTransport(failingBodyTransport{}),
+		rec.Option(),
+	)
+
+	res, err := client.Get(context.Background(), "http://example.com/")
+	ExpectThat(t, err).Is(Error(errBody))
+	ExpectThat(t, res == nil).Is(Equal(true))
+
+	exchanges := rec.Exchanges()
+	ExpectThat(t, exchanges).Is(Len(1))
+	ExpectThat(t, exchanges[0].Err).Is(Error(errBody))
+	ExpectThat(t, string(exchanges[0].ResponseBody)).Is(Equal("partial"))
+}
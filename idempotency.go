@@ -0,0 +1,30 @@
+package httpclient
+
+import "net/http"
+
+// IdempotencyKeyHeader is the header carrying the key set by
+// WithIdempotencyKey.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey creates a RequestInterceptorOption setting an
+// Idempotency-Key header on POST and PATCH requests, which allows servers
+// implementing Stripe-style idempotency to recognize repeated requests. gen
+// is called once per request to create the key; it defaults to NewUUID.
+// Requests already carrying the header keep their key.
+//
+// The key is set before the request is sent, so every attempt made by
+// WithRetry carries the same key. As requests with an Idempotency-Key header
+// are considered retryable, this option makes WithRetry retry POST and PATCH
+// requests as well.
+func WithIdempotencyKey(gen func() string) RequestInterceptorOption {
+	if gen == nil {
+		gen = NewUUID
+	}
+
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		if (r.Method == http.MethodPost || r.Method == http.MethodPatch) && r.Header.Get(IdempotencyKeyHeader) == "" {
+			r.Header.Set(IdempotencyKeyHeader, gen())
+		}
+		return r, nil
+	})
+}
@@ -0,0 +1,48 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(httpclient.IdempotencyKeyHeader))
+		if r.URL.Path == "/unavailable" {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var n int
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRetry(httpclient.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		httpclient.WithIdempotencyKey(func() string {
+			n++
+			return fmt.Sprintf("key-%d", n)
+		}),
+	)
+
+	_, err := client.Post(context.Background(), "/unavailable", httpclient.WithJSON("value"))
+	ExpectThat(t, err).Is(NoError())
+
+	_, err = client.Patch(context.Background(), "/", httpclient.WithRequestHeader(httpclient.IdempotencyKeyHeader, "given"))
+	ExpectThat(t, err).Is(NoError())
+
+	_, err = client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, keys).Is(DeepEqual([]string{"key-1", "key-1", "key-1", "given", ""}))
+}
@@ -2,12 +2,15 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 )
 
 // RequestInterceptor defines an interface for types that can intercept a
@@ -60,6 +63,38 @@ func WithRequestHeader(header, value string) RequestInterceptorOption {
 	})
 }
 
+// WithoutRequestHeader creates a RequestInterceptorOption that removes header
+// from the given request, i.e. to drop a header set by WithDefaultHeaders for
+// a single request.
+func WithoutRequestHeader(header string) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		r.Header.Del(header)
+		return r, nil
+	})
+}
+
+// WithDefaultHeaders creates a RequestInterceptorOption that sets the headers
+// in h on every request not already carrying them. Given to New, the defaults
+// are applied before any request-level option, so requests can override a
+// default using WithRequestHeader or remove it using WithoutRequestHeader.
+func WithDefaultHeaders(h http.Header) RequestInterceptorOption {
+	h = h.Clone()
+
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		for name, values := range h {
+			if _, ok := r.Header[name]; !ok {
+				r.Header[name] = append([]string(nil), values...)
+			}
+		}
+		return r, nil
+	})
+}
+
+// WithDefaultHeader is like WithDefaultHeaders for a single header.
+func WithDefaultHeader(header, value string) RequestInterceptorOption {
+	return WithDefaultHeaders(http.Header{http.CanonicalHeaderKey(header): {value}})
+}
+
 type readCloser struct {
 	r io.Reader
 }
@@ -88,25 +123,55 @@ func withBody(r io.Reader, contentType string, length int64) RequestInterceptorF
 	}
 }
 
+// bodyInterceptor is a RequestInterceptor setting a request body read from
+// an io.Reader. As the reader can only be consumed once, the interceptor
+// tracks whether it has been used.
+type bodyInterceptor struct {
+	r           io.Reader
+	contentType string
+	length      int64
+	used        atomic.Bool
+}
+
+func (b *bodyInterceptor) InterceptRequest(req *http.Request) (*http.Request, error) {
+	b.used.Store(true)
+	return withBody(b.r, b.contentType, b.length).InterceptRequest(req)
+}
+
+// WithBody creates a RequestInterceptorOption that uses r as the request's
+// body with the given contentType and length. As r is consumed when the
+// request is sent, the returned option must not be used for more than one
+// request.
 func WithBody(r io.Reader, contentType string, length int64) RequestInterceptorOption {
-	return WithRequestInterceptorFunc(withBody(r, contentType, length))
+	return WithRequestInterceptor(&bodyInterceptor{r: r, contentType: contentType, length: length})
 }
 
 // WithJSON uses value as a JSON encoded request body. It returns a
 // RequestInterceptor wrapped in a RequestInterceptorOption that marshals value
 // and sets it as the request`s Body. If the request had a previous non-nil
 // Body this value is closed before. The interceptor also sets the
-// Content-Type request header as well as the Content-Length header.
-// Any error produced by json.Marshal or a previous request body's Close method
-// is returned and aborts the request.
+// Content-Type request header as well as the Content-Length header and the
+// request's GetBody function, so the request can be retried.
+// Any error produced by json.Marshal - or the codec given using
+// WithJSONCodec - or a previous request body's Close method is returned and
+// aborts the request.
 func WithJSON(value any) RequestInterceptorOption {
 	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
-		b, err := json.Marshal(value)
+		marshal := json.Marshal
+		if codec := jsonCodecFromContext(r.Context()); codec != nil {
+			marshal = codec.marshal
+		}
+
+		b, err := marshal(value)
 		if err != nil {
 			return r, err
 		}
 
-		return withBody(bytes.NewReader(b), "application/json", int64(len(b))).InterceptRequest(r)
+		r, err = withBody(bytes.NewReader(b), "application/json", int64(len(b))).InterceptRequest(r)
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}
+		return r, err
 	})
 }
 
@@ -148,31 +213,161 @@ func WithResponseInterceptorFunc(f func(*http.Response) (*http.Response, error))
 	return ResponseInterceptorOption{ResponseInterceptorFunc(f)}
 }
 
+// responseContext returns the context of the request that produced r. It
+// returns context.Background() for responses without a request, i.e. when
+// returned by a bare http.RoundTripper.
+func responseContext(r *http.Response) context.Context {
+	if r.Request == nil {
+		return context.Background()
+	}
+	return r.Request.Context()
+}
+
 // ExpectedStatusCode creates a ResponseInterceptor wrapped in a
 // ResponseInterceptorOption that expects the resonse' status code to be any
 // of the expectedStatusCodes. If the status code matches, the response is
 // returned as is with a nil error. If the status code matches neither of the
-// given status codes, an error is returned.
+// given status codes, an *Error of kind ErrUnexpectedStatus is returned. The
+// interceptor runs in PhaseValidate.
 func ExpectedStatusCode(expectedStatusCodes ...int) ResponseInterceptorOption {
-	return WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+	return InPhaseFunc(PhaseValidate, func(r *http.Response) (*http.Response, error) {
 		for _, statusCode := range expectedStatusCodes {
 			if r.StatusCode == statusCode {
 				return r, nil
 			}
 		}
-		return r, fmt.Errorf("unexpected status code: %d", r.StatusCode)
+		return r, newResponseError(ErrUnexpectedStatus, r, nil)
+	})
+}
+
+// ExpectedStatusRange creates a ResponseInterceptorOption that works like
+// ExpectedStatusCode but accepts any status code between min and max, both
+// inclusive.
+func ExpectedStatusRange(min, max int) ResponseInterceptorOption {
+	return InPhaseFunc(PhaseValidate, func(r *http.Response) (*http.Response, error) {
+		if r.StatusCode < min || r.StatusCode > max {
+			return r, newResponseError(ErrUnexpectedStatus, r, nil)
+		}
+		return r, nil
+	})
+}
+
+// ExpectSuccess creates a ResponseInterceptorOption that accepts any
+// successful (2xx) status code. It is a synonym for Expect2xx.
+func ExpectSuccess() ResponseInterceptorOption {
+	return Expect2xx()
+}
+
+// Expect2xx creates a ResponseInterceptorOption that accepts any 2xx status
+// code. See ExpectedStatusRange.
+func Expect2xx() ResponseInterceptorOption {
+	return ExpectedStatusRange(200, 299)
+}
+
+// Expect3xx creates a ResponseInterceptorOption that accepts any 3xx status
+// code. See ExpectedStatusRange. Note that http.Client follows most redirects
+// unless configured otherwise using http.Client.CheckRedirect.
+func Expect3xx() ResponseInterceptorOption {
+	return ExpectedStatusRange(300, 399)
+}
+
+// StatusHandlers maps status codes to the ResponseInterceptor handling
+// responses with that status code. A nil handler accepts responses with the
+// status code without any further processing.
+type StatusHandlers map[int]ResponseInterceptor
+
+// OnStatus creates a ResponseInterceptorOption that passes responses with
+// status code to handler. Responses with a different status code are
+// returned unchanged.
+//
+// The interceptor runs in PhasePreValidate, so handler sees responses before
+// any status validation - such as a client-level ExpectedStatusCode - rejects
+// them. OnStatus does not validate the status itself; use SwitchStatus to
+// replace status validation with per-status handlers.
+func OnStatus(code int, handler ResponseInterceptor) ResponseInterceptorOption {
+	return InPhaseFunc(PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		if r.StatusCode != code {
+			return r, nil
+		}
+		return handler.InterceptResponse(r)
+	})
+}
+
+// OnStatusFunc is like OnStatus but accepts a bare function as handler.
+func OnStatusFunc(code int, handler func(*http.Response) (*http.Response, error)) ResponseInterceptorOption {
+	return OnStatus(code, ResponseInterceptorFunc(handler))
+}
+
+// SwitchStatus creates a ResponseInterceptorOption that works like
+// ExpectedStatusCode with the keys of handlers as expected status codes. In
+// addition, responses are passed to the handler registered for their status
+// code. This allows to declare the handling of all expected outcomes of a
+// request in one place, i.e.
+//
+//	httpclient.SwitchStatus(httpclient.StatusHandlers{
+//		http.StatusOK:       httpclient.ResponseInterceptorFunc(decodePayload),
+//		http.StatusNotFound: nil,
+//		http.StatusConflict: httpclient.ResponseInterceptorFunc(decodeConflict),
+//	})
+//
+// The interceptor runs in PhaseValidate.
+func SwitchStatus(handlers StatusHandlers) ResponseInterceptorOption {
+	return InPhaseFunc(PhaseValidate, func(r *http.Response) (*http.Response, error) {
+		handler, ok := handlers[r.StatusCode]
+		if !ok {
+			return r, newResponseError(ErrUnexpectedStatus, r, nil)
+		}
+
+		if handler == nil {
+			return r, nil
+		}
+
+		return handler.InterceptResponse(r)
 	})
 }
 
+// JSONOption customizes the decoding performed by ForJSON.
+type JSONOption func(*forJSON)
+
+// DisallowUnknownFields causes ForJSON to fail with an *Error of kind
+// ErrDecode if the response body contains object keys which do not match any
+// non-ignored, exported field of the target value. See
+// json.Decoder.DisallowUnknownFields.
+func DisallowUnknownFields() JSONOption {
+	return func(jr *forJSON) {
+		jr.disallowUnknownFields = true
+	}
+}
+
+// MaxJSONSize limits the number of bytes ForJSON reads from the response
+// body to n. Decoding a larger body fails with an *Error of kind ErrDecode.
+func MaxJSONSize(n int64) JSONOption {
+	return func(jr *forJSON) {
+		jr.maxSize = n
+	}
+}
+
+// StreamJSON causes ForJSON to decode the response body without retaining
+// the bytes read, so even large bodies are never held in memory as a whole.
+// The response body is consumed afterwards.
+func StreamJSON() JSONOption {
+	return func(jr *forJSON) {
+		jr.stream = true
+	}
+}
+
 // forJSON is both a RequestInterceptor and a ResponseInterceptor that is
 // used to handle a JSON response body. During request interception, this type
 // adds an Accept request header accepting application/json. In the response
 // interception this type expects the content type to be application/json and
-// then unmarshals the response body to the given value. If the returned
-// content type is not application/json an error is returned. Any error that
-// occurs while unmarshaling the response body is also returned.
+// then decodes the response body to the given value. If the returned
+// content type is not application/json or decoding the response body
+// fails, an *Error of kind ErrDecode is returned.
 type forJSON struct {
-	value any
+	value                 any
+	disallowUnknownFields bool
+	maxSize               int64
+	stream                bool
 }
 
 func (*forJSON) clientOpt() {}
@@ -186,46 +381,143 @@ func (*forJSON) InterceptRequest(r *http.Request) (*http.Request, error) {
 func (jr *forJSON) InterceptResponse(r *http.Response) (*http.Response, error) {
 	ct := r.Header.Get("Content-Type")
 	if !strings.HasPrefix(ct, "application/json") {
-		return r, fmt.Errorf("expected JSON response but got %s", ct)
+		return r, newResponseError(ErrDecode, r, fmt.Errorf("expected JSON response but got %s", ct))
 	}
 
-	d, err := io.ReadAll(r.Body)
-	if err != nil {
-		return r, err
+	return jr.decode(r)
+}
+
+// decode decodes the body of r without checking its content type.
+func (jr *forJSON) decode(r *http.Response) (*http.Response, error) {
+	var src io.Reader = r.Body
+	if jr.maxSize > 0 {
+		src = &maxSizeReader{r: src, n: jr.maxSize}
+	}
+
+	var buf bytes.Buffer
+	if !jr.stream {
+		src = io.TeeReader(src, &buf)
+		// Restore the body, so it can be read again by subsequent
+		// interceptors or the caller. Closing it still closes the original
+		// body.
+		defer func(body io.ReadCloser) {
+			r.Body = &multiReadCloser{io.MultiReader(&buf, body), body}
+		}(r.Body)
+	}
+
+	codec := jsonCodecFromContext(responseContext(r))
+
+	if codec != nil {
+		d, err := io.ReadAll(src)
+		if err == nil {
+			err = codec.unmarshal(d, jr.value)
+		}
+		if err != nil {
+			return r, newResponseError(ErrDecode, r, err)
+		}
+		return r, nil
+	}
+
+	dec := json.NewDecoder(src)
+	if jr.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(jr.value); err != nil {
+		return r, newResponseError(ErrDecode, r, err)
+	}
+
+	// Like json.Unmarshal, reject data following the decoded value.
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("invalid character after top-level value")
+		}
+		return r, newResponseError(ErrDecode, r, err)
 	}
 
-	return r, json.Unmarshal(d, jr.value)
+	return r, nil
+}
+
+// maxSizeReader reads up to n bytes from r and fails if r contains more.
+type maxSizeReader struct {
+	r        io.Reader
+	n        int64
+	read     int64
+	exceeded bool
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	if m.read >= m.n {
+		var b [1]byte
+		if n, _ := io.ReadFull(m.r, b[:]); n > 0 {
+			m.exceeded = true
+			return 0, fmt.Errorf("response body exceeds %d bytes", m.n)
+		}
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > m.n-m.read {
+		p = p[:m.n-m.read]
+	}
+	n, err := m.r.Read(p)
+	m.read += int64(n)
+	return n, err
 }
 
 // ForJSON creates a RequestOption that captures the response body JSON data
-// and unmarshals the data into value.
+// and decodes the data into value.
 // The returned option is both a RequestInterceptor and a ResponseInterceptor.
 // During request interception, this type
 // adds an Accept request header accepting application/json. In the response
 // interception this type expects the content type to be application/json and
-// then unmarshals the response body to the given value. If the returned
-// content type is not application/json an error is returned. Any error that
-// occurs while unmarshaling the response body is also returned.
-func ForJSON(value any) RequestOption {
-	return &forJSON{value}
+// then decodes the response body to the given value. If the returned
+// content type is not application/json or decoding the response body
+// fails, an *Error of kind ErrDecode is returned. Unless StreamJSON is given,
+// the response body remains readable after it has been decoded. Use opts to
+// customize decoding.
+func ForJSON(value any, opts ...JSONOption) RequestOption {
+	jr := &forJSON{value: value}
+	for _, opt := range opts {
+		opt(jr)
+	}
+	return jr
 }
 
 // WithURLPrefix creates a RequestInterceptorOption that applies a common URL
 // prefix to requests not starting with either http:// or https://.
-// prefix must be a syntactically valid HTTP(s) URL.
+// prefix must be a syntactically valid HTTP(s) URL. Given to NewE, a malformed
+// prefix is reported as an error.
 //
 // Any error produced by applying the prefix to a request's URL will be
 // returned when the request is executed.
 func WithURLPrefix(prefix string) RequestInterceptorOption {
-	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
-		if !r.URL.IsAbs() {
-			u, err := url.Parse(prefix + r.URL.String())
-			if err != nil {
-				return r, err
-			}
-			r.URL = u
+	return WithRequestInterceptor(urlPrefix(prefix))
+}
+
+// urlPrefix is the RequestInterceptor created by WithURLPrefix.
+type urlPrefix string
+
+func (p urlPrefix) InterceptRequest(r *http.Request) (*http.Request, error) {
+	if !r.URL.IsAbs() {
+		u, err := url.Parse(string(p) + r.URL.String())
+		if err != nil {
+			return r, err
 		}
+		r.URL = u
+	}
 
-		return r, nil
-	})
+	return r, nil
+}
+
+func (p urlPrefix) validate() error {
+	u, err := url.Parse(string(p))
+	if err != nil {
+		return fmt.Errorf("invalid URL prefix: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid URL prefix: %s: expected http or https URL", p)
+	}
+
+	return nil
 }
@@ -0,0 +1,260 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestSwitchStatus(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		w.WriteHeader(status)
+	}))
+	defer testServer.Close()
+
+	errConflict := errors.New("conflict")
+	var handled string
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	get := func(status int) error {
+		handled = ""
+		_, err := client.Get(context.Background(), "/?status="+strconv.Itoa(status),
+			httpclient.SwitchStatus(httpclient.StatusHandlers{
+				http.StatusOK: httpclient.ResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+					handled = "ok"
+					return r, nil
+				}),
+				http.StatusNotFound: nil,
+				http.StatusConflict: httpclient.ResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+					return r, errConflict
+				}),
+			}),
+			httpclient.OnStatusFunc(http.StatusNotFound, func(r *http.Response) (*http.Response, error) {
+				handled = "not found"
+				return r, nil
+			}),
+		)
+		return err
+	}
+
+	ExpectThat(t, get(http.StatusOK)).Is(NoError())
+	ExpectThat(t, handled).Is(Equal("ok"))
+
+	ExpectThat(t, get(http.StatusNotFound)).Is(NoError())
+	ExpectThat(t, handled).Is(Equal("not found"))
+
+	ExpectThat(t, get(http.StatusConflict)).Is(Error(errConflict))
+
+	ExpectThat(t, get(http.StatusTeapot)).Is(NotNil())
+	ExpectThat(t, handled).Is(Equal(""))
+}
+
+func TestExpectedStatusRange(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		w.WriteHeader(status)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	get := func(status int, opt httpclient.RequestOption) error {
+		_, err := client.Get(context.Background(), "/?status="+strconv.Itoa(status), opt)
+		return err
+	}
+
+	ExpectThat(t, get(http.StatusOK, httpclient.ExpectSuccess())).Is(NoError())
+	ExpectThat(t, get(http.StatusNoContent, httpclient.Expect2xx())).Is(NoError())
+	ExpectThat(t, get(http.StatusNotModified, httpclient.Expect2xx())).Is(Error(httpclient.ErrUnexpectedStatus))
+	ExpectThat(t, get(http.StatusNotModified, httpclient.Expect3xx())).Is(NoError())
+	ExpectThat(t, get(http.StatusOK, httpclient.Expect3xx())).Is(Error(httpclient.ErrUnexpectedStatus))
+	ExpectThat(t, get(http.StatusNotFound, httpclient.ExpectedStatusRange(400, 404))).Is(NoError())
+	ExpectThat(t, get(http.StatusConflict, httpclient.ExpectedStatusRange(400, 404))).Is(Error(httpclient.ErrUnexpectedStatus))
+}
+
+func TestOnStatus_beforeValidation(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer testServer.Close()
+
+	errNotFound := errors.New("not found")
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	_, err := client.Get(context.Background(), "/",
+		httpclient.OnStatusFunc(http.StatusNotFound, func(r *http.Response) (*http.Response, error) {
+			return r, errNotFound
+		}),
+	)
+	ExpectThat(t, err).Is(Error(errNotFound))
+}
+
+func TestWithDefaultHeaders(t *testing.T) {
+	var received http.Header
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithDefaultHeaders(http.Header{
+			"Accept":     {"application/json"},
+			"X-Api-Key":  {"key"},
+			"X-Features": {"a", "b"},
+		}),
+		httpclient.WithDefaultHeader("x-tenant", "acme"),
+	)
+
+	_, err := client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("Accept")).Is(Equal("application/json"))
+	ExpectThat(t, received.Get("X-Tenant")).Is(Equal("acme"))
+	ExpectThat(t, received.Values("X-Features")).Is(DeepEqual([]string{"a", "b"}))
+
+	_, err = client.Get(context.Background(), "/",
+		httpclient.WithRequestHeader("Accept", "text/plain"),
+		httpclient.WithoutRequestHeader("X-Api-Key"),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("Accept")).Is(Equal("text/plain"))
+	ExpectThat(t, received.Get("X-Api-Key")).Is(Equal(""))
+	ExpectThat(t, received.Get("X-Tenant")).Is(Equal("acme"))
+}
+
+func TestForJSON_bodyRemainsReadable(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"foo"}`))
+	}))
+	defer testServer.Close()
+
+	var v struct {
+		Name string `json:"name"`
+	}
+	var body []byte
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+	_, err := client.Get(context.Background(), "/",
+		httpclient.ForJSON(&v),
+		httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			return r, err
+		}),
+	)
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, v.Name).Is(Equal("foo"))
+	ExpectThat(t, string(body)).Is(Equal(`{"name":"foo"}`))
+}
+
+func TestForJSON_options(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"foo","age":42}`))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	type target struct {
+		Name string `json:"name"`
+	}
+
+	t.Run("DisallowUnknownFields", func(t *testing.T) {
+		var v target
+		_, err := client.Get(context.Background(), "/", httpclient.ForJSON(&v, httpclient.DisallowUnknownFields()))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+
+	t.Run("MaxJSONSize", func(t *testing.T) {
+		var v target
+		_, err := client.Get(context.Background(), "/", httpclient.ForJSON(&v, httpclient.MaxJSONSize(10)))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+
+		_, err = client.Get(context.Background(), "/", httpclient.ForJSON(&v, httpclient.MaxJSONSize(23)))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, v.Name).Is(Equal("foo"))
+	})
+
+	t.Run("StreamJSON", func(t *testing.T) {
+		var v target
+		_, err := client.Get(context.Background(), "/", httpclient.ForJSON(&v, httpclient.StreamJSON()))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, v.Name).Is(Equal("foo"))
+	})
+}
+
+// bareTransport is a http.RoundTripper returning responses that don't
+// reference the request they answer.
+type bareTransport struct {
+	contentType, body string
+}
+
+func (b bareTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {b.contentType}},
+		Body:          io.NopCloser(strings.NewReader(b.body)),
+		ContentLength: int64(len(b.body)),
+	}, nil
+}
+
+func TestResponseInterceptors_withoutRequest(t *testing.T) {
+	t.Run("ForJSONStream", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithTransport(bareTransport{"application/x-ndjson", `{"id":1}` + "\n" + `{"id":2}`}))
+
+		var count int
+		_, err := client.Get(context.Background(), "http://example.com/", httpclient.ForJSONStream(func(json.RawMessage) error {
+			count++
+			return nil
+		}))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, count).Is(Equal(2))
+	})
+
+	t.Run("ForGraphQL", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithTransport(bareTransport{"application/json", `{"data":{"id":1}}`}))
+
+		var data struct{ ID int }
+		_, err := client.Post(context.Background(), "http://example.com/", httpclient.WithGraphQL("{ id }", nil), httpclient.ForGraphQL(&data))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, data.ID).Is(Equal(1))
+	})
+
+	t.Run("ForBody", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithTransport(bareTransport{"application/json", `{"id":1}`}))
+
+		var data struct{ ID int }
+		_, err := client.Get(context.Background(), "http://example.com/", httpclient.ForBody(&data))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, data.ID).Is(Equal(1))
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithTransport(bareTransport{"text/plain", "hello"}))
+
+		var buf strings.Builder
+		n, err := client.Download(context.Background(), "http://example.com/", &buf, httpclient.WithDownloadProgress(func(httpclient.Progress) {}))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, n).Is(Equal(int64(5)))
+		ExpectThat(t, buf.String()).Is(Equal("hello"))
+	})
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// Producer produces the items contained in a response by calling yield for
+// each of them. A Producer must stop producing and return nil as soon as
+// yield returns false. Any error returned is passed on to the consumer of the
+// iteration.
+type Producer[T any] func(r *http.Response, yield func(T) bool) error
+
+// Iterate creates an iterator over the items produced by produce from the
+// response received for a request to url using method. The request is sent
+// using c and opts when the iteration starts, so every iteration sends a new
+// request.
+//
+// produce runs in PhasePostValidate and thus only sees responses that passed
+// the status validation configured for c or with opts. Any error that occurs
+// while sending the request, processing the response or producing items is
+// yielded as the final element of the iteration with the zero value of T.
+// When the consumer stops the iteration early, i.e. by breaking out of a range
+// loop, the response body is closed and no error is yielded. A panic raised
+// by the loop body is passed on to the consumer as is.
+//
+//	items := httpclient.Iterate(ctx, client, http.MethodGet, "/items", produceItems)
+//	for item, err := range items {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+func Iterate[T any](ctx context.Context, c *Client, method, url string, produce Producer[T], opts ...RequestOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var stopped bool
+		var body loopBody
+
+		yieldItem := func(item T) bool {
+			if stopped {
+				return false
+			}
+			stopped = !body.call(func() bool { return yield(item, nil) })
+			return !stopped
+		}
+
+		reqOpts := append(opts[:len(opts):len(opts)], InPhaseFunc(PhasePostValidate, func(r *http.Response) (*http.Response, error) {
+			return r, produce(r, yieldItem)
+		}))
+
+		_, err := c.Execute(ctx, method, url, reqOpts...)
+		body.repanic()
+		if err != nil && !stopped {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// loopBody runs the loop body of a range-over-func iteration from within a
+// response interceptor. A panic raised by the loop body is captured instead
+// of being recovered as an *InterceptorPanicError, so it can be re-raised
+// once the request has completed.
+type loopBody struct {
+	panicked bool
+	value    any
+}
+
+// call invokes yield, which calls the loop body, and returns its result. If
+// the loop body panics, the panic is captured and call returns false.
+func (b *loopBody) call(yield func() bool) (ok bool) {
+	completed := false
+	defer func() {
+		if !completed {
+			if v := recover(); v != nil {
+				b.panicked, b.value = true, v
+			}
+		}
+	}()
+
+	ok = yield()
+	completed = true
+	return ok
+}
+
+// repanic re-raises the panic captured by call, if any.
+func (b *loopBody) repanic() {
+	if b.panicked {
+		panic(b.value)
+	}
+}
@@ -0,0 +1,83 @@
+package httpclient_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestIterate(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("a\nb\nc\n"))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	lines := func(r *http.Response, yield func(string) bool) error {
+		s := bufio.NewScanner(r.Body)
+		for s.Scan() {
+			if !yield(s.Text()) {
+				return nil
+			}
+		}
+		return s.Err()
+	}
+
+	t.Run("all", func(t *testing.T) {
+		var got []string
+		for line, err := range httpclient.Iterate(context.Background(), client, http.MethodGet, "/lines", lines) {
+			ExpectThat(t, err).Is(NoError())
+			got = append(got, line)
+		}
+		ExpectThat(t, got).Is(DeepEqual([]string{"a", "b", "c"}))
+	})
+
+	t.Run("break", func(t *testing.T) {
+		var got []string
+		for line, err := range httpclient.Iterate(context.Background(), client, http.MethodGet, "/lines", lines) {
+			ExpectThat(t, err).Is(NoError())
+			got = append(got, line)
+			if len(got) == 2 {
+				break
+			}
+		}
+		ExpectThat(t, got).Is(DeepEqual([]string{"a", "b"}))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var errs []error
+		for line, err := range httpclient.Iterate(context.Background(), client, http.MethodGet, "/missing", lines) {
+			ExpectThat(t, line).Is(Equal(""))
+			errs = append(errs, err)
+		}
+		ExpectThat(t, len(errs)).Is(Equal(1))
+		ExpectThat(t, errs[0]).Is(NotNil())
+	})
+
+	t.Run("loop body panic", func(t *testing.T) {
+		var calls int
+		recovered := func() (v any) {
+			defer func() { v = recover() }()
+			for range httpclient.Iterate(context.Background(), client, http.MethodGet, "/lines", lines) {
+				calls++
+				panic("user bug")
+			}
+			return nil
+		}()
+		ExpectThat(t, recovered).Is(Equal(any("user bug")))
+		ExpectThat(t, calls).Is(Equal(1))
+	})
+}
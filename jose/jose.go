@@ -0,0 +1,77 @@
+// Package jose implements message-level signatures and encryption for
+// requests and responses using JSON Web Signatures (JWS) as defined by RFC
+// 7515 and JSON Web Encryption (JWE) as defined by RFC 7516, as mandated by
+// fintech and PSD2-style APIs.
+//
+// Request bodies are signed using SignRequest, either by replacing the body
+// with a JWS in compact serialization or by sending a detached JWS in a
+// header while the body is sent unchanged. VerifyResponse verifies the
+// signatures of responses in either form, so decoders such as
+// httpclient.ForJSON only ever see verified payloads.
+//
+// EncryptRequest and DecryptResponse encrypt request bodies and decrypt
+// responses using JWE in compact serialization. Keys are given as Key values
+// or parsed from JSON Web Keys (JWK) as defined by RFC 7517 using ParseKey
+// and ParseKeySet.
+//
+// Only the algorithms listed as Algorithm and Encryption constants are
+// supported; the "none" algorithm is always rejected.
+package jose
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ContentType is the media type of JWS and JWE in compact serialization.
+const ContentType = "application/jose"
+
+// ErrInvalidSignature is returned if a JWS is malformed, uses an unsupported
+// algorithm or if its signature doesn't verify with any of the configured
+// keys.
+var ErrInvalidSignature = errors.New("jose: invalid signature")
+
+// Key is a key used to sign or verify a JWS.
+type Key struct {
+	// ID identifies the key. It is sent as the kid header parameter when
+	// signing; when verifying, only keys with the ID given by the kid header
+	// parameter are tried.
+	ID string
+
+	// Algorithm is the algorithm the key is used with. A JWS using any other
+	// algorithm never verifies with this key.
+	Algorithm Algorithm
+
+	// Key is the key material: a []byte for the HMAC algorithms, an
+	// *rsa.PrivateKey or an *ecdsa.PrivateKey to sign and the corresponding
+	// private or public key to verify using the RSA and ECDSA algorithms.
+	// For JWE, it is an *rsa.PublicKey to encrypt and an *rsa.PrivateKey to
+	// decrypt using RSA-OAEP and the content encryption key as a []byte
+	// using dir.
+	Key any
+}
+
+// Header is the protected header of a JWS or JWE.
+type Header struct {
+	Algorithm            Algorithm  `json:"alg"`
+	Encryption           Encryption `json:"enc,omitempty"`
+	Compression          string     `json:"zip,omitempty"`
+	Type                 string     `json:"typ,omitempty"`
+	KeyID                string     `json:"kid,omitempty"`
+	ContentType          string     `json:"cty,omitempty"`
+	X509Thumbprint       string     `json:"x5t,omitempty"`
+	X509ThumbprintSHA256 string     `json:"x5t#S256,omitempty"`
+	Critical             []string   `json:"crit,omitempty"`
+}
+
+// mediaType returns the media type denoted by the cty header parameter,
+// which may omit the "application/" prefix as recommended by RFC 7515.
+func (h Header) mediaType() string {
+	if h.ContentType == "" || strings.Contains(h.ContentType, "/") {
+		return h.ContentType
+	}
+	return "application/" + h.ContentType
+}
+
+var b64 = base64.RawURLEncoding
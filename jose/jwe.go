@@ -0,0 +1,312 @@
+package jose
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/halimath/httpclient"
+)
+
+// JWE key management algorithms.
+const (
+	RSAOAEP    Algorithm = "RSA-OAEP"
+	RSAOAEP256 Algorithm = "RSA-OAEP-256"
+	Direct     Algorithm = "dir"
+)
+
+// Encryption is a JWE content encryption algorithm as registered for the enc
+// header parameter by RFC 7518.
+type Encryption string
+
+// JWE content encryption algorithms.
+const (
+	A128GCM Encryption = "A128GCM"
+	A192GCM Encryption = "A192GCM"
+	A256GCM Encryption = "A256GCM"
+)
+
+// keySize returns the size of the content encryption key used by e in bytes.
+func (e Encryption) keySize() (int, bool) {
+	switch e {
+	case A128GCM:
+		return 16, true
+	case A192GCM:
+		return 24, true
+	case A256GCM:
+		return 32, true
+	}
+	return 0, false
+}
+
+// ErrDecryption is returned if a JWE is malformed, uses an unsupported
+// algorithm or can't be decrypted with any of the configured keys.
+var ErrDecryption = errors.New("jose: decryption failed")
+
+// Encrypt encrypts plaintext for key using enc and returns the JWE in compact
+// serialization.
+func Encrypt(plaintext []byte, key Key, enc Encryption) (string, error) {
+	return encrypt(plaintext, key, enc, "")
+}
+
+// Decrypt decrypts the JWE in compact serialization using one of keys and
+// returns its plaintext.
+func Decrypt(jwe string, keys []Key) ([]byte, error) {
+	_, plaintext, err := decrypt(jwe, keys)
+	return plaintext, err
+}
+
+// encrypt encrypts plaintext for key using enc with contentType as the cty
+// header parameter.
+func encrypt(plaintext []byte, key Key, enc Encryption, contentType string) (string, error) {
+	size, ok := enc.keySize()
+	if !ok {
+		return "", fmt.Errorf("jose: unsupported content encryption %q", enc)
+	}
+
+	var cek, encryptedKey []byte
+	switch key.Algorithm {
+	case Direct:
+		k, ok := key.Key.([]byte)
+		if !ok || len(k) != size {
+			return "", fmt.Errorf("jose: dir requires a %d byte key for %s", size, enc)
+		}
+		cek = k
+
+	case RSAOAEP, RSAOAEP256:
+		var pub *rsa.PublicKey
+		switch k := key.Key.(type) {
+		case *rsa.PublicKey:
+			pub = k
+		case *rsa.PrivateKey:
+			pub = &k.PublicKey
+		default:
+			return "", fmt.Errorf("jose: key of type %T can't be used with %s", key.Key, key.Algorithm)
+		}
+
+		cek = make([]byte, size)
+		if _, err := rand.Read(cek); err != nil {
+			return "", err
+		}
+
+		var err error
+		encryptedKey, err = rsa.EncryptOAEP(oaepHash(key.Algorithm), rand.Reader, pub, cek, nil)
+		if err != nil {
+			return "", err
+		}
+
+	default:
+		return "", fmt.Errorf("jose: unsupported key management algorithm %q", key.Algorithm)
+	}
+
+	h, err := json.Marshal(Header{Algorithm: key.Algorithm, Encryption: enc, KeyID: key.ID, ContentType: contentType})
+	if err != nil {
+		return "", err
+	}
+	protected := b64.EncodeToString(h)
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		b64.EncodeToString(encryptedKey),
+		b64.EncodeToString(iv),
+		b64.EncodeToString(ciphertext),
+		b64.EncodeToString(tag),
+	}, "."), nil
+}
+
+// decrypt decrypts jwe using one of keys and returns its header and
+// plaintext.
+func decrypt(jwe string, keys []Key) (Header, []byte, error) {
+	var h Header
+
+	parts := strings.Split(jwe, ".")
+	if len(parts) != 5 {
+		return h, nil, fmt.Errorf("%w: malformed JWE", ErrDecryption)
+	}
+
+	hb, err := b64.DecodeString(parts[0])
+	if err == nil {
+		err = json.Unmarshal(hb, &h)
+	}
+	if err != nil {
+		return h, nil, fmt.Errorf("%w: malformed header: %v", ErrDecryption, err)
+	}
+	if len(h.Critical) > 0 || h.Compression != "" {
+		return h, nil, fmt.Errorf("%w: unsupported header parameters", ErrDecryption)
+	}
+	size, ok := h.Encryption.keySize()
+	if !ok {
+		return h, nil, fmt.Errorf("%w: unsupported content encryption %q", ErrDecryption, h.Encryption)
+	}
+
+	var decoded [4][]byte
+	for i, p := range parts[1:] {
+		if decoded[i], err = b64.DecodeString(p); err != nil {
+			return h, nil, fmt.Errorf("%w: malformed JWE: %v", ErrDecryption, err)
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := decoded[0], decoded[1], decoded[2], decoded[3]
+
+	for _, k := range keys {
+		if k.Algorithm != h.Algorithm || (h.KeyID != "" && k.ID != h.KeyID) {
+			continue
+		}
+
+		cek, ok := contentKey(k, encryptedKey, size)
+		if !ok {
+			continue
+		}
+
+		gcm, err := newGCM(cek)
+		if err != nil || len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+			continue
+		}
+
+		plaintext, err := gcm.Open(nil, iv, append(ciphertext[:len(ciphertext):len(ciphertext)], tag...), []byte(parts[0]))
+		if err == nil {
+			return h, plaintext, nil
+		}
+	}
+
+	return h, nil, fmt.Errorf("%w: no matching key for alg %q and kid %q", ErrDecryption, h.Algorithm, h.KeyID)
+}
+
+// contentKey returns the content encryption key of size bytes determined by
+// k and encryptedKey.
+func contentKey(k Key, encryptedKey []byte, size int) ([]byte, bool) {
+	switch k.Algorithm {
+	case Direct:
+		cek, ok := k.Key.([]byte)
+		return cek, ok && len(encryptedKey) == 0 && len(cek) == size
+
+	case RSAOAEP, RSAOAEP256:
+		priv, ok := k.Key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, false
+		}
+		cek, err := rsa.DecryptOAEP(oaepHash(k.Algorithm), nil, priv, encryptedKey, nil)
+		return cek, err == nil && len(cek) == size
+	}
+
+	return nil, false
+}
+
+// oaepHash returns the hash used by the RSA-OAEP algorithm alg.
+func oaepHash(alg Algorithm) hash.Hash {
+	if alg == RSAOAEP256 {
+		return sha256.New()
+	}
+	return sha1.New()
+}
+
+func newGCM(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptRequest creates a RequestInterceptorOption replacing request bodies
+// with a JWE in compact serialization encrypted for key using enc. The
+// original content type is sent as the cty header parameter and the request
+// is sent with a Content-Type of application/jose. Requests without a body
+// are sent as is. The interceptor runs in httpclient.RequestPhaseAuth; give
+// SignRequest before EncryptRequest to sign the body and encrypt the
+// resulting JWS.
+func EncryptRequest(key Key, enc Encryption) httpclient.RequestInterceptorOption {
+	return httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, func(r *http.Request) (*http.Request, error) {
+		if r.Body == nil || r.Body == http.NoBody {
+			return r, nil
+		}
+
+		plaintext, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return r, err
+		}
+
+		jwe, err := encrypt(plaintext, key, enc, r.Header.Get("Content-Type"))
+		if err != nil {
+			return r, err
+		}
+
+		r.Header.Set("Content-Type", ContentType)
+		setBody(r, []byte(jwe))
+		return r, nil
+	})
+}
+
+// DecryptResponse creates a ResponseInterceptorOption decrypting responses
+// with a Content-Type of application/jose carrying a JWE in compact
+// serialization using one of keys. The body is replaced with the plaintext
+// and the Content-Type with the one given by the cty header parameter,
+// defaulting to application/json. Other responses are passed on unchanged,
+// so give ExpectedStatusCode or VerifyResponse to reject unencrypted
+// responses. A JWE that can't be decrypted is reported as an
+// *httpclient.Error of kind httpclient.ErrDecode wrapping ErrDecryption.
+//
+// The interceptor runs in httpclient.PhasePreValidate; give DecryptResponse
+// before VerifyResponse to verify a JWS nested in the JWE.
+func DecryptResponse(keys []Key) httpclient.ResponseInterceptorOption {
+	return httpclient.InPhaseFunc(httpclient.PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		if ct, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); !strings.EqualFold(strings.TrimSpace(ct), ContentType) {
+			return r, nil
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return r, err
+		}
+		body = bytes.TrimSpace(body)
+
+		if bytes.Count(body, []byte(".")) != 4 {
+			// A JWS, which is left to VerifyResponse.
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			return r, nil
+		}
+
+		h, plaintext, err := decrypt(string(body), keys)
+		if err != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			return r, httpclient.DecodeError(r, err)
+		}
+
+		ct := h.mediaType()
+		if ct == "" {
+			ct = "application/json"
+		}
+		r.Header.Set("Content-Type", ct)
+		r.Header.Set("Content-Length", strconv.Itoa(len(plaintext)))
+		r.ContentLength = int64(len(plaintext))
+		r.Body = io.NopCloser(bytes.NewReader(plaintext))
+
+		return r, nil
+	})
+}
@@ -0,0 +1,115 @@
+package jose_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/jose"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	ExpectThat(t, err).Is(NoError())
+
+	pub, err := jose.ParseKey([]byte(rsaJWK(rsaKey, false)))
+	ExpectThat(t, err).Is(NoError())
+	priv, err := jose.ParseKey([]byte(rsaJWK(rsaKey, true)))
+	ExpectThat(t, err).Is(NoError())
+
+	direct := jose.Key{Algorithm: jose.Direct, Key: []byte("0123456789abcdef0123456789abcdef")}
+
+	tests := []struct {
+		name    string
+		encrypt jose.Key
+		decrypt jose.Key
+		enc     jose.Encryption
+	}{
+		{"rsaOAEP256", pub, priv, jose.A256GCM},
+		{"rsaOAEP", jose.Key{Algorithm: jose.RSAOAEP, Key: &rsaKey.PublicKey}, jose.Key{Algorithm: jose.RSAOAEP, Key: rsaKey}, jose.A128GCM},
+		{"dir", direct, direct, jose.A256GCM},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jwe, err := jose.Encrypt([]byte("secret"), test.encrypt, test.enc)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, strings.Count(jwe, ".")).Is(Equal(4))
+
+			plaintext, err := jose.Decrypt(jwe, []jose.Key{test.decrypt})
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, string(plaintext)).Is(Equal("secret"))
+
+			parts := strings.Split(jwe, ".")
+			parts[3] = "AAAA" + parts[3][4:]
+			_, err = jose.Decrypt(strings.Join(parts, "."), []jose.Key{test.decrypt})
+			ExpectThat(t, err).Is(Error(jose.ErrDecryption))
+		})
+	}
+
+	t.Run("invalidDirectKey", func(t *testing.T) {
+		_, err := jose.Encrypt([]byte("secret"), direct, jose.A128GCM)
+		ExpectThat(t, err).Is(NotNil())
+	})
+}
+
+func TestEncryptRequestDecryptResponse(t *testing.T) {
+	clientKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	ExpectThat(t, err).Is(NoError())
+	serverKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	ExpectThat(t, err).Is(NoError())
+
+	signing := jose.Key{Algorithm: jose.HS256, Key: []byte("secret")}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		jws, err := jose.Decrypt(string(body), []jose.Key{{Algorithm: jose.RSAOAEP256, Key: serverKey}})
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payload, err := jose.Verify(string(jws), []jose.Key{signing})
+		if err != nil || string(payload) != `{"pin":"1234"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		jwe, _ := jose.Encrypt([]byte(`{"ok":true}`), jose.Key{Algorithm: jose.RSAOAEP256, Key: &clientKey.PublicKey}, jose.A256GCM)
+		w.Header().Set("Content-Type", jose.ContentType)
+		w.Write([]byte(jwe))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	var res struct {
+		OK bool `json:"ok"`
+	}
+	_, err = client.Post(context.Background(), "/",
+		httpclient.WithJSON(map[string]string{"pin": "1234"}),
+		jose.SignRequest(signing),
+		jose.EncryptRequest(jose.Key{Algorithm: jose.RSAOAEP256, Key: &serverKey.PublicKey}, jose.A256GCM),
+		jose.DecryptResponse([]jose.Key{{Algorithm: jose.RSAOAEP256, Key: clientKey}}),
+		httpclient.ForJSON(&res),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.OK).Is(Equal(true))
+
+	_, err = client.Post(context.Background(), "/",
+		httpclient.WithJSON(map[string]string{"pin": "1234"}),
+		jose.SignRequest(signing),
+		jose.EncryptRequest(jose.Key{Algorithm: jose.RSAOAEP256, Key: &serverKey.PublicKey}, jose.A256GCM),
+		jose.DecryptResponse([]jose.Key{{Algorithm: jose.RSAOAEP256, Key: serverKey}}),
+	)
+	ExpectThat(t, err).Is(Error(jose.ErrDecryption))
+}
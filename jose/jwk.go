@@ -0,0 +1,175 @@
+package jose
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// jwk is the JSON representation of a JSON Web Key.
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	D string `json:"d"`
+	P string `json:"p"`
+	Q string `json:"q"`
+
+	// EC
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+
+	// oct
+	K string `json:"k"`
+}
+
+// ParseKey parses a JSON Web Key as defined by RFC 7517. RSA, EC (P-256,
+// P-384 and P-521) and symmetric (oct) keys are supported, both public and
+// private. The key's ID and Algorithm are taken from the kid and alg
+// members; set Algorithm yourself if the JWK doesn't carry it.
+func ParseKey(b []byte) (Key, error) {
+	var k jwk
+	if err := json.Unmarshal(b, &k); err != nil {
+		return Key{}, fmt.Errorf("jose: invalid JWK: %w", err)
+	}
+	return k.key()
+}
+
+// ParseKeySet parses a JSON Web Key Set as defined by RFC 7517, i.e. as
+// published by a server to verify its signatures.
+func ParseKeySet(b []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("jose: invalid JWK set: %w", err)
+	}
+
+	keys := make([]Key, len(set.Keys))
+	for i, k := range set.Keys {
+		key, err := k.key()
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) key() (Key, error) {
+	key := Key{ID: k.KeyID, Algorithm: Algorithm(k.Algorithm)}
+
+	var err error
+	switch k.KeyType {
+	case "RSA":
+		key.Key, err = k.rsaKey()
+	case "EC":
+		key.Key, err = k.ecKey()
+	case "oct":
+		key.Key, err = b64.DecodeString(k.K)
+	default:
+		err = fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("jose: invalid JWK %q: %w", k.KeyID, err)
+	}
+
+	return key, nil
+}
+
+func (k jwk) rsaKey() (any, error) {
+	n, err := bigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := bigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	pub := rsa.PublicKey{N: n, E: int(e.Int64())}
+	if k.D == "" {
+		return &pub, nil
+	}
+
+	priv := &rsa.PrivateKey{PublicKey: pub}
+	if priv.D, err = bigInt(k.D); err != nil {
+		return nil, err
+	}
+	for _, p := range []string{k.P, k.Q} {
+		prime, err := bigInt(p)
+		if err != nil {
+			return nil, err
+		}
+		priv.Primes = append(priv.Primes, prime)
+	}
+
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+	priv.Precompute()
+
+	return priv, nil
+}
+
+func (k jwk) ecKey() (any, error) {
+	var curve elliptic.Curve
+	switch k.Curve {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+	}
+
+	x, err := bigInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := bigInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point not on curve")
+	}
+
+	pub := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	if k.D == "" {
+		return &pub, nil
+	}
+
+	d, err := bigInt(k.D)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PrivateKey{PublicKey: pub, D: d}, nil
+}
+
+// bigInt decodes the base64url encoded big-endian integer s.
+func bigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("missing key parameter")
+	}
+	b, err := b64.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
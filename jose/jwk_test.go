@@ -0,0 +1,69 @@
+package jose_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient/jose"
+)
+
+func b64(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func rsaJWK(k *rsa.PrivateKey, private bool) string {
+	s := fmt.Sprintf(`{"kty":"RSA","kid":"rsa-1","alg":"RSA-OAEP-256","n":%q,"e":%q`, b64(k.N), b64(big.NewInt(int64(k.E))))
+	if private {
+		s += fmt.Sprintf(`,"d":%q,"p":%q,"q":%q`, b64(k.D), b64(k.Primes[0]), b64(k.Primes[1]))
+	}
+	return s + "}"
+}
+
+func TestParseKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	ExpectThat(t, err).Is(NoError())
+
+	t.Run("rsa", func(t *testing.T) {
+		pub, err := jose.ParseKey([]byte(rsaJWK(rsaKey, false)))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, pub.ID).Is(Equal("rsa-1"))
+		ExpectThat(t, pub.Algorithm).Is(Equal(jose.RSAOAEP256))
+		ExpectThat(t, pub.Key.(*rsa.PublicKey).Equal(&rsaKey.PublicKey)).Is(Equal(true))
+
+		priv, err := jose.ParseKey([]byte(rsaJWK(rsaKey, true)))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, priv.Key.(*rsa.PrivateKey).Equal(rsaKey)).Is(Equal(true))
+	})
+
+	t.Run("ec", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		ExpectThat(t, err).Is(NoError())
+
+		key, err := jose.ParseKey([]byte(fmt.Sprintf(`{"kty":"EC","crv":"P-256","x":%q,"y":%q,"d":%q}`,
+			b64(ecKey.X), b64(ecKey.Y), b64(ecKey.D))))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, key.Key.(*ecdsa.PrivateKey).Equal(ecKey)).Is(Equal(true))
+
+		_, err = jose.ParseKey([]byte(fmt.Sprintf(`{"kty":"EC","crv":"P-256","x":%q,"y":%q}`, b64(ecKey.X), b64(ecKey.X))))
+		ExpectThat(t, err).Is(NotNil())
+	})
+
+	t.Run("set", func(t *testing.T) {
+		keys, err := jose.ParseKeySet([]byte(`{"keys":[{"kty":"oct","kid":"s","alg":"HS256","k":"c2VjcmV0"},` + rsaJWK(rsaKey, false) + `]}`))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, len(keys)).Is(Equal(2))
+		ExpectThat(t, keys[0]).Is(DeepEqual(jose.Key{ID: "s", Algorithm: jose.HS256, Key: []byte("secret")}))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := jose.ParseKey([]byte(`{"kty":"OKP"}`))
+		ExpectThat(t, err).Is(NotNil())
+	})
+}
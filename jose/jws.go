@@ -0,0 +1,426 @@
+package jose
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/halimath/httpclient"
+)
+
+// Algorithm is an algorithm as registered for the alg header parameter by
+// RFC 7518: either a JWS algorithm or, for JWE, a key management algorithm.
+type Algorithm string
+
+// JWS algorithms.
+const (
+	HS256 Algorithm = "HS256"
+	HS384 Algorithm = "HS384"
+	HS512 Algorithm = "HS512"
+	RS256 Algorithm = "RS256"
+	RS384 Algorithm = "RS384"
+	RS512 Algorithm = "RS512"
+	PS256 Algorithm = "PS256"
+	PS384 Algorithm = "PS384"
+	PS512 Algorithm = "PS512"
+	ES256 Algorithm = "ES256"
+	ES384 Algorithm = "ES384"
+	ES512 Algorithm = "ES512"
+)
+
+// hash returns the hash function used by a.
+func (a Algorithm) hash() (crypto.Hash, bool) {
+	if len(a) != 5 {
+		return 0, false
+	}
+	switch a[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// curve returns the curve used by the ECDSA algorithm a.
+func (a Algorithm) curve() elliptic.Curve {
+	switch a {
+	case ES256:
+		return elliptic.P256()
+	case ES384:
+		return elliptic.P384()
+	case ES512:
+		return elliptic.P521()
+	}
+	return nil
+}
+
+// Sign signs payload using key and returns the JWS in compact serialization.
+func Sign(payload []byte, key Key) (string, error) {
+	return SignHeader(payload, key, Header{})
+}
+
+// SignHeader is like Sign but uses h as the protected header, i.e. to create
+// a JWT with a typ or x5t header parameter. The alg header parameter is
+// always set to key's Algorithm and kid defaults to key's ID.
+func SignHeader(payload []byte, key Key, h Header) (string, error) {
+	h.Algorithm = key.Algorithm
+	if h.KeyID == "" {
+		h.KeyID = key.ID
+	}
+
+	hb, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	input := b64.EncodeToString(hb) + "." + b64.EncodeToString(payload)
+	sig, err := signInput(key, []byte(input))
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + b64.EncodeToString(sig), nil
+}
+
+// SignDetached signs payload using key and returns the JWS in compact
+// serialization with the payload omitted as described in RFC 7515, Appendix
+// F. The payload has to be transferred separately.
+func SignDetached(payload []byte, key Key) (string, error) {
+	jws, err := Sign(payload, key)
+	if err != nil {
+		return "", err
+	}
+	h, _, _ := strings.Cut(jws, ".")
+	return h + ".." + jws[strings.LastIndexByte(jws, '.')+1:], nil
+}
+
+// Verify verifies the JWS in compact serialization using one of keys and
+// returns its payload.
+func Verify(jws string, keys []Key) ([]byte, error) {
+	_, payload, err := verify(jws, nil, keys)
+	return payload, err
+}
+
+// VerifyDetached verifies the detached JWS in compact serialization for
+// payload using one of keys.
+func VerifyDetached(jws string, payload []byte, keys []Key) error {
+	_, _, err := verify(jws, payload, keys)
+	return err
+}
+
+// sign signs payload using key with contentType as the cty header
+// parameter.
+func sign(payload []byte, key Key, contentType string) (string, error) {
+	return SignHeader(payload, key, Header{ContentType: contentType})
+}
+
+// verify verifies jws using one of keys and returns its header and payload.
+// If detached is not nil, jws must be a detached JWS for the payload
+// detached.
+func verify(jws string, detached []byte, keys []Key) (Header, []byte, error) {
+	var h Header
+
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return h, nil, fmt.Errorf("%w: malformed JWS", ErrInvalidSignature)
+	}
+
+	hb, err := b64.DecodeString(parts[0])
+	if err == nil {
+		err = json.Unmarshal(hb, &h)
+	}
+	if err != nil {
+		return h, nil, fmt.Errorf("%w: malformed header: %v", ErrInvalidSignature, err)
+	}
+	if len(h.Critical) > 0 {
+		return h, nil, fmt.Errorf("%w: unsupported critical header parameters %v", ErrInvalidSignature, h.Critical)
+	}
+
+	payload := detached
+	if detached == nil {
+		payload, err = b64.DecodeString(parts[1])
+		if err != nil {
+			return h, nil, fmt.Errorf("%w: malformed payload: %v", ErrInvalidSignature, err)
+		}
+	} else if parts[1] != "" {
+		return h, nil, fmt.Errorf("%w: expected detached JWS", ErrInvalidSignature)
+	} else {
+		parts[1] = b64.EncodeToString(detached)
+	}
+
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return h, nil, fmt.Errorf("%w: malformed signature: %v", ErrInvalidSignature, err)
+	}
+
+	input := []byte(parts[0] + "." + parts[1])
+	for _, k := range keys {
+		if k.Algorithm != h.Algorithm || (h.KeyID != "" && k.ID != h.KeyID) {
+			continue
+		}
+		if verifyInput(k, input, sig) == nil {
+			return h, payload, nil
+		}
+	}
+
+	return h, nil, fmt.Errorf("%w: no matching key for alg %q and kid %q", ErrInvalidSignature, h.Algorithm, h.KeyID)
+}
+
+// signInput computes the signature of input using key.
+func signInput(key Key, input []byte) ([]byte, error) {
+	hash, ok := key.Algorithm.hash()
+	if !ok {
+		return nil, fmt.Errorf("jose: unsupported algorithm %q", key.Algorithm)
+	}
+
+	switch key.Algorithm[0] {
+	case 'H':
+		secret, ok := key.Key.([]byte)
+		if !ok {
+			break
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+
+	case 'R', 'P':
+		priv, ok := key.Key.(*rsa.PrivateKey)
+		if !ok {
+			break
+		}
+		digest := digest(hash, input)
+		if key.Algorithm[0] == 'P' {
+			return rsa.SignPSS(rand.Reader, priv, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, hash, digest)
+
+	case 'E':
+		priv, ok := key.Key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve != key.Algorithm.curve() {
+			break
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest(hash, input))
+		if err != nil {
+			return nil, err
+		}
+		size := (priv.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	}
+
+	return nil, fmt.Errorf("jose: key of type %T can't be used with %s", key.Key, key.Algorithm)
+}
+
+// verifyInput verifies sig for input using key.
+func verifyInput(key Key, input, sig []byte) error {
+	hash, ok := key.Algorithm.hash()
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	switch key.Algorithm[0] {
+	case 'H':
+		expected, err := signInput(key, input)
+		if err != nil {
+			return err
+		}
+		if hmac.Equal(expected, sig) {
+			return nil
+		}
+
+	case 'R', 'P':
+		var pub *rsa.PublicKey
+		switch k := key.Key.(type) {
+		case *rsa.PublicKey:
+			pub = k
+		case *rsa.PrivateKey:
+			pub = &k.PublicKey
+		default:
+			return ErrInvalidSignature
+		}
+		if key.Algorithm[0] == 'P' {
+			return rsa.VerifyPSS(pub, hash, digest(hash, input), sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest(hash, input), sig)
+
+	case 'E':
+		var pub *ecdsa.PublicKey
+		switch k := key.Key.(type) {
+		case *ecdsa.PublicKey:
+			pub = k
+		case *ecdsa.PrivateKey:
+			pub = &k.PublicKey
+		default:
+			return ErrInvalidSignature
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if pub.Curve != key.Algorithm.curve() || len(sig) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if ecdsa.Verify(pub, digest(hash, input), r, s) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func digest(hash crypto.Hash, input []byte) []byte {
+	h := hash.New()
+	h.Write(input)
+	return h.Sum(nil)
+}
+
+// Option customizes SignRequest and VerifyResponse.
+type Option func(*config)
+
+type config struct {
+	header string
+}
+
+// Detached makes SignRequest send a detached JWS in header, i.e.
+// "x-jws-signature", leaving the body unchanged, and VerifyResponse verify
+// the detached JWS sent in header for the response body.
+func Detached(header string) Option {
+	return func(c *config) {
+		c.header = header
+	}
+}
+
+func newConfig(opts []Option) config {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// SignRequest creates a RequestInterceptorOption signing request bodies
+// using key. By default the body is replaced with a JWS in compact
+// serialization carrying the original content type as the cty header
+// parameter and sent with a Content-Type of application/jose. Use Detached
+// to send the signature in a header instead. Requests without a body are not
+// signed. The interceptor runs in httpclient.RequestPhaseAuth, so it signs
+// the body as it is sent.
+func SignRequest(key Key, opts ...Option) httpclient.RequestInterceptorOption {
+	cfg := newConfig(opts)
+
+	return httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, func(r *http.Request) (*http.Request, error) {
+		if r.Body == nil || r.Body == http.NoBody {
+			return r, nil
+		}
+
+		payload, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return r, err
+		}
+
+		if cfg.header != "" {
+			jws, err := SignDetached(payload, key)
+			if err != nil {
+				return r, err
+			}
+			r.Header.Set(cfg.header, jws)
+			setBody(r, payload)
+			return r, nil
+		}
+
+		jws, err := sign(payload, key, r.Header.Get("Content-Type"))
+		if err != nil {
+			return r, err
+		}
+		r.Header.Set("Content-Type", ContentType)
+		setBody(r, []byte(jws))
+		return r, nil
+	})
+}
+
+// setBody replaces the body of r with b.
+func setBody(r *http.Request, b []byte) {
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	r.ContentLength = int64(len(b))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+}
+
+// VerifyResponse creates a ResponseInterceptorOption verifying the
+// signatures of successful responses using keys. By default responses must
+// have a Content-Type of application/jose; the body is replaced with the
+// verified payload and the Content-Type with the one given by the cty header
+// parameter, defaulting to application/json. Use Detached to verify a
+// detached JWS sent in a header instead, leaving the body unchanged.
+//
+// Responses with a status code other than 2xx as well as 204 No Content
+// responses are not verified. A missing or invalid signature is reported as
+// an *httpclient.Error of kind httpclient.ErrDecode wrapping
+// ErrInvalidSignature. The interceptor runs in httpclient.PhasePreValidate,
+// so decoders only ever see verified payloads.
+func VerifyResponse(keys []Key, opts ...Option) httpclient.ResponseInterceptorOption {
+	cfg := newConfig(opts)
+
+	return httpclient.InPhaseFunc(httpclient.PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		if r.StatusCode < 200 || r.StatusCode > 299 || r.StatusCode == http.StatusNoContent {
+			return r, nil
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return r, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if cfg.header != "" {
+			jws := r.Header.Get(cfg.header)
+			if jws == "" {
+				return r, httpclient.DecodeError(r, fmt.Errorf("%w: missing %s header", ErrInvalidSignature, cfg.header))
+			}
+			if err := VerifyDetached(jws, body, keys); err != nil {
+				return r, httpclient.DecodeError(r, err)
+			}
+			return r, nil
+		}
+
+		if ct, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); !strings.EqualFold(strings.TrimSpace(ct), ContentType) {
+			return r, httpclient.DecodeError(r, fmt.Errorf("%w: expected %s response but got %q", ErrInvalidSignature, ContentType, r.Header.Get("Content-Type")))
+		}
+
+		h, payload, err := verify(string(bytes.TrimSpace(body)), nil, keys)
+		if err != nil {
+			return r, httpclient.DecodeError(r, err)
+		}
+
+		ct := h.mediaType()
+		if ct == "" {
+			ct = "application/json"
+		}
+		r.Header.Set("Content-Type", ct)
+		r.Header.Set("Content-Length", strconv.Itoa(len(payload)))
+		r.ContentLength = int64(len(payload))
+		r.Body = io.NopCloser(bytes.NewReader(payload))
+
+		return r, nil
+	})
+}
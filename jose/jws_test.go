@@ -0,0 +1,144 @@
+package jose_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/jose"
+)
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	ExpectThat(t, err).Is(NoError())
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ExpectThat(t, err).Is(NoError())
+
+	keys := []jose.Key{
+		{ID: "hmac", Algorithm: jose.HS256, Key: []byte("secret")},
+		{ID: "rsa", Algorithm: jose.RS256, Key: rsaKey},
+		{ID: "pss", Algorithm: jose.PS384, Key: rsaKey},
+		{ID: "ec", Algorithm: jose.ES256, Key: ecKey},
+	}
+	public := []jose.Key{
+		keys[0],
+		{ID: "rsa", Algorithm: jose.RS256, Key: &rsaKey.PublicKey},
+		{ID: "pss", Algorithm: jose.PS384, Key: &rsaKey.PublicKey},
+		{ID: "ec", Algorithm: jose.ES256, Key: &ecKey.PublicKey},
+	}
+
+	for _, key := range keys {
+		t.Run(string(key.Algorithm), func(t *testing.T) {
+			jws, err := jose.Sign([]byte("payload"), key)
+			ExpectThat(t, err).Is(NoError())
+
+			payload, err := jose.Verify(jws, public)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, string(payload)).Is(Equal("payload"))
+
+			detached, err := jose.SignDetached([]byte("payload"), key)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, strings.Count(detached, "..")).Is(Equal(1))
+			ExpectThat(t, jose.VerifyDetached(detached, []byte("payload"), public)).Is(NoError())
+			ExpectThat(t, jose.VerifyDetached(detached, []byte("tampered"), public)).Is(Error(jose.ErrInvalidSignature))
+		})
+	}
+
+	t.Run("none", func(t *testing.T) {
+		jws := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte("payload")) + "."
+		_, err := jose.Verify(jws, public)
+		ExpectThat(t, err).Is(Error(jose.ErrInvalidSignature))
+	})
+
+	t.Run("algorithmMismatch", func(t *testing.T) {
+		jws, err := jose.Sign([]byte("payload"), jose.Key{Algorithm: jose.HS256, Key: []byte("secret")})
+		ExpectThat(t, err).Is(NoError())
+		_, err = jose.Verify(jws, public[1:])
+		ExpectThat(t, err).Is(Error(jose.ErrInvalidSignature))
+	})
+}
+
+func TestSignRequestVerifyResponse(t *testing.T) {
+	key := jose.Key{ID: "k1", Algorithm: jose.HS256, Key: []byte("secret")}
+	keys := []jose.Key{key}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if r.URL.Path == "/detached" {
+			if err := jose.VerifyDetached(r.Header.Get("X-JWS-Signature"), body, keys); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			jws, _ := jose.SignDetached([]byte(`{"ok":true}`), key)
+			w.Header().Set("X-JWS-Signature", jws)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+
+		payload, err := jose.Verify(string(body), keys)
+		if err != nil || r.Header.Get("Content-Type") != jose.ContentType || string(payload) != `{"amount":10}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Path == "/unsigned" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+
+		jws, _ := jose.Sign([]byte(`{"ok":true}`), key)
+		w.Header().Set("Content-Type", jose.ContentType)
+		w.Write([]byte(jws))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	var res struct {
+		OK bool `json:"ok"`
+	}
+
+	_, err := client.Post(context.Background(), "/compact",
+		httpclient.WithJSON(map[string]int{"amount": 10}),
+		jose.SignRequest(key),
+		jose.VerifyResponse(keys),
+		httpclient.ForJSON(&res),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.OK).Is(Equal(true))
+
+	res.OK = false
+	_, err = client.Post(context.Background(), "/detached",
+		httpclient.WithJSON(map[string]int{"amount": 10}),
+		jose.SignRequest(key, jose.Detached("X-JWS-Signature")),
+		jose.VerifyResponse(keys, jose.Detached("X-JWS-Signature")),
+		httpclient.ForJSON(&res),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.OK).Is(Equal(true))
+
+	_, err = client.Post(context.Background(), "/unsigned",
+		httpclient.WithJSON(map[string]int{"amount": 10}),
+		jose.SignRequest(key),
+		jose.VerifyResponse(keys),
+	)
+	ExpectThat(t, err).Is(Error(jose.ErrInvalidSignature))
+	ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+}
@@ -0,0 +1,192 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// JSONRPCError is the error object of a JSON-RPC 2.0 response. It is
+// returned by JSONRPCClient.Call for responses reporting an error.
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("jsonrpc: %d: %s", e.Code, e.Message)
+}
+
+// JSONRPCCall is a single call sent as part of a batch using
+// JSONRPCClient.Batch.
+type JSONRPCCall struct {
+	// Method and Params describe the call. Params is omitted if nil.
+	Method string
+	Params any
+
+	// Result receives the result of the call. A nil Result sends the call
+	// as a notification, to which the server sends no response.
+	Result any
+
+	// Err is set by Batch if the server responded to the call with an
+	// error, in which case it is a *JSONRPCError, or if the result can't be
+	// decoded.
+	Err error
+}
+
+// JSONRPCClient sends JSON-RPC 2.0 requests to a single endpoint using a
+// Client. Request IDs are assigned from a counter, so a JSONRPCClient is safe
+// for concurrent use.
+type JSONRPCClient struct {
+	c   *Client
+	url string
+	id  atomic.Int64
+}
+
+// NewJSONRPCClient creates a JSONRPCClient sending requests to url using c.
+// url may be relative if c uses WithURLPrefix.
+func NewJSONRPCClient(c *Client, url string) *JSONRPCClient {
+	return &JSONRPCClient{c: c, url: url}
+}
+
+type jsonRPCRequest struct {
+	Version string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+	ID      *int64 `json:"id,omitempty"`
+}
+
+type jsonRPCResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+func (j *JSONRPCClient) request(method string, params any, notification bool) jsonRPCRequest {
+	req := jsonRPCRequest{Version: "2.0", Method: method, Params: params}
+	if !notification {
+		id := j.id.Add(1)
+		req.ID = &id
+	}
+	return req
+}
+
+// Call calls method with params and decodes the call's result into result.
+// If the server responds with an error object, a *JSONRPCError is returned.
+// opts are applied to the HTTP request.
+func (j *JSONRPCClient) Call(ctx context.Context, method string, params, result any, opts ...RequestOption) error {
+	var res jsonRPCResponse
+	_, err := j.c.Post(ctx, j.url, append(opts[:len(opts):len(opts)],
+		WithJSON(j.request(method, params, false)),
+		ForJSON(&res),
+	)...)
+	if err != nil {
+		return err
+	}
+
+	return j.decodeResult(res, result)
+}
+
+// Notify sends method with params as a notification, to which the server
+// sends no response.
+func (j *JSONRPCClient) Notify(ctx context.Context, method string, params any, opts ...RequestOption) error {
+	_, err := j.c.Post(ctx, j.url, append(opts[:len(opts):len(opts)], WithJSON(j.request(method, params, true)))...)
+	return err
+}
+
+// Batch sends calls as a single batch request. The results and errors of
+// the individual calls are stored in their Result and Err fields. The
+// returned error reports failures of the batch as a whole, such as network
+// errors or responses missing for calls that are no notifications. If the
+// server rejects the batch with a single error object instead of an array of
+// responses, that error is returned as a *JSONRPCError.
+func (j *JSONRPCClient) Batch(ctx context.Context, calls []*JSONRPCCall, opts ...RequestOption) error {
+	reqs := make([]jsonRPCRequest, len(calls))
+	pending := make(map[int64]*JSONRPCCall)
+	for i, call := range calls {
+		reqs[i] = j.request(call.Method, call.Params, call.Result == nil)
+		if reqs[i].ID != nil {
+			pending[*reqs[i].ID] = call
+		}
+	}
+
+	if len(pending) == 0 {
+		_, err := j.c.Post(ctx, j.url, append(opts[:len(opts):len(opts)], WithJSON(reqs))...)
+		return err
+	}
+
+	var raw json.RawMessage
+	if _, err := j.c.Post(ctx, j.url, append(opts[:len(opts):len(opts)], WithJSON(reqs), ForJSON(&raw))...); err != nil {
+		return err
+	}
+
+	if b := bytes.TrimSpace(raw); len(b) > 0 && b[0] == '{' {
+		var r jsonRPCResponse
+		if err := j.unmarshal(b, &r); err != nil {
+			return fmt.Errorf("jsonrpc: %w", err)
+		}
+		if r.Error != nil {
+			return r.Error
+		}
+		return errors.New("jsonrpc: batch answered with a single response")
+	}
+
+	var res []jsonRPCResponse
+	if err := j.unmarshal(raw, &res); err != nil {
+		return fmt.Errorf("jsonrpc: %w", err)
+	}
+
+	for _, r := range res {
+		if r.ID == nil {
+			// Errors not related to a single call, i.e. an invalid
+			// request, are reported without an ID.
+			if r.Error != nil {
+				return r.Error
+			}
+			continue
+		}
+
+		call, ok := pending[*r.ID]
+		if !ok {
+			continue
+		}
+		delete(pending, *r.ID)
+		call.Err = j.decodeResult(r, call.Result)
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("jsonrpc: missing responses for %d calls", len(pending))
+	}
+
+	return nil
+}
+
+var errMissingJSONRPCResult = errors.New("jsonrpc: response contains neither result nor error")
+
+// decodeResult decodes the result of res into result or returns the error
+// reported by res.
+func (j *JSONRPCClient) decodeResult(res jsonRPCResponse, result any) error {
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.Result == nil {
+		return errMissingJSONRPCResult
+	}
+	if result == nil {
+		return nil
+	}
+	return j.unmarshal(res.Result, result)
+}
+
+// unmarshal decodes data into v using the JSON codec configured for j's
+// Client.
+func (j *JSONRPCClient) unmarshal(data []byte, v any) error {
+	if j.c.jsonCodec != nil {
+		return j.c.jsonCodec.unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,157 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestJSONRPCClient(t *testing.T) {
+	type request struct {
+		Version string           `json:"jsonrpc"`
+		Method  string           `json:"method"`
+		Params  []int            `json:"params"`
+		ID      *json.RawMessage `json:"id"`
+	}
+
+	handle := func(req request) map[string]any {
+		if req.ID == nil {
+			return nil
+		}
+		if req.Method != "sum" {
+			return map[string]any{"jsonrpc": "2.0", "id": req.ID, "error": map[string]any{"code": -32601, "message": "Method not found"}}
+		}
+		sum := 0
+		for _, p := range req.Params {
+			sum += p
+		}
+		return map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": sum}
+	}
+
+	var notified []string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		json.NewDecoder(r.Body).Decode(&raw)
+
+		var responses []map[string]any
+		var batch []request
+		if json.Unmarshal(raw, &batch) != nil {
+			var req request
+			json.Unmarshal(raw, &req)
+			batch = []request{req}
+		}
+		for _, req := range batch {
+			if req.ID == nil {
+				notified = append(notified, req.Method)
+			}
+			if res := handle(req); res != nil {
+				responses = append(responses, res)
+			}
+		}
+
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if raw[0] == '[' {
+			json.NewEncoder(w).Encode(responses)
+		} else {
+			json.NewEncoder(w).Encode(responses[0])
+		}
+	}))
+	defer testServer.Close()
+
+	rpc := httpclient.NewJSONRPCClient(httpclient.New(), testServer.URL)
+
+	t.Run("Call", func(t *testing.T) {
+		var sum int
+		err := rpc.Call(context.Background(), "sum", []int{1, 2, 3}, &sum)
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, sum).Is(Equal(6))
+	})
+
+	t.Run("Call error", func(t *testing.T) {
+		var sum int
+		err := rpc.Call(context.Background(), "unknown", nil, &sum)
+
+		var rpcErr *httpclient.JSONRPCError
+		ExpectThat(t, errors.As(err, &rpcErr)).Is(Equal(true))
+		ExpectThat(t, rpcErr.Code).Is(Equal(-32601))
+	})
+
+	t.Run("Notify", func(t *testing.T) {
+		notified = nil
+		err := rpc.Notify(context.Background(), "ping", nil)
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, notified).Is(DeepEqual([]string{"ping"}))
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		notified = nil
+		var a, b int
+		calls := []*httpclient.JSONRPCCall{
+			{Method: "sum", Params: []int{1, 2}, Result: &a},
+			{Method: "log"},
+			{Method: "unknown", Result: &b},
+			{Method: "sum", Params: []int{10, 20}, Result: &b},
+		}
+
+		err := rpc.Batch(context.Background(), calls)
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, a).Is(Equal(3))
+		ExpectThat(t, b).Is(Equal(30))
+		ExpectThat(t, calls[0].Err).Is(NoError())
+		ExpectThat(t, calls[2].Err).Is(NotNil())
+		ExpectThat(t, notified).Is(DeepEqual([]string{"log"}))
+	})
+
+	t.Run("codec", func(t *testing.T) {
+		var results int
+		rpc := httpclient.NewJSONRPCClient(httpclient.New(httpclient.WithJSONCodec(json.Marshal, func(data []byte, v any) error {
+			if _, ok := v.(*int); ok {
+				results++
+			}
+			return json.Unmarshal(data, v)
+		})), testServer.URL)
+
+		var a, b int
+		err := rpc.Call(context.Background(), "sum", []int{1, 2}, &a)
+		ExpectThat(t, err).Is(NoError())
+
+		err = rpc.Batch(context.Background(), []*httpclient.JSONRPCCall{{Method: "sum", Params: []int{3}, Result: &b}})
+		ExpectThat(t, err).Is(NoError())
+
+		ExpectThat(t, a).Is(Equal(3))
+		ExpectThat(t, b).Is(Equal(3))
+		ExpectThat(t, results).Is(Equal(2))
+	})
+}
+
+func TestJSONRPCClient_batchError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid Request"}}`))
+	}))
+	defer testServer.Close()
+
+	rpc := httpclient.NewJSONRPCClient(httpclient.New(), testServer.URL)
+
+	var sum int
+	err := rpc.Batch(context.Background(), []*httpclient.JSONRPCCall{{Method: "sum", Result: &sum}})
+
+	var rpcErr *httpclient.JSONRPCError
+	ExpectThat(t, errors.As(err, &rpcErr)).Is(Equal(true))
+	ExpectThat(t, rpcErr.Code).Is(Equal(-32600))
+}
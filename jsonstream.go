@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// ForJSONStream creates a RequestOption that decodes a response body
+// consisting of a sequence of JSON values, i.e. newline delimited JSON (also
+// known as JSON Lines), and passes each value to f as soon as it has been
+// received. This allows to process log or export APIs streaming huge numbers
+// of records without buffering the response.
+//
+// The option adds an Accept request header accepting application/x-ndjson
+// and expects the response's content type to be either application/x-ndjson,
+// application/jsonl or application/json. Decoding stops if the request's
+// context is canceled, in which case the context's error is returned. Any
+// error returned by f stops decoding and is returned as is. If the response
+// has a different content type or a value can't be decoded, an *Error of
+// kind ErrDecode is returned. The body is consumed afterwards.
+func ForJSONStream(f func(raw json.RawMessage) error) RequestOption {
+	return &forJSONStream{f}
+}
+
+// forJSONStream is the RequestInterceptor and ResponseInterceptor created by
+// ForJSONStream.
+type forJSONStream struct {
+	f func(json.RawMessage) error
+}
+
+func (*forJSONStream) clientOpt() {}
+func (*forJSONStream) reqOpt()    {}
+
+func (*forJSONStream) InterceptRequest(r *http.Request) (*http.Request, error) {
+	r.Header.Add("Accept", "application/x-ndjson")
+	return r, nil
+}
+
+func (s *forJSONStream) InterceptResponse(r *http.Response) (*http.Response, error) {
+	return r, decodeJSONStream(r, s.f)
+}
+
+// decodeJSONStream decodes the JSON values contained in the body of r and
+// passes each of them to f as described for ForJSONStream.
+func decodeJSONStream(r *http.Response, f func(json.RawMessage) error) error {
+	ct := r.Header.Get("Content-Type")
+	if !hasMediaType(ct, []string{"application/x-ndjson", "application/jsonl", "application/json"}) {
+		return newResponseError(ErrDecode, r, fmt.Errorf("expected JSON stream response but got %s", ct))
+	}
+
+	ctx := responseContext(r)
+	dec := json.NewDecoder(r.Body)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return newResponseError(ErrDecode, r, err)
+		}
+
+		if err := f(raw); err != nil {
+			return err
+		}
+	}
+}
+
+// errStopJSONStream is returned to decodeJSONStream by JSONStream once the
+// consumer stopped the iteration.
+var errStopJSONStream = errors.New("stop JSON stream")
+
+// JSONStream creates an iterator over the values of type T decoded from the
+// JSON stream received for a GET request to url, just like ForJSONStream
+// does. The request is sent using c and opts when the iteration starts, so
+// every iteration sends a new request.
+//
+// Values are decoded using the codec configured with WithJSONCodec, if any,
+// in PhasePostValidate and thus only from responses that passed the status
+// validation configured for c or with opts. Any error that
+// occurs while sending the request or decoding a value is yielded as the
+// final element of the iteration with the zero value of T. When the consumer
+// stops the iteration early, i.e. by breaking out of a range loop, the
+// response body is closed and no error is yielded.
+//
+//	for rec, err := range httpclient.JSONStream[Record](ctx, client, "/export") {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+func JSONStream[T any](ctx context.Context, c *Client, url string, opts ...RequestOption) iter.Seq2[T, error] {
+	produce := func(r *http.Response, yield func(T) bool) error {
+		unmarshal := json.Unmarshal
+		if codec := jsonCodecFromContext(responseContext(r)); codec != nil {
+			unmarshal = codec.unmarshal
+		}
+
+		err := decodeJSONStream(r, func(raw json.RawMessage) error {
+			var v T
+			if err := unmarshal(raw, &v); err != nil {
+				return newResponseError(ErrDecode, r, err)
+			}
+			if !yield(v) {
+				return errStopJSONStream
+			}
+			return nil
+		})
+		if err == errStopJSONStream {
+			return nil
+		}
+		return err
+	}
+
+	reqOpts := append(opts[:len(opts):len(opts)], WithRequestInterceptorFunc(new(forJSONStream).InterceptRequest))
+	return Iterate(ctx, c, http.MethodGet, url, produce, reqOpts...)
+}
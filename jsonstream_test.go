@@ -0,0 +1,152 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestForJSONStream(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"id":1}` + "\n"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(`{"id":2}` + "\n"))
+		if r.URL.Path == "/invalid" {
+			w.Write([]byte("{\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("success", func(t *testing.T) {
+		var ids []int
+		_, err := client.Get(context.Background(), "/", httpclient.ForJSONStream(func(raw json.RawMessage) error {
+			var v struct{ ID int }
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			ids = append(ids, v.ID)
+			return nil
+		}))
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, ids).Is(DeepEqual([]int{1, 2}))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var count int
+		_, err := client.Get(ctx, "/", httpclient.ForJSONStream(func(raw json.RawMessage) error {
+			count++
+			cancel()
+			return nil
+		}))
+
+		ExpectThat(t, err).Is(Error(context.Canceled))
+		ExpectThat(t, count).Is(Equal(1))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var count int
+		_, err := client.Get(context.Background(), "/invalid", httpclient.ForJSONStream(func(raw json.RawMessage) error {
+			count++
+			return nil
+		}))
+
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+		ExpectThat(t, count).Is(Equal(2))
+	})
+}
+
+func TestJSONStream(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		switch r.URL.Path {
+		case "/endless":
+			for id := 1; r.Context().Err() == nil; id++ {
+				fmt.Fprintf(w, `{"id":%d}`+"\n", id)
+				w.(http.Flusher).Flush()
+				time.Sleep(time.Millisecond)
+			}
+		case "/invalid":
+			w.Write([]byte(`{"id":1}` + "\n" + `{"id":"two"}` + "\n"))
+		default:
+			w.Write([]byte(`{"id":1}` + "\n" + `{"id":2}` + "\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	type record struct{ ID int }
+
+	t.Run("all", func(t *testing.T) {
+		var ids []int
+		for rec, err := range httpclient.JSONStream[record](context.Background(), client, "/") {
+			ExpectThat(t, err).Is(NoError())
+			ids = append(ids, rec.ID)
+		}
+		ExpectThat(t, ids).Is(DeepEqual([]int{1, 2}))
+	})
+
+	t.Run("stopEarly", func(t *testing.T) {
+		var ids []int
+		for rec, err := range httpclient.JSONStream[record](context.Background(), client, "/endless") {
+			ExpectThat(t, err).Is(NoError())
+			ids = append(ids, rec.ID)
+			if len(ids) == 3 {
+				break
+			}
+		}
+		ExpectThat(t, ids).Is(DeepEqual([]int{1, 2, 3}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var ids []int
+		var lastErr error
+		for rec, err := range httpclient.JSONStream[record](context.Background(), client, "/invalid") {
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			ids = append(ids, rec.ID)
+		}
+		ExpectThat(t, ids).Is(DeepEqual([]int{1}))
+		ExpectThat(t, lastErr).Is(Error(httpclient.ErrDecode))
+	})
+
+	t.Run("codec", func(t *testing.T) {
+		var unmarshaled int
+		client := client.With(httpclient.WithJSONCodec(json.Marshal, func(data []byte, v any) error {
+			unmarshaled++
+			return json.Unmarshal(data, v)
+		}))
+
+		for _, err := range httpclient.JSONStream[record](context.Background(), client, "/") {
+			ExpectThat(t, err).Is(NoError())
+		}
+		ExpectThat(t, unmarshaled).Is(Equal(2))
+	})
+
+	t.Run("loop body panic", func(t *testing.T) {
+		recovered := func() (v any) {
+			defer func() { v = recover() }()
+			for range httpclient.JSONStream[record](context.Background(), client, "/") {
+				panic("user bug")
+			}
+			return nil
+		}()
+		ExpectThat(t, recovered).Is(Equal(any("user bug")))
+	})
+}
@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithMaxResponseBytes creates a ResponseInterceptorOption limiting the size
+// of response bodies to n bytes, protecting against servers sending
+// unexpectedly large bodies. Given as a ClientOption it applies to all
+// requests; given as a RequestOption the limit applies in addition to any
+// client-level limit.
+//
+// Responses announcing a larger body using Content-Length are rejected with
+// an *Error of kind ErrResponseTooLarge right away. Otherwise the body is
+// limited while it is read: once n bytes have been read, reading further
+// fails with an *Error of kind ErrResponseTooLarge, which fails decoders such
+// as ForJSON. The interceptor runs in PhasePreValidate, so the limit applies
+// to all other interceptors.
+func WithMaxResponseBytes(n int64) ResponseInterceptorOption {
+	return InPhaseFunc(PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		if r.ContentLength > n {
+			return r, newResponseError(ErrResponseTooLarge, r, fmt.Errorf("content length %d exceeds %d bytes", r.ContentLength, n))
+		}
+
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &limitedBody{
+				maxSizeReader: maxSizeReader{r: r.Body, n: n},
+				body:          r.Body,
+				res:           r,
+			}
+		}
+
+		return r, nil
+	})
+}
+
+// limitedBody is a response body failing with an *Error of kind
+// ErrResponseTooLarge once more than n bytes are read.
+type limitedBody struct {
+	maxSizeReader
+	body io.Closer
+	res  *http.Response
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.maxSizeReader.Read(p)
+	if l.exceeded {
+		return n, newResponseError(ErrResponseTooLarge, l.res, err)
+	}
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
@@ -0,0 +1,61 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/chunked" {
+			// Flushing before writing the body prevents a Content-Length.
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(`["` + strings.Repeat("a", 100) + `"]`))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL), httpclient.WithMaxResponseBytes(50))
+
+	t.Run("contentLength", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/")
+		ExpectThat(t, err).Is(Error(httpclient.ErrResponseTooLarge))
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		var v []string
+		_, err := client.Get(context.Background(), "/chunked", httpclient.ForJSON(&v))
+		ExpectThat(t, err).Is(Error(httpclient.ErrResponseTooLarge))
+	})
+
+	t.Run("readByCaller", func(t *testing.T) {
+		res, err := client.Fetch(context.Background(), http.MethodGet, "/chunked")
+		ExpectThat(t, err).Is(NoError())
+		defer res.Close()
+
+		b, err := io.ReadAll(res.Body)
+		ExpectThat(t, err).Is(Error(httpclient.ErrResponseTooLarge))
+		ExpectThat(t, len(b)).Is(Equal(50))
+	})
+
+	t.Run("clientLimitApplies", func(t *testing.T) {
+		var v []string
+		_, err := client.Get(context.Background(), "/", httpclient.WithMaxResponseBytes(200), httpclient.ForJSON(&v))
+		ExpectThat(t, err).Is(Error(httpclient.ErrResponseTooLarge))
+	})
+
+	t.Run("withinLimit", func(t *testing.T) {
+		var v []string
+		_, err := httpclient.New(httpclient.WithURLPrefix(testServer.URL)).Get(context.Background(), "/chunked", httpclient.WithMaxResponseBytes(200), httpclient.ForJSON(&v))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, len(v[0])).Is(Equal(100))
+	})
+}
@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Link returns the target of the link with relation type rel given in the
+// Link headers (RFC 8288) of r, i.e. the next page of a paginated
+// collection. Relative targets are resolved against the URL of the request
+// that produced r. Link returns nil if r contains no such link or if its
+// target is malformed.
+func Link(r *http.Response, rel string) *url.URL {
+	for _, header := range r.Header.Values("Link") {
+		for _, link := range splitLinks(header) {
+			target, params, ok := strings.Cut(link, ";")
+			target = strings.TrimSpace(target)
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			if !hasRel(params, rel) {
+				continue
+			}
+
+			u, err := url.Parse(target[1 : len(target)-1])
+			if err != nil {
+				return nil
+			}
+			if r.Request != nil && r.Request.URL != nil {
+				u = r.Request.URL.ResolveReference(u)
+			}
+			return u
+		}
+	}
+
+	return nil
+}
+
+// splitLinks splits the value of a Link header into its links. Commas
+// contained in link targets or quoted parameter values are ignored.
+func splitLinks(header string) []string {
+	var links []string
+	var inTarget, inQuotes bool
+	start := 0
+
+	for i, c := range header {
+		switch {
+		case c == '<' && !inQuotes:
+			inTarget = true
+		case c == '>' && !inQuotes:
+			inTarget = false
+		case c == '"' && !inTarget:
+			inQuotes = !inQuotes
+		case c == ',' && !inTarget && !inQuotes:
+			links = append(links, header[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(links, header[start:])
+}
+
+// hasRel reports whether the link parameters params contain a rel parameter
+// listing rel.
+func hasRel(params, rel string) bool {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+			continue
+		}
+
+		for _, r := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(r, rel) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,321 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNoEndpoint is returned for requests that can't be sent because a
+// LoadBalancer has no endpoint available.
+var ErrNoEndpoint = errors.New("no endpoint available")
+
+// Endpoint is a backend a LoadBalancer distributes requests to.
+type Endpoint struct {
+	// URL is the base URL of the backend, i.e. "http://10.0.0.1:8080/api".
+	// It is used as a prefix for request URLs like in WithURLPrefix.
+	URL string
+
+	// Weight is used by WeightedRoundRobin to send a proportional share of
+	// requests to this endpoint. Values <= 0 are treated as 1.
+	Weight int
+
+	// Priority groups endpoints for failover. Requests are only sent to the
+	// healthy endpoints with the lowest Priority; endpoints with higher values
+	// are used only if none of those is available.
+	Priority int
+
+	inFlight atomic.Int64
+	ejected  atomic.Bool
+}
+
+// InFlight returns the number of requests currently being sent to e.
+func (e *Endpoint) InFlight() int64 {
+	return e.inFlight.Load()
+}
+
+// Healthy reports whether e receives requests, i.e. because it has not been
+// ejected by a health check started with LoadBalancer.CheckHealth.
+func (e *Endpoint) Healthy() bool {
+	return !e.ejected.Load()
+}
+
+func (e *Endpoint) weight() int {
+	return max(e.Weight, 1)
+}
+
+// LoadBalancingStrategy selects the endpoint a request is sent to.
+// Implementations must be safe for concurrent use.
+type LoadBalancingStrategy interface {
+	// Pick returns one of endpoints, which is never empty.
+	Pick(endpoints []*Endpoint) *Endpoint
+}
+
+// RoundRobin creates a LoadBalancingStrategy picking endpoints in turn.
+func RoundRobin() LoadBalancingStrategy {
+	return new(roundRobin)
+}
+
+type roundRobin struct {
+	next atomic.Uint64
+}
+
+func (s *roundRobin) Pick(endpoints []*Endpoint) *Endpoint {
+	return endpoints[(s.next.Add(1)-1)%uint64(len(endpoints))]
+}
+
+// WeightedRoundRobin creates a LoadBalancingStrategy picking endpoints in turn
+// proportionally to their Weight. Picks of the same endpoint are spread
+// evenly instead of being sent in bursts.
+func WeightedRoundRobin() LoadBalancingStrategy {
+	return &weightedRoundRobin{current: make(map[*Endpoint]int)}
+}
+
+type weightedRoundRobin struct {
+	mutex   sync.Mutex
+	current map[*Endpoint]int
+}
+
+// Pick implements the smooth weighted round-robin algorithm used by nginx.
+func (s *weightedRoundRobin) Pick(endpoints []*Endpoint) *Endpoint {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var total int
+	var best *Endpoint
+	for _, e := range endpoints {
+		s.current[e] += e.weight()
+		total += e.weight()
+		if best == nil || s.current[e] > s.current[best] {
+			best = e
+		}
+	}
+
+	s.current[best] -= total
+	return best
+}
+
+// LeastInFlight creates a LoadBalancingStrategy picking the endpoint with the
+// lowest number of in-flight requests. Ties are resolved in turn.
+func LeastInFlight() LoadBalancingStrategy {
+	return new(leastInFlight)
+}
+
+type leastInFlight struct {
+	next atomic.Uint64
+}
+
+func (s *leastInFlight) Pick(endpoints []*Endpoint) *Endpoint {
+	offset := int((s.next.Add(1) - 1) % uint64(len(endpoints)))
+
+	var best *Endpoint
+	for i := range endpoints {
+		e := endpoints[(offset+i)%len(endpoints)]
+		if best == nil || e.InFlight() < best.InFlight() {
+			best = e
+		}
+	}
+	return best
+}
+
+// LoadBalancer distributes requests over a set of endpoints using a
+// LoadBalancingStrategy.
+type LoadBalancer struct {
+	strategy LoadBalancingStrategy
+	srv      *srvSource
+
+	mutex     sync.Mutex
+	endpoints []*Endpoint
+}
+
+// NewLoadBalancer creates a LoadBalancer distributing requests over endpoints
+// using strategy.
+func NewLoadBalancer(strategy LoadBalancingStrategy, endpoints ...*Endpoint) *LoadBalancer {
+	return &LoadBalancer{
+		strategy:  strategy,
+		endpoints: endpoints,
+	}
+}
+
+// Endpoints returns the current endpoints of lb.
+func (lb *LoadBalancer) Endpoints() []*Endpoint {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	return lb.endpoints
+}
+
+// current returns the endpoints of lb, resolving them first if lb is fed by
+// DNS SRV records.
+func (lb *LoadBalancer) current(ctx context.Context) ([]*Endpoint, error) {
+	if lb.srv != nil {
+		return lb.srv.endpoints(ctx, lb)
+	}
+	return lb.Endpoints(), nil
+}
+
+// pick returns the healthy endpoint to send the next request to, skipping
+// the endpoints in exclude. Only endpoints with the lowest Priority among the
+// candidates are considered.
+func (lb *LoadBalancer) pick(ctx context.Context, exclude ...*Endpoint) (*Endpoint, error) {
+	endpoints, err := lb.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*Endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if !e.Healthy() || slices.Contains(exclude, e) {
+			continue
+		}
+		if len(candidates) > 0 {
+			if e.Priority > candidates[0].Priority {
+				continue
+			}
+			if e.Priority < candidates[0].Priority {
+				candidates = candidates[:0]
+			}
+		}
+		candidates = append(candidates, e)
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	return lb.strategy.Pick(candidates), nil
+}
+
+// WithLoadBalancer creates a ClientOption sending requests to the endpoints
+// of lb. Like WithURLPrefix, the URL of the picked endpoint is used as a
+// prefix for requests not starting with either http:// or https://; requests
+// with absolute URLs are sent as is unless lb has been created using
+// NewSRVLoadBalancer and they are sent to the domain of its records. Requests
+// for which no healthy endpoint is available fail with ErrNoEndpoint.
+//
+// If sending a request fails with a connection error, the request is sent to
+// another healthy endpoint, falling back to endpoints with higher Priority
+// values. Requests with a body are only failed over if the body can be
+// recreated using the request's GetBody function. Requests for which
+// RetriesDisabled reports true are not failed over.
+//
+// Requests count as in-flight for their endpoint until the response headers
+// have been received or sending them failed.
+func WithLoadBalancer(lb *LoadBalancer) ClientOption {
+	return &loadBalancerOption{lb}
+}
+
+// balancedKey is the context key used to store the balancedRequest of a
+// request.
+type balancedKey struct{}
+
+// balancedRequest captures the endpoint picked for a request together with
+// the request's URL before it has been rewritten for that endpoint.
+type balancedRequest struct {
+	endpoint *Endpoint
+	url      *url.URL
+}
+
+type loadBalancerOption struct {
+	lb *LoadBalancer
+}
+
+func (*loadBalancerOption) clientOpt() {}
+
+func (o *loadBalancerOption) InterceptRequest(r *http.Request) (*http.Request, error) {
+	if r.URL.IsAbs() && (o.lb.srv == nil || !strings.EqualFold(r.URL.Hostname(), o.lb.srv.domain)) {
+		return r, nil
+	}
+
+	e, err := o.lb.pick(r.Context())
+	if err != nil {
+		return r, err
+	}
+
+	b := &balancedRequest{endpoint: e, url: r.URL}
+	u, err := b.target(e)
+	if err != nil {
+		return r, err
+	}
+	r.URL = u
+	r.Host = ""
+
+	return r.WithContext(context.WithValue(r.Context(), balancedKey{}, b)), nil
+}
+
+// target returns the URL of the request for e.
+func (b *balancedRequest) target(e *Endpoint) (*url.URL, error) {
+	if !b.url.IsAbs() {
+		return url.Parse(e.URL + b.url.String())
+	}
+
+	eu, err := url.Parse(e.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *b.url
+	u.Host = eu.Host
+	return &u, nil
+}
+
+func (o *loadBalancerOption) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	b, ok := r.Context().Value(balancedKey{}).(*balancedRequest)
+	if !ok {
+		return next(r)
+	}
+
+	ctx := r.Context()
+	e := b.endpoint
+	var tried []*Endpoint
+
+	for {
+		e.inFlight.Add(1)
+		res, err := next(r)
+		e.inFlight.Add(-1)
+
+		if err == nil || !IsConnectionError(err) || ctx.Err() != nil || RetriesDisabled(ctx) {
+			return res, err
+		}
+
+		var body io.ReadCloser
+		if r.Body != nil && r.Body != http.NoBody {
+			if r.GetBody == nil {
+				return res, err
+			}
+			var bodyErr error
+			if body, bodyErr = r.GetBody(); bodyErr != nil {
+				return nil, bodyErr
+			}
+		}
+
+		tried = append(tried, e)
+		failover, pickErr := o.lb.pick(ctx, tried...)
+		if pickErr != nil {
+			if body != nil {
+				body.Close()
+			}
+			return res, err
+		}
+
+		u, urlErr := b.target(failover)
+		if urlErr != nil {
+			if body != nil {
+				body.Close()
+			}
+			return nil, urlErr
+		}
+
+		e = failover
+		r = r.Clone(ctx)
+		r.URL = u
+		r.Host = ""
+		if body != nil {
+			r.Body = body
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithLoadBalancer(t *testing.T) {
+	var mutex sync.Mutex
+	hits := make(map[string]int)
+
+	endpoints := make([]*httpclient.Endpoint, 3)
+	for i := range endpoints {
+		name := fmt.Sprintf("backend-%d", i)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			hits[name+r.URL.Path]++
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer s.Close()
+		endpoints[i] = &httpclient.Endpoint{URL: s.URL, Weight: i + 1}
+	}
+
+	send := func(t *testing.T, strategy httpclient.LoadBalancingStrategy, n int) map[string]int {
+		clear(hits)
+		client := httpclient.New(httpclient.WithLoadBalancer(httpclient.NewLoadBalancer(strategy, endpoints...)))
+		for range n {
+			_, err := client.Get(context.Background(), "/x")
+			ExpectThat(t, err).Is(NoError())
+		}
+		return hits
+	}
+
+	t.Run("roundRobin", func(t *testing.T) {
+		ExpectThat(t, send(t, httpclient.RoundRobin(), 6)).Is(DeepEqual(map[string]int{
+			"backend-0/x": 2,
+			"backend-1/x": 2,
+			"backend-2/x": 2,
+		}))
+	})
+
+	t.Run("weightedRoundRobin", func(t *testing.T) {
+		ExpectThat(t, send(t, httpclient.WeightedRoundRobin(), 12)).Is(DeepEqual(map[string]int{
+			"backend-0/x": 2,
+			"backend-1/x": 4,
+			"backend-2/x": 6,
+		}))
+	})
+
+	t.Run("leastInFlight", func(t *testing.T) {
+		ExpectThat(t, send(t, httpclient.LeastInFlight(), 3)).Is(DeepEqual(map[string]int{
+			"backend-0/x": 1,
+			"backend-1/x": 1,
+			"backend-2/x": 1,
+		}))
+
+	})
+
+	t.Run("noEndpoints", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithLoadBalancer(httpclient.NewLoadBalancer(httpclient.RoundRobin())))
+		_, err := client.Get(context.Background(), "/x")
+		ExpectThat(t, err).Is(Error(httpclient.ErrNoEndpoint))
+	})
+}
+
+func TestLeastInFlight(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer slow.Close()
+
+	var fastHits int
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fastHits++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer fast.Close()
+
+	slowEndpoint := &httpclient.Endpoint{URL: slow.URL}
+	client := httpclient.New(httpclient.WithLoadBalancer(httpclient.NewLoadBalancer(
+		httpclient.LeastInFlight(), slowEndpoint, &httpclient.Endpoint{URL: fast.URL},
+	)))
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Get(context.Background(), "/")
+		done <- err
+	}()
+
+	for slowEndpoint.InFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for range 3 {
+		_, err := client.Get(context.Background(), "/")
+		ExpectThat(t, err).Is(NoError())
+	}
+	ExpectThat(t, fastHits).Is(Equal(3))
+
+	close(release)
+	ExpectThat(t, <-done).Is(NoError())
+	ExpectThat(t, slowEndpoint.InFlight()).Is(Equal(int64(0)))
+}
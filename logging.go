@@ -0,0 +1,214 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// defaultRedactedHeaders lists the headers that are always redacted when
+// headers are logged.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// redacted is the value logged in place of a redacted header's value.
+const redacted = "REDACTED"
+
+// LoggingOption customizes the behaviour of the interceptor created by
+// WithLogging.
+type LoggingOption func(*logging)
+
+// LogHeaders enables logging of request and response headers. The values of
+// the headers named in redact are replaced with a placeholder in addition to
+// the values of Authorization, Proxy-Authorization, Cookie and Set-Cookie
+// which are always redacted.
+func LogHeaders(redact ...string) LoggingOption {
+	return func(l *logging) {
+		l.headers = true
+		for _, h := range redact {
+			l.redact[http.CanonicalHeaderKey(h)] = struct{}{}
+		}
+	}
+}
+
+// LogBodySample enables logging of up to maxBytes bytes of request and
+// response bodies. Bodies are only sampled when the logger is enabled for
+// slog.LevelDebug, so sampling can stay configured in production setups. The
+// sampled bytes are restored so the bodies are left untouched for downstream
+// processing.
+func LogBodySample(maxBytes int) LoggingOption {
+	return func(l *logging) {
+		l.bodySample = maxBytes
+	}
+}
+
+// logging logs the request roundtrip using a slog.Logger. It wraps the
+// roundtrip so records reflect the request as it is sent, i.e. after all
+// request options have been applied.
+type logging struct {
+	logger     *slog.Logger
+	level      slog.Level
+	headers    bool
+	redact     map[string]struct{}
+	bodySample int
+}
+
+func (*logging) clientOpt() {}
+func (*logging) reqOpt()    {}
+
+func (l *logging) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := r.Context()
+	if !l.logger.Enabled(ctx, l.level) {
+		return next(r)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("url", r.URL.Redacted()),
+		slog.Int("attempt", ExecutionStateFromContext(ctx).Attempt),
+	}
+
+	requestID := RequestIDFromContext(ctx)
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+
+	if l.headers {
+		attrs = append(attrs, l.headerAttrs(r.Header))
+	}
+
+	if l.sampleBodies(ctx) && r.Body != nil {
+		sample, body, err := sampleBody(r.Body, l.bodySample)
+		r.Body = body
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, slog.String("body", sample))
+	}
+
+	l.logger.LogAttrs(ctx, l.level, "http request started", attrs...)
+
+	clock := ClockFromContext(ctx)
+	start := clock.Now()
+	res, err := next(r)
+
+	attrs = []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("url", r.URL.Redacted()),
+	}
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+
+	if err != nil {
+		attrs = append(attrs,
+			slog.Int("attempt", ExecutionStateFromContext(ctx).Attempt),
+			slog.Duration("duration", clock.Now().Sub(start)),
+			slog.String("error", err.Error()),
+		)
+		l.logger.LogAttrs(ctx, l.level, "http request failed", attrs...)
+		return res, err
+	}
+
+	attrs = append(attrs,
+		slog.Int("status", res.StatusCode),
+		slog.Int("attempt", ExecutionStateFromContext(ctx).Attempt),
+	)
+
+	if FromCache(res) {
+		attrs = append(attrs, slog.Bool("cached", true))
+	}
+
+	attrs = append(attrs, slog.Duration("duration", clock.Now().Sub(start)))
+
+	if l.headers {
+		attrs = append(attrs, l.headerAttrs(res.Header))
+	}
+
+	if l.sampleBodies(ctx) && res.Body != nil {
+		sample, body, err := sampleBody(res.Body, l.bodySample)
+		res.Body = body
+		if err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+		attrs = append(attrs, slog.String("body", sample))
+	}
+
+	l.logger.LogAttrs(ctx, l.level, "http request completed", attrs...)
+
+	return res, nil
+}
+
+func (l *logging) sampleBodies(ctx context.Context) bool {
+	return l.bodySample > 0 && l.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+func (l *logging) headerAttrs(h http.Header) slog.Attr {
+	attrs := make([]any, 0, len(h))
+	for _, name := range slices.Sorted(maps.Keys(h)) {
+		if _, ok := l.redact[name]; ok {
+			attrs = append(attrs, slog.String(name, redacted))
+			continue
+		}
+		attrs = append(attrs, slog.String(name, strings.Join(h[name], ", ")))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// WithLogging creates an Option that logs every request roundtrip to logger
+// using level. A record is logged when a request is sent and when its
+// response has been received. Records contain the request's method, URL (with
+// any password redacted) and attempt number as well as the response's status
+// code and the duration of the roundtrip. Use opts to enable logging of
+// headers and body samples. Records of requests carrying a request ID, i.e.
+// assigned using WithRequestID, contain the ID.
+//
+// Records are emitted when the request is sent, so they include headers and
+// bodies set by any option, no matter whether WithLogging is given to New or
+// to a single request. Requests failing with a transport error produce a
+// "http request failed" record carrying the error and duration.
+func WithLogging(logger *slog.Logger, level slog.Level, opts ...LoggingOption) Option {
+	l := &logging{
+		logger: logger,
+		level:  level,
+		redact: make(map[string]struct{}, len(defaultRedactedHeaders)),
+	}
+
+	for _, h := range defaultRedactedHeaders {
+		l.redact[h] = struct{}{}
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// multiReadCloser combines a Reader with a separate Closer.
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// sampleBody reads up to n bytes from body and returns them as a string
+// together with a ReadCloser that yields the full, unconsumed body.
+func sampleBody(body io.ReadCloser, n int) (string, io.ReadCloser, error) {
+	buf := make([]byte, n)
+	k, err := io.ReadFull(body, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	return string(buf[:k]), &multiReadCloser{io.MultiReader(bytes.NewReader(buf[:k]), body), body}, err
+}
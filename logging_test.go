@@ -0,0 +1,103 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithLogging(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("response body"))
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithLogging(logger, slog.LevelInfo,
+			httpclient.LogHeaders("X-Api-Key"),
+			httpclient.LogBodySample(4),
+		),
+	)
+
+	var body []byte
+	_, err := client.Post(context.Background(), "/items",
+		httpclient.WithRequestHeader("X-Api-Key", "secret"),
+		httpclient.WithBody(strings.NewReader("request body"), "text/plain", -1),
+		httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			return r, err
+		}),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, string(body)).Is(Equal("response body"))
+
+	log := buf.String()
+	ExpectThat(t, log).
+		Has(StringContaining(`msg="http request started" method=POST url=` + testServer.URL + "/items attempt=1")).
+		And(StringContaining(`msg="http request completed" method=POST url=` + testServer.URL + "/items status=202 attempt=1 duration=")).
+		And(StringContaining("headers.X-Api-Key=REDACTED")).
+		And(StringContaining("headers.Set-Cookie=REDACTED")).
+		And(StringContaining(`body=requ`)).
+		And(StringContaining(`body=resp`))
+}
+
+func TestWithLogging_failure(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	testServer := httptest.NewServer(http.NotFoundHandler())
+	url := strings.Replace(testServer.URL, "http://", "http://user:pass@", 1) + "/items"
+	redactedURL := strings.Replace(testServer.URL, "http://", "http://user:xxxxx@", 1) + "/items"
+	testServer.Close()
+
+	client := httpclient.New(httpclient.WithLogging(logger, slog.LevelInfo))
+
+	_, err := client.Get(context.Background(), url)
+	ExpectThat(t, err).Is(NotNil())
+
+	ExpectThat(t, buf.String()).
+		Has(StringContaining(`msg="http request started" method=GET url=` + redactedURL + " attempt=1")).
+		And(StringContaining(`msg="http request failed" method=GET url=` + redactedURL + " attempt=1 duration=")).
+		And(StringContaining(`error=`))
+}
+
+func TestWithLogging_sortedHeaders(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithLogging(logger, slog.LevelInfo, httpclient.LogHeaders()),
+	)
+
+	for range 10 {
+		buf.Reset()
+		_, err := client.Get(context.Background(), "/",
+			httpclient.WithRequestHeader("X-C", "c"),
+			httpclient.WithRequestHeader("X-A", "a"),
+			httpclient.WithRequestHeader("X-B", "b"),
+		)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, buf.String()).Is(StringContaining("headers.X-A=a headers.X-B=b headers.X-C=c"))
+	}
+}
@@ -0,0 +1,44 @@
+package httpclient
+
+import "net/http"
+
+// Doer defines the interface of types sending HTTP requests. It is
+// implemented by *http.Client.
+type Doer interface {
+	Do(r *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is a convenience type implementing Doer as a bare function.
+type DoerFunc func(r *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Middleware wraps the Doer sending a request to add behaviour around the
+// whole exchange, i.e. retries, caching or tracing. A Middleware may modify
+// or replace the request before calling next, send it multiple times or not
+// at all and modify or replace the response.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware creates an Option wrapping the sending of requests with m.
+// The request passed to m has been processed by all request interceptors; the
+// response returned by m is passed to the response interceptors. Given
+// multiple middlewares, the ones given first wrap the ones given later.
+// Client-level middlewares wrap request-level ones.
+func WithMiddleware(m ...Middleware) Option {
+	return middlewareOption(m)
+}
+
+type middlewareOption []Middleware
+
+func (middlewareOption) clientOpt() {}
+func (middlewareOption) reqOpt()    {}
+
+func (o middlewareOption) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	var d Doer = DoerFunc(next)
+	for i := len(o) - 1; i >= 0; i-- {
+		d = o[i](d)
+	}
+	return d.Do(r)
+}
@@ -0,0 +1,57 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Trace", r.Header.Get("X-Trace"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var calls []string
+
+	trace := func(name string) httpclient.Middleware {
+		return func(next httpclient.Doer) httpclient.Doer {
+			return httpclient.DoerFunc(func(r *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				r.Header.Add("X-Trace", name)
+				res, err := next.Do(r)
+				calls = append(calls, name+" after")
+				return res, err
+			})
+		}
+	}
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithMiddleware(trace("client-1"), trace("client-2")),
+	)
+
+	res, err := client.Get(context.Background(), "/",
+		httpclient.WithMiddleware(trace("request")),
+		httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			calls = append(calls, "response interceptor")
+			return r, nil
+		}),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.Header.Get("X-Trace")).Is(Equal("client-1"))
+	ExpectThat(t, calls).Is(DeepEqual([]string{
+		"client-1 before",
+		"client-2 before",
+		"request before",
+		"request after",
+		"client-2 after",
+		"client-1 after",
+		"response interceptor",
+	}))
+}
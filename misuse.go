@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrMisuse is wrapped by all errors reported by the misuse detection enabled
+// with WithMisuseDetection.
+var ErrMisuse = errors.New("httpclient misuse")
+
+// WithMisuseDetection creates a ClientOption enabling the detection of common
+// misuses of request options, which are hard to debug otherwise:
+//
+//   - sharing a single ForJSON target between concurrently executed requests
+//   - reusing an option created by WithBody whose body has already been
+//     consumed by a previous request
+//
+// Every detected misuse is passed to report as an error wrapping ErrMisuse
+// and the request is executed anyway. If report is nil, the request fails
+// with that error instead. Misuse detection adds bookkeeping overhead to
+// every request and is meant for development and testing.
+func WithMisuseDetection(report func(error)) ClientOption {
+	return clientConfigOption(func(c *Client) {
+		c.misuse = &misuseDetector{
+			report:   report,
+			inflight: make(map[uintptr]int),
+		}
+	})
+}
+
+// misuseDetector implements the bookkeeping for WithMisuseDetection.
+type misuseDetector struct {
+	report   func(error)
+	mutex    sync.Mutex
+	inflight map[uintptr]int
+}
+
+// check checks the client-level interceptors of c as well as opts for misuse
+// and registers the ForJSON targets as being in use. The returned function
+// must be called once the request has been completed, even if a non-nil
+// error is returned.
+func (d *misuseDetector) check(c *Client, opts []RequestOption) (func(), error) {
+	var targets []uintptr
+	var errs []error
+
+	candidates := make([]any, 0, len(c.reqInterceptors)+len(opts))
+	for _, i := range c.reqInterceptors {
+		candidates = append(candidates, i)
+	}
+	for _, opt := range opts {
+		candidates = append(candidates, opt)
+	}
+
+	d.mutex.Lock()
+	for _, candidate := range candidates {
+		switch o := candidate.(type) {
+		case *forJSON:
+			v := reflect.ValueOf(o.value)
+			if v.Kind() != reflect.Pointer || v.IsNil() {
+				continue
+			}
+			p := v.Pointer()
+			if d.inflight[p] > 0 {
+				errs = append(errs, fmt.Errorf("%w: ForJSON target %T is shared with a concurrently executed request", ErrMisuse, o.value))
+			}
+			d.inflight[p]++
+			targets = append(targets, p)
+
+		case RequestInterceptorOption:
+			// Swap marks the body as used, so concurrent requests sharing the
+			// option are detected before either of them consumes the body.
+			if b, ok := o.RequestInterceptor.(*bodyInterceptor); ok && b.used.Swap(true) {
+				errs = append(errs, fmt.Errorf("%w: WithBody option reused after its body has been consumed", ErrMisuse))
+			}
+		}
+	}
+	d.mutex.Unlock()
+
+	release := func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+
+		for _, p := range targets {
+			d.inflight[p]--
+			if d.inflight[p] == 0 {
+				delete(d.inflight, p)
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return release, nil
+	}
+
+	if d.report == nil {
+		return release, errors.Join(errs...)
+	}
+
+	for _, err := range errs {
+		d.report(err)
+	}
+
+	return release, nil
+}
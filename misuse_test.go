@@ -0,0 +1,82 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithMisuseDetection(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer testServer.Close()
+
+	t.Run("shared_target", func(t *testing.T) {
+		client := httpclient.New(
+			httpclient.WithURLPrefix(testServer.URL),
+			httpclient.WithMisuseDetection(nil),
+		)
+
+		var target map[string]any
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.Get(context.Background(), "/slow", httpclient.ForJSON(&target))
+			done <- err
+		}()
+
+		<-started
+		_, err := client.Get(context.Background(), "/", httpclient.ForJSON(&target))
+		ExpectThat(t, err).Is(Error(httpclient.ErrMisuse))
+
+		close(release)
+		ExpectThat(t, <-done).Is(NoError())
+	})
+
+	t.Run("reused_body", func(t *testing.T) {
+		var reported []error
+		client := httpclient.New(
+			httpclient.WithURLPrefix(testServer.URL),
+			httpclient.WithMisuseDetection(func(err error) { reported = append(reported, err) }),
+		)
+
+		body := httpclient.WithBody(strings.NewReader("hello"), "text/plain", 5)
+
+		_, err := client.Post(context.Background(), "/", body)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, reported).Is(Len(0))
+
+		// The request is executed anyway and fails as the body has been consumed.
+		_, err = client.Post(context.Background(), "/", body)
+		ExpectThat(t, err).Is(NotNil())
+		ExpectThat(t, reported).Is(Len(1))
+		ExpectThat(t, reported[0]).Is(Error(httpclient.ErrMisuse))
+	})
+
+	t.Run("client_level_body", func(t *testing.T) {
+		client := httpclient.New(
+			httpclient.WithURLPrefix(testServer.URL),
+			httpclient.WithBody(strings.NewReader("hello"), "text/plain", 5),
+			httpclient.WithMisuseDetection(nil),
+		)
+
+		_, err := client.Post(context.Background(), "/")
+		ExpectThat(t, err).Is(NoError())
+
+		_, err = client.Post(context.Background(), "/")
+		ExpectThat(t, err).Is(Error(httpclient.ErrMisuse))
+	})
+}
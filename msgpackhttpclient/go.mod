@@ -0,0 +1,14 @@
+module github.com/halimath/httpclient/msgpackhttpclient
+
+go 1.23.0
+
+require (
+	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7
+	github.com/halimath/httpclient v0.1.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/deckarep/golang-set/v2 v2.1.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+)
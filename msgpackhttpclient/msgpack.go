@@ -0,0 +1,26 @@
+// Package msgpackhttpclient integrates httpclient with MessagePack for APIs
+// preferring its compact binary encoding to JSON. It is provided as a
+// separate module so that applications not using MessagePack don't depend on
+// it.
+package msgpackhttpclient
+
+import (
+	"github.com/halimath/httpclient"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the content type of MessagePack encoded bodies.
+const ContentType = "application/msgpack"
+
+// WithMsgPack creates a RequestInterceptorOption that uses v encoded as
+// MessagePack as the request's body. See httpclient.WithEncoded.
+func WithMsgPack(v any) httpclient.RequestInterceptorOption {
+	return httpclient.WithEncoded(msgpack.Marshal, v, ContentType)
+}
+
+// ForMsgPack creates a RequestOption that decodes a MessagePack encoded
+// response body into v. Both application/msgpack and the unregistered
+// application/x-msgpack are accepted. See httpclient.ForDecoded.
+func ForMsgPack(v any) httpclient.RequestOption {
+	return httpclient.ForDecoded(msgpack.Unmarshal, v, ContentType, "application/x-msgpack")
+}
@@ -0,0 +1,43 @@
+package msgpackhttpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/msgpackhttpclient"
+)
+
+func TestMsgPack(t *testing.T) {
+	type event struct {
+		Name  string `msgpack:"name"`
+		Count int    `msgpack:"count"`
+	}
+
+	var contentType, accept string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", msgpackhttpclient.ContentType)
+		io.Copy(w, r.Body)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	var got event
+	_, err := client.Post(context.Background(), "/",
+		msgpackhttpclient.WithMsgPack(event{Name: "click", Count: 3}),
+		msgpackhttpclient.ForMsgPack(&got),
+	)
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, got).Is(Equal(event{Name: "click", Count: 3}))
+	ExpectThat(t, contentType).Is(Equal(msgpackhttpclient.ContentType))
+	ExpectThat(t, accept).Is(Equal(msgpackhttpclient.ContentType))
+}
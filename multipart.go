@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FilePart describes a file uploaded using WithMultipartForm.
+type FilePart struct {
+	// FieldName is the name of the form field.
+	FieldName string
+
+	// FileName is the name of the file reported to the server.
+	FileName string
+
+	// ContentType is the content type of the file. It defaults to
+	// application/octet-stream.
+	ContentType string
+
+	// Header contains additional headers of the part. Values given here
+	// replace the Content-Disposition and Content-Type headers derived from
+	// the fields above.
+	Header textproto.MIMEHeader
+
+	// Content is read to produce the file's content. It is not closed.
+	Content io.Reader
+}
+
+// WithMultipartForm creates a RequestInterceptorOption that sends fields and
+// files as a multipart/form-data body. The body is streamed: files are read
+// from their Content while the request is sent and are never buffered in
+// memory as a whole. If the request had a previous non-nil Body this value is
+// closed before.
+//
+// As the body's length is not known upfront, the request is sent using
+// chunked transfer encoding. Fields are written in the order of their names
+// followed by files in the given order. As files are consumed when the
+// request is sent, the returned option must not be used for more than one
+// request and the request is not retried. Any error produced while reading
+// a file aborts the request.
+func WithMultipartForm(fields map[string]string, files ...FilePart) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		body := newMultipartBody(fields, files)
+		return withBody(body, body.w.FormDataContentType(), -1).InterceptRequest(r)
+	})
+}
+
+// multipartBody is an io.ReadCloser producing a multipart/form-data body. The
+// parts are written to a pipe by a goroutine started on the first read, so
+// no goroutine is leaked for requests that are never sent.
+type multipartBody struct {
+	r      *io.PipeReader
+	pw     *io.PipeWriter
+	w      *multipart.Writer
+	start  sync.Once
+	fields map[string]string
+	files  []FilePart
+}
+
+func newMultipartBody(fields map[string]string, files []FilePart) *multipartBody {
+	pr, pw := io.Pipe()
+	return &multipartBody{
+		r:      pr,
+		pw:     pw,
+		w:      multipart.NewWriter(pw),
+		fields: fields,
+		files:  files,
+	}
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.start.Do(func() {
+		go func() {
+			b.pw.CloseWithError(b.write())
+		}()
+	})
+	return b.r.Read(p)
+}
+
+func (b *multipartBody) Close() error {
+	return b.r.Close()
+}
+
+func (b *multipartBody) write() error {
+	for _, name := range slices.Sorted(maps.Keys(b.fields)) {
+		if err := b.w.WriteField(name, b.fields[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range b.files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(f.FieldName), quoteEscaper.Replace(f.FileName)))
+		if f.ContentType != "" {
+			h.Set("Content-Type", f.ContentType)
+		} else {
+			h.Set("Content-Type", "application/octet-stream")
+		}
+		maps.Copy(h, f.Header)
+
+		w, err := b.w.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, f.Content); err != nil {
+			return err
+		}
+	}
+
+	return b.w.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
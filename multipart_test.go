@@ -0,0 +1,97 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithMultipartForm(t *testing.T) {
+	type part struct {
+		filename, contentType, checksum, content string
+	}
+
+	var fields map[string][]string
+	var files map[string]part
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = nil
+		files = make(map[string]part)
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fields = r.MultipartForm.Value
+		for name, headers := range r.MultipartForm.File {
+			f, _ := headers[0].Open()
+			content, _ := io.ReadAll(f)
+			files[name] = part{
+				filename:    headers[0].Filename,
+				contentType: headers[0].Header.Get("Content-Type"),
+				checksum:    headers[0].Header.Get("X-Checksum"),
+				content:     string(content),
+			}
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	t.Run("success", func(t *testing.T) {
+		_, err := client.Post(context.Background(), "/", httpclient.WithMultipartForm(
+			map[string]string{"title": "Report", "year": "2024"},
+			httpclient.FilePart{
+				FieldName:   "report",
+				FileName:    "report.csv",
+				ContentType: "text/csv",
+				Header:      textproto.MIMEHeader{"X-Checksum": {"abc"}},
+				Content:     strings.NewReader("a,b\n1,2\n"),
+			},
+			httpclient.FilePart{
+				FieldName: "raw",
+				FileName:  "data.bin",
+				Content:   strings.NewReader("binary"),
+			},
+		))
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, fields).Is(DeepEqual(map[string][]string{"title": {"Report"}, "year": {"2024"}}))
+		ExpectThat(t, files).Is(DeepEqual(map[string]part{
+			"report": {filename: "report.csv", contentType: "text/csv", checksum: "abc", content: "a,b\n1,2\n"},
+			"raw":    {filename: "data.bin", contentType: "application/octet-stream", content: "binary"},
+		}))
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		errRead := errors.New("read failed")
+
+		_, err := client.Post(context.Background(), "/", httpclient.WithMultipartForm(nil,
+			httpclient.FilePart{
+				FieldName: "file",
+				FileName:  "file.txt",
+				Content:   io.MultiReader(strings.NewReader("partial"), &failingReader{errRead}),
+			},
+		))
+
+		ExpectThat(t, err).Is(Error(errRead))
+	})
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
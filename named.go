@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"slices"
+)
+
+// named is implemented by interceptors registered under a name.
+type named interface {
+	interceptorName() string
+}
+
+// nameOf returns the name i has been registered under or an empty string.
+func nameOf(i any) string {
+	switch o := i.(type) {
+	case RequestInterceptorOption:
+		i = o.RequestInterceptor
+	case ResponseInterceptorOption:
+		i = o.ResponseInterceptor
+	}
+
+	if n, ok := i.(named); ok {
+		return n.interceptorName()
+	}
+	return ""
+}
+
+type namedRequestInterceptor struct {
+	RequestInterceptor
+	name string
+}
+
+func (n namedRequestInterceptor) interceptorName() string { return n.name }
+
+func (n namedRequestInterceptor) RequestPhase() RequestPhase {
+	return requestPhaseOf(n.RequestInterceptor)
+}
+
+type namedResponseInterceptor struct {
+	ResponseInterceptor
+	name string
+}
+
+func (n namedResponseInterceptor) interceptorName() string { return n.name }
+
+func (n namedResponseInterceptor) Phase() Phase {
+	return phaseOf(n.ResponseInterceptor)
+}
+
+// WithNamedRequestInterceptor creates a RequestInterceptorOption registering i
+// under name. Registering an interceptor under a name already used by a
+// client-level interceptor replaces that interceptor, keeping its position.
+// This allows clients derived using Client.With to replace i, i.e. to use
+// different credentials:
+//
+//	base := httpclient.New(httpclient.WithNamedRequestInterceptor("auth", userAuth))
+//	admin := base.With(httpclient.WithNamedRequestInterceptor("auth", adminAuth))
+//
+// Given as a RequestOption, i replaces the client-level interceptor
+// registered under name for a single request. Use WithoutInterceptor to
+// remove a named interceptor. The RequestPhase of i is kept.
+func WithNamedRequestInterceptor(name string, i RequestInterceptor) RequestInterceptorOption {
+	return RequestInterceptorOption{namedRequestInterceptor{i, name}}
+}
+
+// WithNamedResponseInterceptor is like WithNamedRequestInterceptor for
+// ResponseInterceptors. The Phase of i is kept.
+func WithNamedResponseInterceptor(name string, i ResponseInterceptor) ResponseInterceptorOption {
+	return ResponseInterceptorOption{namedResponseInterceptor{i, name}}
+}
+
+// WithoutInterceptor creates an Option removing the request and response
+// interceptors registered under name using WithNamedRequestInterceptor or
+// WithNamedResponseInterceptor. Given to Client.With, the interceptors are
+// removed from the derived client. Given as a RequestOption, the client-level
+// interceptors are skipped for a single request, i.e. to send a request
+// without authentication.
+func WithoutInterceptor(name string) Option {
+	return withoutInterceptor(name)
+}
+
+type withoutInterceptor string
+
+func (withoutInterceptor) clientOpt() {}
+func (withoutInterceptor) reqOpt()    {}
+
+// addNamed appends i to interceptors or replaces the interceptor registered
+// under the same name. interceptors is never modified in place, as it may be
+// shared with a Client derived using Client.With.
+func addNamed[T any](interceptors []T, i T) []T {
+	if name := nameOf(i); name != "" {
+		if idx := slices.IndexFunc(interceptors, func(e T) bool { return nameOf(e) == name }); idx >= 0 {
+			interceptors = slices.Clone(interceptors)
+			interceptors[idx] = i
+			return interceptors
+		}
+	}
+
+	return append(interceptors, i)
+}
+
+// removeNamed returns a copy of interceptors without the ones registered
+// under name.
+func removeNamed[T any](interceptors []T, name string) []T {
+	return slices.DeleteFunc(slices.Clone(interceptors), func(e T) bool { return nameOf(e) == name })
+}
+
+// overriddenNames returns the names of client-level interceptors that must be
+// skipped for a request sent with opts or nil if there are none.
+func overriddenNames(opts []RequestOption) map[string]bool {
+	var names map[string]bool
+
+	for _, opt := range opts {
+		name := nameOf(opt)
+		if w, ok := opt.(withoutInterceptor); ok {
+			name = string(w)
+		}
+
+		if name == "" {
+			continue
+		}
+
+		if names == nil {
+			names = make(map[string]bool)
+		}
+		names[name] = true
+	}
+
+	return names
+}
@@ -0,0 +1,60 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestNamedInterceptors(t *testing.T) {
+	var received http.Header
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	errRejected := errors.New("rejected")
+
+	base := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithNamedRequestInterceptor("auth", httpclient.WithRequestHeader("Authorization", "user")),
+		httpclient.WithRequestHeader("X-Order", "1"),
+		httpclient.WithNamedResponseInterceptor("reject", httpclient.ResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+			return r, errRejected
+		})),
+	)
+
+	_, err := base.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(Error(errRejected))
+	ExpectThat(t, received.Get("Authorization")).Is(Equal("user"))
+
+	admin := base.With(
+		httpclient.WithNamedRequestInterceptor("auth", httpclient.WithRequestHeader("Authorization", "admin")),
+		httpclient.WithoutInterceptor("reject"),
+	)
+
+	_, err = admin.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("Authorization")).Is(Equal("admin"))
+
+	_, err = admin.Get(context.Background(), "/", httpclient.WithoutInterceptor("auth"))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("Authorization")).Is(Equal(""))
+	ExpectThat(t, received.Get("X-Order")).Is(Equal("1"))
+
+	_, err = admin.Get(context.Background(), "/",
+		httpclient.WithNamedRequestInterceptor("auth", httpclient.WithRequestHeader("X-Token", "t")))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("Authorization")).Is(Equal(""))
+	ExpectThat(t, received.Get("X-Token")).Is(Equal("t"))
+
+	_, err = base.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(Error(errRejected))
+	ExpectThat(t, received.Get("Authorization")).Is(Equal("user"))
+}
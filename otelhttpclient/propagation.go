@@ -0,0 +1,47 @@
+// Package otelhttpclient integrates httpclient with OpenTelemetry. It is
+// provided as a separate module so that applications not using OpenTelemetry
+// don't depend on it.
+package otelhttpclient
+
+import (
+	"net/http"
+
+	"github.com/halimath/httpclient"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// PropagationOption customizes the interceptor created by Propagation.
+type PropagationOption func(*propagator)
+
+// WithPropagator sets the propagator used to inject the trace context. It
+// defaults to propagation.TraceContext, which writes the W3C traceparent and
+// tracestate headers. Use otel.GetTextMapPropagator() to use the globally
+// configured propagator instead.
+func WithPropagator(p propagation.TextMapPropagator) PropagationOption {
+	return func(pr *propagator) {
+		pr.p = p
+	}
+}
+
+type propagator struct {
+	p propagation.TextMapPropagator
+}
+
+// Propagation creates a RequestInterceptorOption injecting the span context
+// stored in a request's context into the request's headers. The span context
+// is propagated no matter whether spans are recorded for the requests sent
+// by the client; contexts carrying no valid span context leave the request
+// untouched. The interceptor runs in httpclient.RequestPhaseTracing, so it
+// sees the context set by any other request interceptor.
+func Propagation(opts ...PropagationOption) httpclient.RequestInterceptorOption {
+	pr := &propagator{p: propagation.TraceContext{}}
+
+	for _, opt := range opts {
+		opt(pr)
+	}
+
+	return httpclient.InRequestPhaseFunc(httpclient.RequestPhaseTracing, func(r *http.Request) (*http.Request, error) {
+		pr.p.Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+		return r, nil
+	})
+}
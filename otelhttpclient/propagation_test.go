@@ -0,0 +1,46 @@
+package otelhttpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/otelhttpclient"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPropagation(t *testing.T) {
+	var traceparent, tracestate string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		tracestate = r.Header.Get("Tracestate")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(otelhttpclient.Propagation())
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	state, _ := trace.ParseTraceState("vendor=value")
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		TraceState: state,
+		Remote:     true,
+	}))
+
+	_, err := client.Get(ctx, testServer.URL)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, traceparent).Is(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	ExpectThat(t, tracestate).Is(Equal("vendor=value"))
+
+	_, err = client.Get(context.Background(), testServer.URL)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, traceparent).Is(Equal(""))
+}
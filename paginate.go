@@ -0,0 +1,183 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+)
+
+// DefaultMaxPages is the number of pages requested by Paginate unless a
+// different limit is given.
+const DefaultMaxPages = 100
+
+// ErrMaxPagesExceeded is yielded by Paginate if the last page permitted
+// links to a further page.
+var ErrMaxPagesExceeded = errors.New("maximum number of pages exceeded")
+
+// Paginate creates an iterator over the pages of a paginated collection
+// starting at url. Subsequent pages are requested by following the
+// rel="next" links given in each response's Link header, as done by the
+// GitHub API. Each page is requested using c and opts when the previous one
+// has been consumed.
+//
+// Like for Iterate, the responses are yielded in PhasePostValidate and thus
+// only after they passed the status validation configured for c or with
+// opts. Their bodies are readable while the iteration's loop body runs, i.e.
+// to decode each page:
+//
+//	for res, err := range client.Paginate(ctx, "/repos/halimath/httpclient/issues", 0) {
+//		if err != nil {
+//			return err
+//		}
+//		var issues []Issue
+//		if err := json.NewDecoder(res.Body).Decode(&issues); err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+//
+// maxPages limits the number of pages requested; values <= 0 use
+// DefaultMaxPages. If the last page permitted links to a further page,
+// ErrMaxPagesExceeded is yielded. Any error that occurs while requesting a
+// page is yielded as the final element of the iteration.
+func (c *Client) Paginate(ctx context.Context, url string, maxPages int, opts ...RequestOption) iter.Seq2[*http.Response, error] {
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	return func(yield func(*http.Response, error) bool) {
+		next := url
+
+		for page := 1; next != ""; page++ {
+			if page > maxPages {
+				yield(nil, ErrMaxPagesExceeded)
+				return
+			}
+
+			var stopped bool
+			var body loopBody
+			reqOpts := append(opts[:len(opts):len(opts)], InPhaseFunc(PhasePostValidate, func(r *http.Response) (*http.Response, error) {
+				next = ""
+				if u := Link(r, "next"); u != nil {
+					next = u.String()
+				}
+				stopped = !body.call(func() bool { return yield(r, nil) })
+				return r, nil
+			}))
+
+			_, err := c.Execute(ctx, http.MethodGet, next, reqOpts...)
+			body.repanic()
+			if stopped {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// Pagination describes a paginated collection of items of type T delivered
+// in pages of type P for use with PaginateItems. It supports any paging
+// scheme - i.e. offset, cursor or token based - by letting NextPage derive
+// the request for the next page from a response and its decoded page.
+type Pagination[P, T any] struct {
+	// Decode decodes a page from a response. It defaults to decoding the
+	// response body as JSON like ForJSON does.
+	Decode func(r *http.Response) (P, error)
+
+	// Items returns the items contained in page.
+	Items func(page P) []T
+
+	// NextPage returns the request for the page following page, which has
+	// been decoded from r, or false if page is the last one. Use
+	// CloneWithQuery to derive the request from the initial one.
+	NextPage func(r *http.Response, page P) (*http.Request, bool)
+
+	// MaxPages limits the number of pages requested. It defaults to
+	// DefaultMaxPages.
+	MaxPages int
+}
+
+// PaginateItems creates an iterator over the items of the paginated
+// collection described by p, starting with the page requested by req. Each
+// page is requested using c and opts when the items of the previous page
+// have been consumed:
+//
+//	req, _ := http.NewRequest(http.MethodGet, "/items?limit=100", nil)
+//	items := httpclient.PaginateItems(ctx, client, req, httpclient.Pagination[ItemPage, Item]{
+//		Items: func(p ItemPage) []Item { return p.Items },
+//		NextPage: func(_ *http.Response, p ItemPage) (*http.Request, bool) {
+//			return httpclient.CloneWithQuery(req, "cursor", p.NextCursor), p.NextCursor != ""
+//		},
+//	})
+//
+// Pages are decoded in PhasePostValidate and thus only after they passed the
+// status validation configured for c or with opts. Any error that occurs
+// while requesting or decoding a page is yielded as the final element of the
+// iteration with the zero value of T. If the last page permitted links to a
+// further page, ErrMaxPagesExceeded is yielded.
+func PaginateItems[P, T any](ctx context.Context, c *Client, req *http.Request, p Pagination[P, T], opts ...RequestOption) iter.Seq2[T, error] {
+	if p.Decode == nil {
+		p.Decode = func(r *http.Response) (P, error) {
+			var page P
+			_, err := (&forJSON{value: &page}).InterceptResponse(r)
+			return page, err
+		}
+	}
+	if p.MaxPages <= 0 {
+		p.MaxPages = DefaultMaxPages
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		cur := req
+		for pageNum := 1; cur != nil; pageNum++ {
+			if pageNum > p.MaxPages {
+				yield(zero, ErrMaxPagesExceeded)
+				return
+			}
+
+			var page P
+			var next *http.Request
+			reqOpts := append(opts[:len(opts):len(opts)], InPhaseFunc(PhasePostValidate, func(r *http.Response) (*http.Response, error) {
+				var err error
+				if page, err = p.Decode(r); err != nil {
+					return r, err
+				}
+				if n, ok := p.NextPage(r, page); ok {
+					next = n
+				}
+				return r, nil
+			}))
+
+			if _, err := c.Do(cur.Clone(ctx), reqOpts...); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range p.Items(page) {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			cur = next
+		}
+	}
+}
+
+// CloneWithQuery returns a copy of r with the query parameter key set to
+// value, replacing any previous values. It is meant to derive the requests
+// for subsequent pages in Pagination.NextPage, i.e. to set an offset or a
+// cursor.
+func CloneWithQuery(r *http.Request, key, value string) *http.Request {
+	next := r.Clone(r.Context())
+	q := next.URL.Query()
+	q.Set(key, value)
+	next.URL.RawQuery = q.Encode()
+	return next
+}
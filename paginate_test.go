@@ -0,0 +1,174 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestPaginate(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page < 3 {
+			w.Header().Add("Link", fmt.Sprintf(`</items?page=%d>; rel="next", </items?page=3>; rel="last"`, page+1))
+		}
+		fmt.Fprintf(w, "page %d", page)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	collect := func(maxPages int) ([]string, error) {
+		var pages []string
+		for res, err := range client.Paginate(context.Background(), "/items", maxPages) {
+			if err != nil {
+				return pages, err
+			}
+			b, _ := io.ReadAll(res.Body)
+			pages = append(pages, string(b))
+		}
+		return pages, nil
+	}
+
+	t.Run("all pages", func(t *testing.T) {
+		pages, err := collect(0)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, pages).Is(DeepEqual([]string{"page 1", "page 2", "page 3"}))
+	})
+
+	t.Run("max pages", func(t *testing.T) {
+		pages, err := collect(2)
+		ExpectThat(t, err).Is(Error(httpclient.ErrMaxPagesExceeded))
+		ExpectThat(t, pages).Is(DeepEqual([]string{"page 1", "page 2"}))
+	})
+
+	t.Run("break", func(t *testing.T) {
+		var count int
+		for range client.Paginate(context.Background(), "/items", 0) {
+			count++
+			break
+		}
+		ExpectThat(t, count).Is(Equal(1))
+	})
+
+	t.Run("loop body panic", func(t *testing.T) {
+		recovered := func() (v any) {
+			defer func() { v = recover() }()
+			for range client.Paginate(context.Background(), "/items", 0) {
+				panic("user bug")
+			}
+			return nil
+		}()
+		ExpectThat(t, recovered).Is(Equal(any("user bug")))
+	})
+}
+
+func TestLink(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/items?page=2", nil)
+	res := &http.Response{
+		Request: req,
+		Header: http.Header{"Link": {
+			`<https://api.example.com/items?page=1>; rel="prev first", <https://api.example.com/items?a=1,2>; rel=next`,
+			`</items?page=9>; title="a, b"; rel="last"`,
+		}},
+	}
+
+	ExpectThat(t, httpclient.Link(res, "next").String()).Is(Equal("https://api.example.com/items?a=1,2"))
+	ExpectThat(t, httpclient.Link(res, "first").String()).Is(Equal("https://api.example.com/items?page=1"))
+	ExpectThat(t, httpclient.Link(res, "last").String()).Is(Equal("https://api.example.com/items?page=9"))
+	ExpectThat(t, httpclient.Link(res, "self") == nil).Is(Equal(true))
+}
+
+func TestPaginateItems(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		w.Header().Set("Content-Type", "application/json")
+		switch offset {
+		case 0:
+			w.Write([]byte(`{"items":[1,2],"total":5}`))
+		case 2:
+			w.Write([]byte(`{"items":[3,4],"total":5}`))
+		default:
+			w.Write([]byte(`{"items":[5],"total":5}`))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	type page struct {
+		Items []int `json:"items"`
+		Total int   `json:"total"`
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/items?limit=2", nil)
+	offset := 0
+
+	pagination := httpclient.Pagination[page, int]{
+		Items: func(p page) []int { return p.Items },
+		NextPage: func(_ *http.Response, p page) (*http.Request, bool) {
+			offset += len(p.Items)
+			return httpclient.CloneWithQuery(req, "offset", strconv.Itoa(offset)), offset < p.Total
+		},
+	}
+
+	var items []int
+	for item, err := range httpclient.PaginateItems(context.Background(), client, req, pagination) {
+		ExpectThat(t, err).Is(NoError())
+		items = append(items, item)
+	}
+
+	ExpectThat(t, items).Is(DeepEqual([]int{1, 2, 3, 4, 5}))
+
+	offset = 0
+	pagination.MaxPages = 1
+	var lastErr error
+	for _, err := range httpclient.PaginateItems(context.Background(), client, req, pagination) {
+		lastErr = err
+	}
+	ExpectThat(t, lastErr).Is(Error(httpclient.ErrMaxPagesExceeded))
+}
+
+func TestPaginateItems_reusable(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items":[%d],"last":%t}`, page, page == 2)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	type page struct {
+		Items []int `json:"items"`
+		Last  bool  `json:"last"`
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/items?page=0", nil)
+	items := httpclient.PaginateItems(context.Background(), client, req, httpclient.Pagination[page, int]{
+		Items: func(p page) []int { return p.Items },
+		NextPage: func(r *http.Response, p page) (*http.Request, bool) {
+			next := strconv.Itoa(p.Items[0] + 1)
+			return httpclient.CloneWithQuery(r.Request, "page", next), !p.Last
+		},
+	})
+
+	for range 2 {
+		var got []int
+		for item, err := range items {
+			ExpectThat(t, err).Is(NoError())
+			got = append(got, item)
+		}
+		ExpectThat(t, got).Is(DeepEqual([]int{0, 1, 2}))
+	}
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+)
+
+// InterceptorPanicError is returned for requests aborted because one of the
+// request or response interceptors panicked. The panic is recovered, so a
+// faulty interceptor fails the request only.
+type InterceptorPanicError struct {
+	// Interceptor identifies the interceptor that panicked by its name, as
+	// given to WithNamedRequestInterceptor or WithNamedResponseInterceptor,
+	// or by its type or function name.
+	Interceptor string
+
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *InterceptorPanicError) Error() string {
+	return fmt.Sprintf("interceptor %s panicked: %v", e.Interceptor, e.Value)
+}
+
+// Unwrap returns Value if it is an error.
+func (e *InterceptorPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// interceptRequest invokes i converting any panic into an
+// *InterceptorPanicError.
+func interceptRequest(i RequestInterceptor, r *http.Request) (out *http.Request, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			out, err = r, &InterceptorPanicError{Interceptor: identify(i), Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return i.InterceptRequest(r)
+}
+
+// interceptResponse invokes i converting any panic into an
+// *InterceptorPanicError.
+func interceptResponse(i ResponseInterceptor, r *http.Response) (out *http.Response, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			out, err = r, &InterceptorPanicError{Interceptor: identify(i), Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return i.InterceptResponse(r)
+}
+
+// identify returns a human readable identity of the interceptor i.
+func identify(i any) string {
+	if name := nameOf(i); name != "" {
+		return name
+	}
+
+	for {
+		switch o := i.(type) {
+		case RequestInterceptorOption:
+			i = o.RequestInterceptor
+			continue
+		case ResponseInterceptorOption:
+			i = o.ResponseInterceptor
+			continue
+		case phasedRequestInterceptor:
+			i = o.RequestInterceptor
+			continue
+		case phasedResponseInterceptor:
+			i = o.ResponseInterceptor
+			continue
+		}
+		break
+	}
+
+	if v := reflect.ValueOf(i); v.Kind() == reflect.Func && !v.IsNil() {
+		if f := runtime.FuncForPC(v.Pointer()); f != nil {
+			return f.Name()
+		}
+	}
+
+	return fmt.Sprintf("%T", i)
+}
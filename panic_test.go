@@ -0,0 +1,45 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestInterceptorPanicError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	_, err := client.Get(context.Background(), "/", httpclient.WithNamedRequestInterceptor("buggy",
+		httpclient.RequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+			panic("boom")
+		})))
+
+	var panicErr *httpclient.InterceptorPanicError
+	ExpectThat(t, errors.As(err, &panicErr)).Is(Equal(true))
+	ExpectThat(t, panicErr.Interceptor).Is(Equal("buggy"))
+	ExpectThat(t, panicErr.Value).Is(Equal("boom"))
+	ExpectThat(t, len(panicErr.Stack) > 0).Is(Equal(true))
+
+	errBoom := errors.New("boom")
+	_, err = client.Get(context.Background(), "/", httpclient.WithResponseInterceptorFunc(panicking(errBoom)))
+	ExpectThat(t, err).Is(Error(errBoom))
+	ExpectThat(t, errors.As(err, &panicErr)).Is(Equal(true))
+	ExpectThat(t, strings.HasSuffix(panicErr.Interceptor, "httpclient_test.panicking.func1")).Is(Equal(true))
+}
+
+func panicking(err error) func(*http.Response) (*http.Response, error) {
+	return func(r *http.Response) (*http.Response, error) {
+		panic(err)
+	}
+}
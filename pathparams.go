@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// pathTemplateKey is the context key used to store the path template.
+type pathTemplateKey struct{}
+
+// PathTemplateFromContext returns the path template a request's path has been
+// expanded from using WithPathParams or an empty string if ctx carries none.
+// Unlike the expanded path, the template has a low cardinality, which makes it
+// suitable as a label for metrics.
+func PathTemplateFromContext(ctx context.Context) string {
+	t, _ := ctx.Value(pathTemplateKey{}).(string)
+	return t
+}
+
+// WithPathParams creates a RequestInterceptorOption expanding placeholders of
+// the form {name} in the request's path with the path escaped value of the
+// parameter name, i.e.
+//
+//	c.Get(ctx, "/users/{id}/orders/{orderID}", httpclient.WithPathParams(map[string]string{
+//		"id":      "4711",
+//		"orderID": "a/b",
+//	}))
+//
+// requests /users/4711/orders/a%2Fb. The unexpanded path is stored in the
+// request's context and can be obtained using PathTemplateFromContext; it is
+// also reported as Event.PathTemplate.
+//
+// A placeholder without a corresponding parameter aborts the request with an
+// error. Parameters without a placeholder are ignored.
+func WithPathParams(params map[string]string) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		// url.URL escapes curly braces, so they are restored to obtain the
+		// template as given by the caller.
+		template := braceUnescaper.Replace(r.URL.EscapedPath())
+
+		var b strings.Builder
+		rest := template
+		for {
+			start := strings.IndexByte(rest, '{')
+			if start < 0 {
+				b.WriteString(rest)
+				break
+			}
+			end := strings.IndexByte(rest[start:], '}')
+			if end < 0 {
+				return r, fmt.Errorf("unterminated path parameter in %s", template)
+			}
+			end += start
+
+			name := rest[start+1 : end]
+			value, ok := params[name]
+			if !ok {
+				return r, fmt.Errorf("missing path parameter: %s", name)
+			}
+
+			b.WriteString(rest[:start])
+			b.WriteString(url.PathEscape(value))
+			rest = rest[end+1:]
+		}
+
+		rawPath := b.String()
+		path, err := url.PathUnescape(rawPath)
+		if err != nil {
+			return r, err
+		}
+
+		r.URL.Path = path
+		r.URL.RawPath = rawPath
+
+		return r.WithContext(context.WithValue(r.Context(), pathTemplateKey{}, template)), nil
+	})
+}
+
+var braceUnescaper = strings.NewReplacer("%7B", "{", "%7b", "{", "%7D", "}", "%7d", "}")
@@ -0,0 +1,36 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithPathParams(t *testing.T) {
+	var requestURI string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL), httpclient.WithEvents(4))
+
+	_, err := client.Get(context.Background(), "/users/{id}/orders/{orderID}?q=1", httpclient.WithPathParams(map[string]string{
+		"id":      "4711",
+		"orderID": "a/b c",
+	}))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, requestURI).Is(Equal("/users/4711/orders/a%2Fb%20c?q=1"))
+
+	ev := <-client.Events()
+	ExpectThat(t, ev.PathTemplate).Is(Equal("/users/{id}/orders/{orderID}"))
+
+	_, err = client.Get(context.Background(), "/users/{id}", httpclient.WithPathParams(nil))
+	ExpectThat(t, err).Is(NotNil())
+}
@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Phase defines the phase of the response processing a ResponseInterceptor
+// runs in. Response interceptors run ordered by phase. Within a phase,
+// client-level interceptors run before request-level ones, each in the order
+// they have been given.
+type Phase int
+
+const (
+	// PhasePreValidate is used for interceptors that must see a response
+	// before its status is validated, i.e. to decode error bodies.
+	PhasePreValidate Phase = iota
+
+	// PhaseValidate is used for interceptors validating a response, such as
+	// ExpectedStatusCode. It is the default phase for interceptors that don't
+	// declare one.
+	PhaseValidate
+
+	// PhasePostValidate is used for interceptors that must only see responses
+	// that passed validation, i.e. to decode success bodies.
+	PhasePostValidate
+)
+
+// PhasedResponseInterceptor is implemented by ResponseInterceptors that
+// declare the Phase they run in. ResponseInterceptors not implementing this
+// interface run in PhaseValidate.
+type PhasedResponseInterceptor interface {
+	ResponseInterceptor
+
+	// Phase returns the phase the interceptor runs in.
+	Phase() Phase
+}
+
+// phasedResponseInterceptor assigns a Phase to a ResponseInterceptor.
+type phasedResponseInterceptor struct {
+	ResponseInterceptor
+	phase Phase
+}
+
+func (p phasedResponseInterceptor) Phase() Phase { return p.phase }
+
+// InPhase wraps i in a ResponseInterceptorOption that runs i in phase.
+func InPhase(phase Phase, i ResponseInterceptor) ResponseInterceptorOption {
+	return ResponseInterceptorOption{phasedResponseInterceptor{i, phase}}
+}
+
+// InPhaseFunc wraps f in a ResponseInterceptorOption that runs f in phase.
+func InPhaseFunc(phase Phase, f func(*http.Response) (*http.Response, error)) ResponseInterceptorOption {
+	return InPhase(phase, ResponseInterceptorFunc(f))
+}
+
+// phaseOf returns the Phase i runs in.
+func phaseOf(i ResponseInterceptor) Phase {
+	if o, ok := i.(ResponseInterceptorOption); ok {
+		i = o.ResponseInterceptor
+	}
+
+	if p, ok := i.(PhasedResponseInterceptor); ok {
+		return p.Phase()
+	}
+	return PhaseValidate
+}
+
+// sortByPhase sorts interceptors by their phase keeping the relative order
+// of interceptors in the same phase.
+func sortByPhase(interceptors []ResponseInterceptor) {
+	sort.SliceStable(interceptors, func(i, j int) bool {
+		return phaseOf(interceptors[i]) < phaseOf(interceptors[j])
+	})
+}
+
+// RequestPhase defines the phase of the request preparation a
+// RequestInterceptor runs in. Request interceptors run ordered by phase.
+// Within a phase, client-level interceptors run before request-level ones,
+// each in the order they have been given.
+type RequestPhase int
+
+const (
+	// RequestPhasePrepare is used for interceptors building the request, i.e.
+	// by setting its URL, query parameters, headers or body. It is the default
+	// phase for interceptors that don't declare one.
+	RequestPhasePrepare RequestPhase = iota
+
+	// requestPhaseRewrite is used by WithQueryRewriter. It runs after
+	// RequestPhasePrepare, so rewriters see all query parameters added by
+	// client-level and request-level options.
+	requestPhaseRewrite
+
+	// RequestPhaseAuth is used for interceptors authenticating the request.
+	// They see the fully prepared request, which allows signing it.
+	RequestPhaseAuth
+
+	// RequestPhaseTracing is used for interceptors adding correlation
+	// information such as request IDs or trace context. They run last, so
+	// they see the request as it is sent.
+	RequestPhaseTracing
+)
+
+// PhasedRequestInterceptor is implemented by RequestInterceptors that
+// declare the RequestPhase they run in. RequestInterceptors not implementing
+// this interface run in RequestPhasePrepare.
+type PhasedRequestInterceptor interface {
+	RequestInterceptor
+
+	// RequestPhase returns the phase the interceptor runs in.
+	RequestPhase() RequestPhase
+}
+
+// phasedRequestInterceptor assigns a RequestPhase to a RequestInterceptor.
+type phasedRequestInterceptor struct {
+	RequestInterceptor
+	phase RequestPhase
+}
+
+func (p phasedRequestInterceptor) RequestPhase() RequestPhase { return p.phase }
+
+// InRequestPhase wraps i in a RequestInterceptorOption that runs i in phase.
+func InRequestPhase(phase RequestPhase, i RequestInterceptor) RequestInterceptorOption {
+	return RequestInterceptorOption{phasedRequestInterceptor{i, phase}}
+}
+
+// InRequestPhaseFunc wraps f in a RequestInterceptorOption that runs f in
+// phase.
+func InRequestPhaseFunc(phase RequestPhase, f func(*http.Request) (*http.Request, error)) RequestInterceptorOption {
+	return InRequestPhase(phase, RequestInterceptorFunc(f))
+}
+
+// requestPhaseOf returns the RequestPhase i runs in.
+func requestPhaseOf(i RequestInterceptor) RequestPhase {
+	if o, ok := i.(RequestInterceptorOption); ok {
+		i = o.RequestInterceptor
+	}
+
+	if p, ok := i.(PhasedRequestInterceptor); ok {
+		return p.RequestPhase()
+	}
+	return RequestPhasePrepare
+}
+
+// sortByRequestPhase sorts interceptors by their phase keeping the relative
+// order of interceptors in the same phase.
+func sortByRequestPhase(interceptors []RequestInterceptor) {
+	sort.SliceStable(interceptors, func(i, j int) bool {
+		return requestPhaseOf(interceptors[i]) < requestPhaseOf(interceptors[j])
+	})
+}
+
+// InterceptorOrder defines the order of client-level and request-level
+// response interceptors running in the same Phase.
+type InterceptorOrder int
+
+const (
+	// ClientInterceptorsFirst runs client-level response interceptors before
+	// request-level ones, just like request interceptors. This is the
+	// default, which allows i.e. a client-level interceptor buffering
+	// response bodies to feed request-level decoding.
+	ClientInterceptorsFirst InterceptorOrder = iota
+
+	// RequestInterceptorsFirst runs request-level response interceptors
+	// before client-level ones, so requests can handle a response before any
+	// generic client-level handling.
+	RequestInterceptorsFirst
+)
+
+// WithResponseInterceptorOrder creates a ClientOption setting the order of
+// client-level and request-level response interceptors running in the same
+// Phase. Phases always take precedence over order.
+func WithResponseInterceptorOrder(order InterceptorOrder) ClientOption {
+	return clientConfigOption(func(c *Client) {
+		c.resOrder = order
+	})
+}
@@ -0,0 +1,110 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestInPhase(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer testServer.Close()
+
+	var calls []string
+
+	record := func(name string) func(*http.Response) (*http.Response, error) {
+		return func(r *http.Response) (*http.Response, error) {
+			calls = append(calls, name)
+			return r, nil
+		}
+	}
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+		httpclient.InPhaseFunc(httpclient.PhasePostValidate, record("client-post")),
+	)
+
+	_, err := client.Get(context.Background(), "/",
+		httpclient.InPhaseFunc(httpclient.PhasePreValidate, record("request-pre")),
+		httpclient.WithResponseInterceptorFunc(record("request-default")),
+	)
+
+	ExpectThat(t, err).Is(NotNil())
+	ExpectThat(t, calls).Is(DeepEqual([]string{"request-pre"}))
+}
+
+func TestInRequestPhase(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var calls []string
+
+	record := func(name string) func(*http.Request) (*http.Request, error) {
+		return func(r *http.Request) (*http.Request, error) {
+			calls = append(calls, name)
+			return r, nil
+		}
+	}
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.InRequestPhaseFunc(httpclient.RequestPhaseTracing, record("client-tracing")),
+		httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, record("client-auth")),
+		httpclient.WithRequestInterceptorFunc(record("client-default")),
+	)
+
+	_, err := client.Get(context.Background(), "/",
+		httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, record("request-auth")),
+		httpclient.WithRequestInterceptorFunc(record("request-default")),
+	)
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, calls).Is(DeepEqual([]string{
+		"client-default",
+		"request-default",
+		"client-auth",
+		"request-auth",
+		"client-tracing",
+	}))
+}
+
+func TestWithResponseInterceptorOrder(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var calls []string
+
+	record := func(name string) func(*http.Response) (*http.Response, error) {
+		return func(r *http.Response) (*http.Response, error) {
+			calls = append(calls, name)
+			return r, nil
+		}
+	}
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithResponseInterceptorOrder(httpclient.RequestInterceptorsFirst),
+		httpclient.WithResponseInterceptorFunc(record("client-1")),
+		httpclient.WithResponseInterceptorFunc(record("client-2")),
+		httpclient.InPhaseFunc(httpclient.PhasePostValidate, record("client-post")),
+	)
+
+	_, err := client.Get(context.Background(), "/",
+		httpclient.WithResponseInterceptorFunc(record("request-1")),
+		httpclient.WithResponseInterceptorFunc(record("request-2")),
+	)
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, calls).Is(DeepEqual([]string{"request-1", "request-2", "client-1", "client-2", "client-post"}))
+}
@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// PollPolicy configures the requests sent by Poll.
+type PollPolicy struct {
+	// Interval is the time to wait between two successful polls. It defaults
+	// to 1s. A negative Interval polls again immediately, which is useful
+	// for long-polling endpoints holding requests until new data is
+	// available.
+	Interval time.Duration
+
+	// MaxBackoff limits the time to wait before polling again after a failed
+	// poll. The time doubles for every consecutive failure, starting with
+	// Interval or 1s, whichever is greater. It defaults to 1m.
+	MaxBackoff time.Duration
+
+	// Resume returns the RequestOption resuming the next poll where the
+	// response of the previous one left off, i.e. by adding a cursor to the
+	// query. A nil option keeps the option used for the previous poll. Resume
+	// defaults to sending the response's ETag header as If-None-Match.
+	Resume func(*http.Response) RequestOption
+}
+
+// PollResult is delivered by Poll for every item produced and for every
+// failed poll.
+type PollResult[T any] struct {
+	Item T
+	Err  error
+}
+
+// Poll repeatedly sends GET requests for url using c and opts and delivers the
+// items produced by produce from the responses over the returned channel
+// until ctx is done, at which point the channel is closed. Each poll is
+// resumed based on the previous response as configured by p.Resume.
+//
+// Like for Iterate, produce runs in PhasePostValidate. Responses with a 304
+// status code - sent to requests carrying If-None-Match when nothing has
+// changed - produce no items. Errors that occur while sending a request or
+// producing items are delivered as a PollResult with a non-nil Err and
+// polling continues after a backoff.
+func Poll[T any](ctx context.Context, c *Client, url string, p PollPolicy, produce Producer[T], opts ...RequestOption) <-chan PollResult[T] {
+	if p.Interval == 0 {
+		p.Interval = time.Second
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = time.Minute
+	}
+	if p.Resume == nil {
+		p.Resume = resumeWithETag
+	}
+
+	clock := c.clock
+	if clock == nil {
+		clock = ClockFromContext(ctx)
+	}
+
+	results := make(chan PollResult[T])
+
+	go func() {
+		defer close(results)
+
+		var resume RequestOption
+		backoff := max(p.Interval, time.Second)
+
+		deliver := func(r PollResult[T]) bool {
+			select {
+			case results <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			reqOpts := opts[:len(opts):len(opts)]
+			if resume != nil {
+				reqOpts = append(reqOpts, resume)
+			}
+			reqOpts = append(reqOpts, InPhaseFunc(PhasePostValidate, func(r *http.Response) (*http.Response, error) {
+				if o := p.Resume(r); o != nil {
+					resume = o
+				}
+				if r.StatusCode == http.StatusNotModified {
+					return r, nil
+				}
+				return r, produce(r, func(item T) bool {
+					return deliver(PollResult[T]{Item: item})
+				})
+			}))
+
+			_, err := c.Execute(ctx, http.MethodGet, url, reqOpts...)
+			if ctx.Err() != nil {
+				return
+			}
+
+			wait := p.Interval
+			if err != nil {
+				if !deliver(PollResult[T]{Err: err}) {
+					return
+				}
+				wait = backoff
+				backoff = min(backoff*2, p.MaxBackoff)
+			} else {
+				backoff = max(p.Interval, time.Second)
+			}
+
+			if wait > 0 {
+				if sleep(ctx, clock, wait) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return results
+}
+
+// resumeWithETag is the default PollPolicy.Resume implementation.
+func resumeWithETag(r *http.Response) RequestOption {
+	if etag := r.Header.Get("ETag"); etag != "" {
+		return WithRequestHeader("If-None-Match", etag)
+	}
+	return nil
+}
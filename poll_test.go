@@ -0,0 +1,87 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestPoll(t *testing.T) {
+	var requests atomic.Int32
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		switch {
+		case n == 3:
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.Header.Get("If-None-Match") == `"v1"` && n == 2:
+			w.WriteHeader(http.StatusNotModified)
+		case r.Header.Get("If-None-Match") == `"v1"`:
+			w.Header().Set("ETag", `"v2"`)
+			w.Write([]byte("[3]"))
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("[1,2]"))
+		}
+	}))
+	defer testServer.Close()
+
+	clock := httpclienttest.NewFakeClock(time.Now())
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithClock(clock),
+		httpclient.ExpectedStatusCode(http.StatusOK, http.StatusNotModified),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := httpclient.Poll(ctx, client, "/items", httpclient.PollPolicy{Interval: time.Second},
+		func(r *http.Response, yield func(int) bool) error {
+			var items []int
+			if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+				return err
+			}
+			for _, item := range items {
+				if !yield(item) {
+					return nil
+				}
+			}
+			return nil
+		},
+	)
+
+	// next returns the next result, advancing the clock whenever the poller
+	// waits.
+	next := func() httpclient.PollResult[int] {
+		for {
+			select {
+			case r := <-results:
+				return r
+			default:
+				if clock.Waiters() > 0 {
+					clock.Advance(time.Minute)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+
+	ExpectThat(t, next().Item).Is(Equal(1))
+	ExpectThat(t, next().Item).Is(Equal(2))
+	ExpectThat(t, next().Err).Is(Error(httpclient.ErrUnexpectedStatus))
+	ExpectThat(t, next().Item).Is(Equal(3))
+	ExpectThat(t, requests.Load()).Is(Equal(int32(4)))
+
+	cancel()
+	for range results {
+	}
+}
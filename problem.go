@@ -0,0 +1,248 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details documents.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails implements a RFC 7807 problem details document. Members not
+// defined by the RFC are kept in Extensions.
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// MarshalJSON marshals p with its Extensions as top-level members.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+
+	set := func(k, v string) {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	set("type", p.Type)
+	set("title", p.Title)
+	set("detail", p.Detail)
+	set("instance", p.Instance)
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON unmarshals data into p collecting unknown members in
+// Extensions.
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	*p = ProblemDetails{}
+
+	for k, raw := range m {
+		var err error
+		switch k {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			err = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			err = json.Unmarshal(raw, &p.Instance)
+		default:
+			var v any
+			err = json.Unmarshal(raw, &v)
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]any)
+			}
+			p.Extensions[k] = v
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP writes p as the response to r, which makes ProblemDetails usable
+// as a http.Handler. A p without a Status is sent with status code 500.
+func (p *ProblemDetails) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.Status == 0 {
+		withStatus := *p
+		withStatus.Status = http.StatusInternalServerError
+		p = &withStatus
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	w.Write(b)
+}
+
+// ProblemOptions customizes the ProblemDetails created by NewProblemDetails.
+type ProblemOptions struct {
+	// Type is the problem type URI to use. If empty, the type of an upstream
+	// problem is used or the member is omitted.
+	Type string
+
+	// Instance is the URI identifying the occurrence of the problem.
+	Instance string
+
+	// StatusMapper maps the upstream status code to the status reported in
+	// the problem. It defaults to DefaultProblemStatus.
+	StatusMapper func(upstreamStatus int) int
+
+	// ExposeDetail includes the upstream problem's detail or the message of
+	// the error in the problem. As these may leak internal information they
+	// are omitted by default.
+	ExposeDetail bool
+
+	// ExposeExtensions lists the extension members of an upstream problem
+	// which are propagated. Any other extension member is omitted, so new
+	// upstream members are never leaked by accident.
+	ExposeExtensions []string
+}
+
+// DefaultProblemStatus maps upstreamStatus to the status reported by a
+// gateway: client errors are propagated as is while any other status is
+// reported as 502 Bad Gateway.
+func DefaultProblemStatus(upstreamStatus int) int {
+	if upstreamStatus >= 400 && upstreamStatus < 500 {
+		return upstreamStatus
+	}
+	return http.StatusBadGateway
+}
+
+// NewProblemDetails converts the outcome of a request execution - the
+// response and error returned by one of the Client's request methods - into
+// a ProblemDetails to be sent to a downstream caller.
+//
+// If res carries a problem details document that has been buffered using
+// BufferProblemDetails, the upstream problem is propagated subject to the
+// members exposed by opts. If the upstream status is mapped to a different
+// status, the title of the upstream problem is replaced with the status text
+// of the mapped status. If no response has been received, the
+// problem reports 504 Gateway Timeout for timeouts and 502 Bad Gateway
+// otherwise.
+func NewProblemDetails(res *http.Response, err error, opts ProblemOptions) *ProblemDetails {
+	mapStatus := opts.StatusMapper
+	if mapStatus == nil {
+		mapStatus = DefaultProblemStatus
+	}
+
+	p := &ProblemDetails{}
+
+	if res != nil {
+		p.Status = mapStatus(res.StatusCode)
+		p.Extensions = make(map[string]any, len(opts.ExposeExtensions)+1)
+
+		if upstream, ok := readProblem(res); ok {
+			p.Type = upstream.Type
+			if p.Status == res.StatusCode {
+				p.Title = upstream.Title
+			}
+			if opts.ExposeDetail {
+				p.Detail = upstream.Detail
+			}
+			for _, k := range opts.ExposeExtensions {
+				if v, ok := upstream.Extensions[k]; ok {
+					p.Extensions[k] = v
+				}
+			}
+		}
+
+		p.Extensions["upstreamStatus"] = res.StatusCode
+	} else if IsTimeout(err) {
+		p.Status = http.StatusGatewayTimeout
+	} else {
+		p.Status = http.StatusBadGateway
+	}
+
+	if opts.Type != "" {
+		p.Type = opts.Type
+	}
+
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	if opts.ExposeDetail && p.Detail == "" && err != nil {
+		p.Detail = err.Error()
+	}
+
+	p.Instance = opts.Instance
+
+	return p
+}
+
+// BufferProblemDetails creates a ResponseInterceptorOption running in
+// PhasePreValidate that buffers the body of responses carrying a problem
+// details document. This keeps the body readable after the request has been
+// executed, so NewProblemDetails can propagate the upstream problem.
+func BufferProblemDetails() ResponseInterceptorOption {
+	return InPhaseFunc(PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		if !isProblem(r) {
+			return r, nil
+		}
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return r, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(b))
+
+		return r, nil
+	})
+}
+
+func isProblem(r *http.Response) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mt == ProblemContentType
+}
+
+// readProblem reads a problem details document body from r. The body is
+// restored after reading.
+func readProblem(r *http.Response) (ProblemDetails, bool) {
+	var p ProblemDetails
+
+	if r.Body == nil || !isProblem(r) {
+		return p, false
+	}
+
+	b, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return p, false
+	}
+
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, false
+	}
+
+	return p, true
+}
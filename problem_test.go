@@ -0,0 +1,109 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestNewProblemDetails(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", httpclient.ProblemContentType)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"type":"https://example.com/conflict","title":"Conflict","status":409,"detail":"internal detail","resource":"42","trace":"abc"}`))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+		httpclient.BufferProblemDetails(),
+	)
+
+	t.Run("upstream_problem", func(t *testing.T) {
+		res, err := client.Get(context.Background(), "/")
+		ExpectThat(t, err).Is(NotNil())
+
+		p := httpclient.NewProblemDetails(res, err, httpclient.ProblemOptions{
+			Instance:         "/orders/42",
+			ExposeExtensions: []string{"resource"},
+		})
+
+		ExpectThat(t, p).Is(DeepEqual(&httpclient.ProblemDetails{
+			Type:     "https://example.com/conflict",
+			Title:    "Conflict",
+			Status:   http.StatusConflict,
+			Instance: "/orders/42",
+			Extensions: map[string]any{
+				"resource":       "42",
+				"upstreamStatus": http.StatusConflict,
+			},
+		}))
+	})
+
+	t.Run("remapped_status", func(t *testing.T) {
+		res, err := client.Get(context.Background(), "/")
+		ExpectThat(t, err).Is(NotNil())
+
+		p := httpclient.NewProblemDetails(res, err, httpclient.ProblemOptions{
+			StatusMapper: func(int) int { return http.StatusBadGateway },
+		})
+
+		ExpectThat(t, p).Is(DeepEqual(&httpclient.ProblemDetails{
+			Type:   "https://example.com/conflict",
+			Title:  "Bad Gateway",
+			Status: http.StatusBadGateway,
+			Extensions: map[string]any{
+				"upstreamStatus": http.StatusConflict,
+			},
+		}))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		p := httpclient.NewProblemDetails(nil, context.DeadlineExceeded, httpclient.ProblemOptions{})
+		ExpectThat(t, p.Status).Is(Equal(http.StatusGatewayTimeout))
+		ExpectThat(t, p.Title).Is(Equal("Gateway Timeout"))
+		ExpectThat(t, p.Detail).Is(Equal(""))
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		p := httpclient.NewProblemDetails(nil, errors.New("connection refused"), httpclient.ProblemOptions{ExposeDetail: true})
+
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		ExpectThat(t, rec.Code).Is(Equal(http.StatusBadGateway))
+		ExpectThat(t, rec.Header().Get("Content-Type")).Is(Equal(httpclient.ProblemContentType))
+
+		var got map[string]any
+		ExpectThat(t, json.Unmarshal(rec.Body.Bytes(), &got)).Is(NoError())
+		ExpectThat(t, got).Is(DeepEqual(map[string]any{
+			"title":  "Bad Gateway",
+			"status": float64(http.StatusBadGateway),
+			"detail": "connection refused",
+		}))
+	})
+
+	t.Run("serveWithoutStatus", func(t *testing.T) {
+		p := &httpclient.ProblemDetails{Title: "Internal error"}
+
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		ExpectThat(t, rec.Code).Is(Equal(http.StatusInternalServerError))
+
+		var got map[string]any
+		ExpectThat(t, json.Unmarshal(rec.Body.Bytes(), &got)).Is(NoError())
+		ExpectThat(t, got).Is(DeepEqual(map[string]any{
+			"title":  "Internal error",
+			"status": float64(http.StatusInternalServerError),
+		}))
+		ExpectThat(t, p.Status).Is(Equal(0))
+	})
+}
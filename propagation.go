@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// inboundHeadersKey is the context key used to store inbound headers.
+type inboundHeadersKey struct{}
+
+// ContextWithInboundHeaders returns a copy of ctx carrying a copy of h as the
+// headers of the inbound request being served. Headers stored this way are
+// forwarded by WithHeaderPropagation.
+func ContextWithInboundHeaders(ctx context.Context, h http.Header) context.Context {
+	return context.WithValue(ctx, inboundHeadersKey{}, h.Clone())
+}
+
+// InboundHeadersFromContext returns the inbound headers stored in ctx or nil
+// if ctx carries none.
+func InboundHeadersFromContext(ctx context.Context) http.Header {
+	h, _ := ctx.Value(inboundHeadersKey{}).(http.Header)
+	return h
+}
+
+// InboundHeaders is a middleware for http.Handlers storing the headers of
+// every inbound request in the request's context using
+// ContextWithInboundHeaders. Requests sent with the request's context by a
+// Client using WithHeaderPropagation then forward the propagated headers.
+func InboundHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(ContextWithInboundHeaders(r.Context(), r.Header)))
+	})
+}
+
+// WithHeaderPropagation creates a RequestInterceptorOption forwarding the
+// headers named in keys from the inbound request stored in the request's
+// context, i.e. a tenant ID or an Authorization header. Headers already set
+// on the outgoing request are not overwritten. Requests whose context carries
+// no inbound headers are left untouched.
+//
+// Only the headers named explicitly are forwarded, so credentials are never
+// propagated by accident.
+func WithHeaderPropagation(keys ...string) RequestInterceptorOption {
+	canonical := make([]string, len(keys))
+	for i, k := range keys {
+		canonical[i] = http.CanonicalHeaderKey(k)
+	}
+
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		inbound := InboundHeadersFromContext(r.Context())
+		if inbound == nil {
+			return r, nil
+		}
+
+		for _, k := range canonical {
+			if _, ok := r.Header[k]; ok {
+				continue
+			}
+			if vs, ok := inbound[k]; ok {
+				r.Header[k] = append([]string(nil), vs...)
+			}
+		}
+
+		return r, nil
+	})
+}
@@ -0,0 +1,45 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithHeaderPropagation(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(upstream.URL),
+		httpclient.WithHeaderPropagation("x-tenant-id", "X-Region"),
+	)
+
+	var err error
+	service := httpclient.InboundHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err = client.Get(r.Context(), "/", httpclient.WithRequestHeader("X-Region", "eu"))
+	}))
+
+	in := httptest.NewRequest(http.MethodGet, "/", nil)
+	in.Header.Set("X-Tenant-Id", "acme")
+	in.Header.Set("X-Region", "us")
+	in.Header.Set("Authorization", "Bearer secret")
+	service.ServeHTTP(httptest.NewRecorder(), in)
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("X-Tenant-Id")).Is(Equal("acme"))
+	ExpectThat(t, received.Get("X-Region")).Is(Equal("eu"))
+	ExpectThat(t, received.Get("Authorization")).Is(Equal(""))
+
+	_, err = client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, received.Get("X-Tenant-Id")).Is(Equal(""))
+}
@@ -0,0 +1,11 @@
+module github.com/halimath/httpclient/protohttpclient
+
+go 1.23.0
+
+require (
+	github.com/halimath/expect-go v0.0.0-20220913172635-5e8906ded1a7
+	github.com/halimath/httpclient v0.1.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require github.com/deckarep/golang-set/v2 v2.1.0 // indirect
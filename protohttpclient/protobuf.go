@@ -0,0 +1,98 @@
+// Package protohttpclient integrates httpclient with Protocol Buffers for
+// services exchanging binary encoded messages over plain HTTP. It is provided
+// as a separate module so that applications not using Protocol Buffers don't
+// depend on it.
+package protohttpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/halimath/httpclient"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType is the content type of binary encoded Protocol Buffers
+// messages.
+const ContentType = "application/x-protobuf"
+
+// WithProtobuf creates a RequestInterceptorOption that uses msg encoded in the
+// Protocol Buffers binary format as the request's body. It sets the
+// Content-Type and Content-Length headers as well as the request's GetBody
+// function, so the request can be retried. If the request had a previous
+// non-nil Body this value is closed before. Any error produced by
+// proto.Marshal is returned and aborts the request.
+func WithProtobuf(msg proto.Message) httpclient.RequestInterceptorOption {
+	return httpclient.WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		b, err := proto.Marshal(msg)
+		if err != nil {
+			return r, err
+		}
+
+		if r.Body != nil {
+			if err := r.Body.Close(); err != nil {
+				return r, err
+			}
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(b))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}
+		r.ContentLength = int64(len(b))
+		r.Header.Set("Content-Type", ContentType)
+
+		return r, nil
+	})
+}
+
+// ForProtobuf creates an httpclient.Option that decodes the response body
+// into msg. Like httpclient.ForJSON, it adds an Accept header requesting
+// ContentType and fails with an *httpclient.Error of kind
+// httpclient.ErrDecode if the response has a different content type - both
+// application/x-protobuf and application/protobuf are accepted - or if
+// the body can't be unmarshaled. The response body remains readable after
+// it has been decoded.
+func ForProtobuf(msg proto.Message) httpclient.Option {
+	return &forProtobuf{
+		RequestInterceptorOption: httpclient.WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+			r.Header.Add("Accept", ContentType)
+			return r, nil
+		}),
+		msg: msg,
+	}
+}
+
+// forProtobuf embeds the RequestInterceptorOption setting the Accept header,
+// which makes it an httpclient.Option, and adds the ResponseInterceptor
+// decoding the body.
+type forProtobuf struct {
+	httpclient.RequestInterceptorOption
+	msg proto.Message
+}
+
+func (p *forProtobuf) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct != ContentType && ct != "application/protobuf" {
+		return r, httpclient.DecodeError(r, fmt.Errorf("expected Protocol Buffers response but got %s", r.Header.Get("Content-Type")))
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return r, err
+	}
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(b), r.Body}
+
+	if err := proto.Unmarshal(b, p.msg); err != nil {
+		return r, httpclient.DecodeError(r, err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,61 @@
+package protohttpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/protohttpclient"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtobuf(t *testing.T) {
+	var contentType, accept string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		accept = r.Header.Get("Accept")
+
+		if r.URL.Path == "/text" {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello"))
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		var in wrapperspb.StringValue
+		proto.Unmarshal(b, &in)
+
+		out, _ := proto.Marshal(wrapperspb.String("hello, " + in.GetValue()))
+		w.Header().Set("Content-Type", protohttpclient.ContentType)
+		w.Write(out)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("success", func(t *testing.T) {
+		var got wrapperspb.StringValue
+		_, err := client.Post(context.Background(), "/",
+			protohttpclient.WithProtobuf(wrapperspb.String("world")),
+			protohttpclient.ForProtobuf(&got),
+		)
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, got.GetValue()).Is(Equal("hello, world"))
+		ExpectThat(t, contentType).Is(Equal(protohttpclient.ContentType))
+		ExpectThat(t, accept).Is(Equal(protohttpclient.ContentType))
+	})
+
+	t.Run("unexpected content type", func(t *testing.T) {
+		var got wrapperspb.StringValue
+		_, err := client.Get(context.Background(), "/text", protohttpclient.ForProtobuf(&got))
+
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+	})
+}
@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// QueryRewriter defines a function that rewrites the query parameters of a
+// request. The function may modify and return q or return a new set of
+// values.
+type QueryRewriter func(q url.Values) url.Values
+
+// WithQueryRewriter creates a RequestInterceptorOption that applies rewriters
+// to the query parameters of a request in the given order. The rewritten
+// query is encoded with its keys sorted, so every request leaves the client
+// with a canonical query string.
+//
+// The rewriters run after all interceptors of RequestPhasePrepare, so they
+// see parameters added by request-level options such as WithQueryParam even
+// when given to the client, and before those of RequestPhaseAuth, so signing
+// interceptors see the rewritten query.
+//
+// Queries that cannot be parsed, i.e. because they use semicolons to separate
+// parameters, are left untouched and the request is sent as is.
+func WithQueryRewriter(rewriters ...QueryRewriter) RequestInterceptorOption {
+	return InRequestPhaseFunc(requestPhaseRewrite, func(r *http.Request) (*http.Request, error) {
+		q, err := url.ParseQuery(r.URL.RawQuery)
+		if err != nil {
+			return r, nil
+		}
+
+		for _, rewrite := range rewriters {
+			q = rewrite(q)
+		}
+
+		r.URL.RawQuery = q.Encode()
+
+		return r, nil
+	})
+}
+
+// DropQueryParams creates a QueryRewriter removing all parameters named in
+// names.
+func DropQueryParams(names ...string) QueryRewriter {
+	return func(q url.Values) url.Values {
+		for _, n := range names {
+			q.Del(n)
+		}
+		return q
+	}
+}
+
+// RenameQueryParam creates a QueryRewriter renaming parameter from to to. Any
+// values of from are appended to existing values of to.
+func RenameQueryParam(from, to string) QueryRewriter {
+	return func(q url.Values) url.Values {
+		if vs, ok := q[from]; ok {
+			delete(q, from)
+			q[to] = append(q[to], vs...)
+		}
+		return q
+	}
+}
+
+// LowercaseQueryKeys is a QueryRewriter converting all parameter names to
+// lower case. Values of parameters whose names only differ in case are
+// merged in the order of the original names sorted byte-wise.
+func LowercaseQueryKeys(q url.Values) url.Values {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	l := make(url.Values, len(q))
+	for _, k := range keys {
+		lk := strings.ToLower(k)
+		l[lk] = append(l[lk], q[k]...)
+	}
+	return l
+}
+
+// WithQuery creates a RequestInterceptorOption adding the parameters in q to
+// the query of a request. Parameters already present in the request's URL are
+// kept, so values for the same key are appended. Keys and values are escaped
+// as needed.
+func WithQuery(q url.Values) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		encoded := q.Encode()
+		if encoded == "" {
+			return r, nil
+		}
+
+		if r.URL.RawQuery == "" {
+			r.URL.RawQuery = encoded
+		} else {
+			r.URL.RawQuery += "&" + encoded
+		}
+
+		return r, nil
+	})
+}
+
+// WithQueryParam is like WithQuery for a single parameter.
+func WithQueryParam(key, value string) RequestInterceptorOption {
+	return WithQuery(url.Values{key: {value}})
+}
@@ -0,0 +1,164 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithQueryRewriter(t *testing.T) {
+	var rawQuery string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithQueryRewriter(
+			httpclient.DropQueryParams("debug"),
+			httpclient.RenameQueryParam("q", "query"),
+			httpclient.LowercaseQueryKeys,
+		),
+	)
+
+	_, err := client.Get(context.Background(), "/search?q=go&debug=1&Page=2&a=b")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, rawQuery).Is(Equal("a=b&page=2&query=go"))
+}
+
+func TestWithQueryRewriter_mergeAndUnparsable(t *testing.T) {
+	var rawQuery string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithQueryRewriter(httpclient.LowercaseQueryKeys),
+	)
+
+	for i := 0; i < 10; i++ {
+		_, err := client.Get(context.Background(), "/search?tag=c&Tag=b&TAG=a")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, rawQuery).Is(Equal("tag=a&tag=b&tag=c"))
+	}
+
+	_, err := client.Get(context.Background(), "/search?a=1;b=2")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, rawQuery).Is(Equal("a=1;b=2"))
+}
+
+func TestWithQueryRewriter_requestLevelParams(t *testing.T) {
+	var rawQuery string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithQueryRewriter(
+			httpclient.DropQueryParams("debug"),
+			httpclient.RenameQueryParam("q", "query"),
+		),
+	)
+
+	type params struct {
+		Query string `url:"q"`
+		Debug string `url:"debug"`
+	}
+
+	_, err := client.Get(context.Background(), "/search/{id}",
+		httpclient.WithPathParams(map[string]string{"id": "1"}),
+		httpclient.WithQueryParam("debug", "1"),
+		httpclient.WithQuery(url.Values{"page": {"2"}}),
+		httpclient.WithQueryStruct(params{Query: "go", Debug: "2"}),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, rawQuery).Is(Equal("page=2&query=go"))
+}
+
+func TestWithQuery(t *testing.T) {
+	var rawQuery string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	_, err := client.Get(context.Background(), "/search?page=2",
+		httpclient.WithQuery(url.Values{"tag": {"a&b", "c"}}),
+		httpclient.WithQueryParam("q", "go lang"),
+		httpclient.WithQueryParam("page", "3"),
+	)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, rawQuery).Is(Equal("page=2&tag=a%26b&tag=c&q=go+lang&page=3"))
+}
+
+func TestWithQueryStruct(t *testing.T) {
+	var query url.Values
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	type Paging struct {
+		Page  *int `url:"page"`
+		Limit int  `url:"limit,omitempty"`
+	}
+
+	type Search struct {
+		Paging
+		Query   string    `url:"q"`
+		Tags    []string  `url:"tag,omitempty"`
+		Since   time.Time `url:"since,omitempty" layout:"2006-01-02"`
+		Until   time.Time `url:"until,unix"`
+		Score   float64
+		Debug   bool `url:"-"`
+		Missing *string
+	}
+
+	page := 2
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	_, err := client.Get(context.Background(), "/search", httpclient.WithQueryStruct(&Search{
+		Paging: Paging{Page: &page},
+		Query:  "go",
+		Tags:   []string{"a", "b"},
+		Since:  time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Until:  time.Unix(1700000000, 0),
+		Score:  0.5,
+		Debug:  true,
+	}))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, query).Is(DeepEqual(url.Values{
+		"page":  {"2"},
+		"q":     {"go"},
+		"tag":   {"a", "b"},
+		"since": {"2024-05-01"},
+		"until": {"1700000000"},
+		"Score": {"0.5"},
+	}))
+
+	_, err = client.Get(context.Background(), "/search", httpclient.WithQueryStruct(struct{ C chan int }{}))
+	ExpectThat(t, err).Is(NotNil())
+}
@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithQueryStruct creates a RequestInterceptorOption that encodes the exported
+// fields of the struct v (or a pointer to it) as query parameters and adds
+// them to the request like WithQuery does.
+//
+// Fields are encoded using their "url" struct tag, which works like the
+// "json" tag of encoding/json:
+//
+//	type Search struct {
+//		Query string    `url:"q"`
+//		Tags  []string  `url:"tag,omitempty"`
+//		Since time.Time `url:"since,omitempty" layout:"2006-01-02"`
+//		Page  *int      `url:"page"`
+//		Debug bool      `url:"-"`
+//	}
+//
+// Fields without a tag use the field's name. The omitempty option drops
+// fields with a zero value; nil pointers are always dropped. Slices and
+// arrays produce one parameter per element. time.Time values are formatted
+// using the layout given in the "layout" tag, defaulting to time.RFC3339, or
+// as seconds since the epoch with the unix option. Fields of embedded structs
+// are encoded as if they were fields of v. Values implementing
+// encoding.TextMarshaler are encoded using MarshalText.
+//
+// Any error produced while encoding v is returned when the request is
+// executed.
+func WithQueryStruct(v any) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		q, err := encodeQueryStruct(v)
+		if err != nil {
+			return r, err
+		}
+
+		return WithQuery(q).InterceptRequest(r)
+	})
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+func encodeQueryStruct(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("query struct: expected struct but got %T", v)
+	}
+
+	q := make(url.Values)
+	if err := encodeQueryFields(q, rv); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func encodeQueryFields(q url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		tag := f.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		omitEmpty := hasTagOption(opts, "omitempty")
+		fv := rv.Field(i)
+
+		if f.Anonymous && name == "" {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType && !reflect.PointerTo(fv.Type()).Implements(textMarshalerType) {
+				if err := encodeQueryFields(q, fv); err != nil {
+					return err
+				}
+				continue
+			}
+			fv = rv.Field(i)
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := range fv.Len() {
+				s, err := formatQueryValue(fv.Index(j), f.Tag, opts)
+				if err != nil {
+					return fmt.Errorf("query struct: field %s: %w", f.Name, err)
+				}
+				q.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := formatQueryValue(fv, f.Tag, opts)
+		if err != nil {
+			return fmt.Errorf("query struct: field %s: %w", f.Name, err)
+		}
+		q.Add(name, s)
+	}
+
+	return nil
+}
+
+func formatQueryValue(v reflect.Value, tag reflect.StructTag, opts string) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if hasTagOption(opts, "unix") {
+			return strconv.FormatInt(t.Unix(), 10), nil
+		}
+		layout := tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return t.Format(layout), nil
+	}
+
+	if m, ok := textMarshaler(v); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		// Only byte slices reach this point.
+		return string(v.Bytes()), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if v.Type().Implements(textMarshalerType) {
+		return v.Interface().(encoding.TextMarshaler), true
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		return v.Addr().Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
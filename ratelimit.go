@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxIdleBuckets is the number of per-host buckets kept before idle ones are
+// removed.
+const maxIdleBuckets = 1024
+
+// WithPerHostRateLimit creates a ClientOption limiting the rate of requests
+// sent to each host to rps requests per second with bursts of up to burst
+// requests. Every host - including its port - gets its own token bucket, so
+// saturating one API never delays requests to another. Requests exceeding
+// the rate wait until a token is available or their context is done. The
+// limit applies to every attempt, so retries made by WithRetry given before
+// this option are limited as well.
+//
+// rps must be positive; burst defaults to 1 if it is not.
+func WithPerHostRateLimit(rps float64, burst int) ClientOption {
+	if rps <= 0 {
+		return invalidOption{fmt.Errorf("invalid rate limit: %v requests per second", rps)}
+	}
+
+	return &perHostRateLimit{
+		rps:     rps,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+type perHostRateLimit struct {
+	rps   float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket is the bucket of a host. tokens becomes negative if requests
+// are waiting for tokens.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (*perHostRateLimit) clientOpt() {}
+
+func (l *perHostRateLimit) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := r.Context()
+	clock := ClockFromContext(ctx)
+	host := strings.ToLower(r.URL.Host)
+
+	if wait := l.reserve(host, clock.Now()); wait > 0 {
+		if err := sleep(ctx, clock, wait); err != nil {
+			l.cancel(host)
+			return nil, err
+		}
+	}
+
+	return next(r)
+}
+
+// reserve takes a token from the bucket of host and returns the time to wait
+// until it becomes available.
+func (l *perHostRateLimit) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[host]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.pruneLocked(now)
+		}
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[host] = b
+	}
+
+	l.refillLocked(b, now)
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.rps * float64(time.Second))
+}
+
+// cancel returns the token reserved for a request that has been canceled
+// while waiting.
+func (l *perHostRateLimit) cancel(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[host]; ok {
+		b.tokens = min(b.tokens+1, l.burst)
+	}
+}
+
+func (l *perHostRateLimit) refillLocked(b *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*l.rps, l.burst)
+		b.last = now
+	}
+}
+
+// pruneLocked removes the buckets of hosts that have been idle long enough
+// to be full again, as they behave just like new ones.
+func (l *perHostRateLimit) pruneLocked(now time.Time) {
+	for host, b := range l.buckets {
+		l.refillLocked(b, now)
+		if b.tokens >= l.burst {
+			delete(l.buckets, host)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+// sleepRecordingClock is a Clock recording the durations waited for and
+// advancing its time by them immediately.
+type sleepRecordingClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *sleepRecordingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *sleepRecordingClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestWithPerHostRateLimit(t *testing.T) {
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer a.Close()
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer b.Close()
+
+	clock := &sleepRecordingClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	client := httpclient.New(
+		httpclient.WithClock(clock),
+		httpclient.WithPerHostRateLimit(2, 2),
+	)
+
+	for range 4 {
+		_, err := client.Get(context.Background(), a.URL)
+		ExpectThat(t, err).Is(NoError())
+	}
+
+	_, err := client.Get(context.Background(), b.URL)
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, clock.waits).Is(DeepEqual([]time.Duration{500 * time.Millisecond, 500 * time.Millisecond}))
+
+	_, err = httpclient.NewE(httpclient.WithPerHostRateLimit(0, 1))
+	ExpectThat(t, err).Is(NotNil())
+}
+
+func TestWithPerHostRateLimit_canceled(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithPerHostRateLimit(0.001, 1))
+
+	_, err := client.Get(context.Background(), testServer.URL)
+	ExpectThat(t, err).Is(NoError())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Get(ctx, testServer.URL)
+	ExpectThat(t, err).Is(NotNil())
+}
@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultRequestIDHeader is the header used by WithRequestID if no header
+// name is given.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// requestIDKey is the context key used to store the request ID.
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying id as the request ID.
+// WithRequestID uses this ID instead of generating a new one, which allows
+// propagating the ID of an inbound request.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx or an empty
+// string if ctx carries no request ID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDError wraps an error caused by a request with the ID assigned to
+// the request.
+type RequestIDError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestIDError) Error() string {
+	return fmt.Sprintf("request %s: %v", e.RequestID, e.Err)
+}
+
+func (e *RequestIDError) Unwrap() error { return e.Err }
+
+// annotateRequestID wraps err in a *RequestIDError if r carries a request ID
+// and err is not already annotated.
+func annotateRequestID(r *http.Request, err error) error {
+	id := RequestIDFromContext(r.Context())
+	if id == "" {
+		return err
+	}
+
+	var already *RequestIDError
+	if errors.As(err, &already) {
+		return err
+	}
+
+	return &RequestIDError{RequestID: id, Err: err}
+}
+
+// WithRequestID creates a RequestInterceptorOption assigning an ID to every
+// request. The ID is sent in header, which defaults to
+// DefaultRequestIDHeader, and stored in the request's context, so it is
+// included in the records logged by WithLogging and in any error returned
+// by the Client.
+//
+// If the request's context already carries an ID set with
+// ContextWithRequestID, that ID is used. Otherwise gen is called to create a
+// new ID; gen defaults to generating random (version 4) UUIDs. The
+// interceptor runs in RequestPhaseTracing.
+func WithRequestID(header string, gen func() string) RequestInterceptorOption {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+
+	if gen == nil {
+		gen = NewUUID
+	}
+
+	return InRequestPhaseFunc(RequestPhaseTracing, func(r *http.Request) (*http.Request, error) {
+		id := RequestIDFromContext(r.Context())
+		if id == "" {
+			id = gen()
+			r = r.WithContext(ContextWithRequestID(r.Context(), id))
+		}
+
+		r.Header.Set(header, id)
+
+		return r, nil
+	})
+}
+
+// NewUUID returns a random (version 4) UUID in its canonical string form.
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,51 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithRequestID(t *testing.T) {
+	var received []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Correlation-Id"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer testServer.Close()
+
+	var buf bytes.Buffer
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRequestID("X-Correlation-Id", func() string { return "generated" }),
+		httpclient.WithLogging(slog.New(slog.NewTextHandler(&buf, nil)), slog.LevelInfo),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	_, err := client.Get(context.Background(), "/")
+	var idErr *httpclient.RequestIDError
+	ExpectThat(t, errors.As(err, &idErr)).Is(Equal(true))
+	ExpectThat(t, idErr.RequestID).Is(Equal("generated"))
+	ExpectThat(t, err.Error()).Is(Equal("request generated: GET " + testServer.URL + "/: unexpected status code: 500"))
+
+	_, err = client.Get(httpclient.ContextWithRequestID(context.Background(), "inbound"), "/")
+	ExpectThat(t, err).Is(NotNil())
+
+	ExpectThat(t, received).Is(DeepEqual([]string{"generated", "inbound"}))
+	ExpectThat(t, buf.String()).Has(StringContaining("request_id=generated")).And(StringContaining("request_id=inbound"))
+}
+
+func TestNewUUID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ExpectThat(t, uuid.MatchString(httpclient.NewUUID())).Is(Equal(true))
+	ExpectThat(t, httpclient.NewUUID() != httpclient.NewUUID()).Is(Equal(true))
+}
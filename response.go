@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// ErrBodyConsumed is returned by the methods of Response reading the body
+// once the body has been streamed using SaveTo or closed using Close.
+var ErrBodyConsumed = errors.New("response body already consumed")
+
+// Response wraps an *http.Response returned by Client.Fetch with methods to
+// read the body. Bytes, String and JSON buffer the body on first use and
+// close it, so they can be called any number of times. SaveTo streams the
+// body to a file without buffering it. A Response whose body is never read
+// must be closed using Close.
+type Response struct {
+	*http.Response
+
+	body     []byte
+	buffered bool
+	consumed bool
+}
+
+// Fetch sends a request for url using method, ctx and opts just like Execute
+// but leaves the response body open to be read using the returned *Response.
+// If an error is returned along with a non-nil *Response, i.e. because the
+// response didn't pass status validation, the body has already been closed;
+// an *Error of kind ErrUnexpectedStatus carries the first bytes of it.
+func (c *Client) Fetch(ctx context.Context, method, url string, opts ...RequestOption) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(req, opts, true)
+	if res == nil {
+		return nil, err
+	}
+
+	if err != nil {
+		res.Body.Close()
+		return &Response{Response: res, consumed: true}, err
+	}
+
+	return &Response{Response: res}, nil
+}
+
+// IsSuccess reports whether the response has a 2xx status code.
+func (r *Response) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode <= 299
+}
+
+// Bytes returns the response body. The body is read completely and closed on
+// the first call.
+func (r *Response) Bytes() ([]byte, error) {
+	if r.buffered {
+		return r.body, nil
+	}
+	if r.consumed {
+		return nil, ErrBodyConsumed
+	}
+
+	b, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.consumed = true
+	if err != nil {
+		return nil, err
+	}
+
+	r.body = b
+	r.buffered = true
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// String returns the response body as a string. See Bytes.
+func (r *Response) String() (string, error) {
+	b, err := r.Bytes()
+	return string(b), err
+}
+
+// JSON decodes the response body into v using the Client's JSON codec. In
+// contrast to ForJSON the Content-Type of the response is not checked.
+// Decoding errors are reported as an *Error of kind ErrDecode. See Bytes.
+func (r *Response) JSON(v any, opts ...JSONOption) error {
+	b, err := r.Bytes()
+	if err != nil {
+		return err
+	}
+
+	jr := &forJSON{value: v}
+	for _, opt := range opts {
+		opt(jr)
+	}
+
+	res := *r.Response
+	res.Body = io.NopCloser(bytes.NewReader(b))
+	_, err = jr.decode(&res)
+	return err
+}
+
+// SaveTo streams the response body to the file at path and closes the body.
+// The body is written to a temporary file in the same directory which is
+// renamed once the body has been read completely, so an interrupted transfer
+// never replaces an existing file. If the body has been buffered using
+// Bytes, the buffered bytes are written.
+func (r *Response) SaveTo(path string) error {
+	if r.consumed && !r.buffered {
+		return ErrBodyConsumed
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	var src io.Reader = r.Body
+	if r.buffered {
+		src = bytes.NewReader(r.body)
+	} else {
+		defer r.Body.Close()
+		r.consumed = true
+	}
+
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// Close closes the response body unless it has already been read.
+func (r *Response) Close() error {
+	if r.consumed {
+		return nil
+	}
+	r.consumed = true
+	return r.Body.Close()
+}
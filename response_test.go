@@ -0,0 +1,79 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestClient_Fetch(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"Alice"}`))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("buffered", func(t *testing.T) {
+		res, err := client.Fetch(context.Background(), http.MethodGet, "/users/42")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.IsSuccess()).Is(Equal(true))
+
+		s, err := res.String()
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, s).Is(Equal(`{"name":"Alice"}`))
+
+		var user struct {
+			Name string `json:"name"`
+		}
+		ExpectThat(t, res.JSON(&user)).Is(NoError())
+		ExpectThat(t, user.Name).Is(Equal("Alice"))
+
+		path := filepath.Join(t.TempDir(), "user.json")
+		ExpectThat(t, res.SaveTo(path)).Is(NoError())
+		b, err := os.ReadFile(path)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, string(b)).Is(Equal(`{"name":"Alice"}`))
+	})
+
+	t.Run("saveTo", func(t *testing.T) {
+		res, err := client.Fetch(context.Background(), http.MethodGet, "/users/42")
+		ExpectThat(t, err).Is(NoError())
+
+		path := filepath.Join(t.TempDir(), "user.json")
+		ExpectThat(t, res.SaveTo(path)).Is(NoError())
+		b, err := os.ReadFile(path)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, string(b)).Is(Equal(`{"name":"Alice"}`))
+
+		_, err = res.Bytes()
+		ExpectThat(t, err).Is(Error(httpclient.ErrBodyConsumed))
+	})
+
+	t.Run("decodeError", func(t *testing.T) {
+		res, err := client.Fetch(context.Background(), http.MethodGet, "/users/42")
+		ExpectThat(t, err).Is(NoError())
+
+		var names []string
+		ExpectThat(t, res.JSON(&names)).Is(Error(httpclient.ErrDecode))
+	})
+
+	t.Run("unexpectedStatus", func(t *testing.T) {
+		res, err := client.Fetch(context.Background(), http.MethodGet, "/missing", httpclient.ExpectSuccess())
+		ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+		ExpectThat(t, res.IsSuccess()).Is(Equal(false))
+		ExpectThat(t, res.Close()).Is(NoError())
+	})
+}
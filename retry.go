@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures the retries performed by WithRetry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the initial
+	// one. Values <= 1 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the time to wait before the first retry. It doubles
+	// for every further retry. It defaults to 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff limits the time to wait before a retry, including times
+	// requested using a Retry-After header. It defaults to 10s.
+	MaxBackoff time.Duration
+
+	// RetryStatusCodes lists the status codes of responses that are retried.
+	// It defaults to 429, 502, 503 and 504.
+	RetryStatusCodes []int
+}
+
+// WithRetry creates a ClientOption that sends requests again if they fail
+// with an error for which IsRetryable reports true or if they are answered
+// with one of p.RetryStatusCodes. The backoff between attempts grows
+// exponentially; a Retry-After header given in seconds is honored instead.
+//
+// Only requests using an idempotent method - GET, HEAD, OPTIONS, TRACE, PUT
+// and DELETE - or carrying an Idempotency-Key header are retried. Requests
+// with a body are only retried if the body can be recreated using the
+// request's GetBody function. Requests for which RetriesDisabled reports true
+// are sent once.
+//
+// Each attempt carries an ExecutionState denoting its number. Options
+// observing requests as they are sent, such as WithLogging, see every attempt
+// if they are given after this option.
+func WithRetry(p RetryPolicy) ClientOption {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 10 * time.Second
+	}
+	if p.RetryStatusCodes == nil {
+		p.RetryStatusCodes = defaultRetryStatusCodes
+	}
+
+	return &retrier{p}
+}
+
+// defaultRetryStatusCodes lists the status codes retried by WithRetry unless
+// configured otherwise. IsRetryable uses the same status codes to classify
+// errors of kind ErrUnexpectedStatus.
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type retrier struct {
+	policy RetryPolicy
+}
+
+func (*retrier) clientOpt() {}
+
+func (rt *retrier) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := r.Context()
+	if rt.policy.MaxAttempts <= 1 || !retryable(r) || RetriesDisabled(ctx) {
+		return next(r)
+	}
+
+	clock := ClockFromContext(ctx)
+	backoff := rt.policy.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		out := r.WithContext(ContextWithExecutionState(ctx, ExecutionState{Attempt: attempt}))
+		if attempt > 1 && r.Body != nil && r.Body != http.NoBody {
+			body, err := r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			out.Body = body
+		}
+
+		res, err := next(out)
+
+		if attempt == rt.policy.MaxAttempts || ctx.Err() != nil {
+			return res, err
+		}
+
+		wait := backoff
+		if err != nil {
+			if !IsRetryable(err) {
+				return res, err
+			}
+		} else {
+			if !slices.Contains(rt.policy.RetryStatusCodes, res.StatusCode) {
+				return res, nil
+			}
+			if d, ok := retryAfter(res); ok {
+				wait = d
+			}
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if err := sleep(ctx, clock, min(wait, rt.policy.MaxBackoff)); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether r may be sent more than once.
+func retryable(r *http.Request) bool {
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		return false
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return r.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// retryAfter returns the delay requested by the Retry-After header of res if
+// it is given in seconds.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	s, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || s < 0 {
+		return 0, false
+	}
+	return time.Duration(s) * time.Second, true
+}
@@ -0,0 +1,101 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+// attemptRecorder is a http.RoundTripper recording the attempts of the
+// requests it sends.
+type attemptRecorder struct {
+	attempts []int
+}
+
+func (a *attemptRecorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	a.attempts = append(a.attempts, httpclient.ExecutionStateFromContext(r.Context()).Attempt)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestWithRetry(t *testing.T) {
+	var bodies []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if r.URL.Path == "/unavailable" || len(bodies) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	recorder := new(attemptRecorder)
+	client := httpclient.New(
+		httpclient.WithTransport(recorder),
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRetry(httpclient.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+	)
+
+	res, err := client.Execute(context.Background(), http.MethodPut, "/", httpclient.WithJSON("value"))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusNoContent))
+	ExpectThat(t, bodies).Is(DeepEqual([]string{`"value"`, `"value"`, `"value"`}))
+	ExpectThat(t, recorder.attempts).Is(DeepEqual([]int{1, 2, 3}))
+
+	t.Run("nonIdempotent", func(t *testing.T) {
+		bodies = nil
+		res, err := client.Post(context.Background(), "/unavailable", httpclient.WithJSON("value"))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusServiceUnavailable))
+		ExpectThat(t, len(bodies)).Is(Equal(1))
+	})
+
+	t.Run("idempotencyKey", func(t *testing.T) {
+		bodies = nil
+		res, err := client.Post(context.Background(), "/unavailable", httpclient.WithJSON("value"),
+			httpclient.WithRequestHeader("Idempotency-Key", "k"))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusServiceUnavailable))
+		ExpectThat(t, len(bodies)).Is(Equal(3))
+	})
+
+	t.Run("retriesDisabled", func(t *testing.T) {
+		bodies = nil
+		client := client.With(httpclient.WithDegradationProfiles(map[string]httpclient.DegradationProfile{
+			"degraded": {DisableRetries: true},
+		}))
+		ExpectThat(t, client.SetProfile("degraded")).Is(NoError())
+
+		_, err := client.Get(context.Background(), "/unavailable")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, len(bodies)).Is(Equal(1))
+	})
+}
+
+func TestWithRetry_retryableStatusError(t *testing.T) {
+	var attempts int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRetry(httpclient.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	_, err := client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+	ExpectThat(t, attempts).Is(Equal(2))
+	ExpectThat(t, httpclient.IsRetryable(err)).Is(Equal(true))
+}
@@ -0,0 +1,243 @@
+package robots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/halimath/httpclient"
+)
+
+// DefaultTTL is the time a robots.txt is cached unless configured otherwise
+// using WithTTL.
+const DefaultTTL = 24 * time.Hour
+
+// DefaultFetchTimeout is the time allowed for fetching a robots.txt unless
+// configured otherwise using WithFetchTimeout.
+const DefaultFetchTimeout = 30 * time.Second
+
+// unreachableTTL is the time a robots.txt that couldn't be fetched is
+// treated as disallowing everything before it is fetched again.
+const unreachableTTL = time.Minute
+
+// maxSize is the number of bytes of a robots.txt that are parsed, as
+// required by RFC 9309.
+const maxSize = 500 << 10
+
+// ErrDisallowed is returned for requests the robots.txt of the host
+// disallows.
+var ErrDisallowed = errors.New("robots: disallowed by robots.txt")
+
+// Option customizes a crawler.
+type Option func(*crawler)
+
+// WithClient sets the Client used to fetch robots.txt files. It defaults to
+// a Client created using httpclient.New without options. The Client may use
+// the crawling mode itself, as robots.txt files are always allowed.
+func WithClient(c *httpclient.Client) Option {
+	return func(cr *crawler) {
+		cr.client = c
+	}
+}
+
+// WithTTL sets the time a robots.txt is cached. It defaults to DefaultTTL.
+func WithTTL(d time.Duration) Option {
+	return func(cr *crawler) {
+		cr.ttl = d
+	}
+}
+
+// WithFetchTimeout sets the time allowed for fetching a robots.txt. A
+// robots.txt that couldn't be fetched in time is treated like one that can't
+// be fetched at all. It defaults to DefaultFetchTimeout.
+func WithFetchTimeout(d time.Duration) Option {
+	return func(cr *crawler) {
+		cr.fetchTimeout = d
+	}
+}
+
+// WithDefaultCrawlDelay sets the delay between requests to a host whose
+// robots.txt specifies no Crawl-delay. It defaults to 0.
+func WithDefaultCrawlDelay(d time.Duration) Option {
+	return func(cr *crawler) {
+		cr.defaultDelay = d
+	}
+}
+
+// WithCrawling creates a RequestInterceptorOption making requests honor the
+// robots.txt of their host for userAgent, which is the product token of the
+// crawler such as "examplebot"; set the User-Agent header using
+// httpclient.WithUserAgent. Requests for paths disallowed for userAgent fail
+// with an error wrapping ErrDisallowed without being sent. Requests to a
+// host are delayed so they are at least the Crawl-delay requested by the
+// host apart, including concurrent ones.
+//
+// A robots.txt answered with a 4xx status code allows everything, while one
+// that can't be fetched or is answered with a 5xx status code disallows
+// everything until it is fetched again a minute later, as required by RFC
+// 9309. A robots.txt is fetched once for all concurrent requests to a host;
+// requests waiting for it stop waiting once their context is done. The
+// interceptor runs in httpclient.RequestPhaseTracing, so it sees
+// the request as it is sent.
+func WithCrawling(userAgent string, opts ...Option) httpclient.RequestInterceptorOption {
+	cr := &crawler{
+		userAgent:    userAgent,
+		ttl:          DefaultTTL,
+		fetchTimeout: DefaultFetchTimeout,
+		hosts:        make(map[string]*host),
+	}
+
+	for _, opt := range opts {
+		opt(cr)
+	}
+
+	if cr.client == nil {
+		cr.client = httpclient.New()
+	}
+
+	return httpclient.InRequestPhaseFunc(httpclient.RequestPhaseTracing, cr.intercept)
+}
+
+type crawler struct {
+	userAgent    string
+	client       *httpclient.Client
+	ttl          time.Duration
+	fetchTimeout time.Duration
+	defaultDelay time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// host is the state kept for an origin.
+type host struct {
+	mu      sync.Mutex
+	robots  *Robots
+	expires time.Time
+	next    time.Time
+
+	// fetching is closed once the robots.txt currently being fetched is
+	// available. It is nil if no fetch is in flight.
+	fetching chan struct{}
+}
+
+func (cr *crawler) intercept(r *http.Request) (*http.Request, error) {
+	path := r.URL.EscapedPath()
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	if path == "/robots.txt" {
+		return r, nil
+	}
+
+	ctx := r.Context()
+	clock := httpclient.ClockFromContext(ctx)
+	origin := r.URL.Scheme + "://" + r.URL.Host
+
+	cr.mu.Lock()
+	h, ok := cr.hosts[origin]
+	if !ok {
+		h = &host{}
+		cr.hosts[origin] = h
+	}
+	cr.mu.Unlock()
+
+	h.mu.Lock()
+	now := clock.Now()
+	for h.robots == nil || !now.Before(h.expires) {
+		if h.fetching == nil {
+			h.fetching = make(chan struct{})
+			// The robots.txt is shared by all requests to the host, so it is
+			// fetched independently of the cancellation of this request.
+			go cr.refresh(context.WithoutCancel(ctx), origin, h)
+		}
+		fetching := h.fetching
+		h.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return r, ctx.Err()
+		case <-fetching:
+		}
+
+		h.mu.Lock()
+		now = clock.Now()
+	}
+
+	if !h.robots.Allowed(cr.userAgent, path) {
+		h.mu.Unlock()
+		return r, fmt.Errorf("%w: %s", ErrDisallowed, r.URL.Redacted())
+	}
+
+	delay := h.robots.CrawlDelay(cr.userAgent)
+	if delay == 0 {
+		delay = cr.defaultDelay
+	}
+
+	at := now
+	if h.next.After(at) {
+		at = h.next
+	}
+	h.next = at.Add(delay)
+	h.mu.Unlock()
+
+	if wait := at.Sub(now); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return r, ctx.Err()
+		case <-clock.After(wait):
+		}
+	}
+
+	return r, nil
+}
+
+// refresh fetches the robots.txt of origin and stores it in h, releasing
+// the requests waiting for it.
+func (cr *crawler) refresh(ctx context.Context, origin string, h *host) {
+	robots, ttl := cr.fetch(ctx, origin)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.robots = robots
+	h.expires = httpclient.ClockFromContext(ctx).Now().Add(ttl)
+	close(h.fetching)
+	h.fetching = nil
+}
+
+// fetch fetches the robots.txt of origin and returns it along with the time
+// it may be cached.
+func (cr *crawler) fetch(ctx context.Context, origin string) (*Robots, time.Duration) {
+	ctx, cancel := context.WithTimeout(httpclient.ContextWithoutOptions(ctx), cr.fetchTimeout)
+	defer cancel()
+
+	res, err := cr.client.Fetch(ctx, http.MethodGet, origin+"/robots.txt")
+	if res == nil {
+		return disallowAll, unreachableTTL
+	}
+	defer res.Close()
+
+	switch {
+	case res.StatusCode >= 400 && res.StatusCode <= 499:
+		return allowAll, cr.ttl
+	case err != nil || !res.IsSuccess():
+		return disallowAll, unreachableTTL
+	}
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxSize))
+	if err != nil {
+		return disallowAll, unreachableTTL
+	}
+
+	return Parse(b), cr.ttl
+}
+
+var (
+	allowAll    = &Robots{}
+	disallowAll = &Robots{groups: []group{{agents: []string{"*"}, rules: []rule{{pattern: "/"}}}}}
+)
@@ -0,0 +1,103 @@
+package robots_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/robots"
+)
+
+func TestWithCrawling(t *testing.T) {
+	var robotsFetches int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsFetches++
+			w.Write([]byte("User-agent: examplebot\nDisallow: /private\nCrawl-delay: 0.05\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	c := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+	client := c.With(robots.WithCrawling("examplebot", robots.WithClient(c)))
+
+	start := time.Now()
+	for range 3 {
+		_, err := client.Get(context.Background(), "/page")
+		ExpectThat(t, err).Is(NoError())
+	}
+	ExpectThat(t, time.Since(start) >= 100*time.Millisecond).Is(Equal(true))
+
+	_, err := client.Get(context.Background(), "/private/page")
+	ExpectThat(t, err).Is(Error(robots.ErrDisallowed))
+
+	ExpectThat(t, robotsFetches).Is(Equal(1))
+}
+
+func TestWithCrawling_robotsStatus(t *testing.T) {
+	status := http.StatusNotFound
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(status)
+		}
+	}))
+	defer testServer.Close()
+
+	_, err := httpclient.New(httpclient.WithURLPrefix(testServer.URL), robots.WithCrawling("examplebot")).
+		Get(context.Background(), "/page")
+	ExpectThat(t, err).Is(NoError())
+
+	status = http.StatusServiceUnavailable
+	_, err = httpclient.New(httpclient.WithURLPrefix(testServer.URL), robots.WithCrawling("examplebot")).
+		Get(context.Background(), "/page")
+	ExpectThat(t, err).Is(Error(robots.ErrDisallowed))
+}
+
+func TestWithCrawling_hangingRobots(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			<-r.Context().Done()
+		}
+	}))
+	defer testServer.Close()
+
+	c := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+	client := c.With(robots.WithCrawling("examplebot", robots.WithClient(c), robots.WithFetchTimeout(100*time.Millisecond)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Get(ctx, "/page")
+	ExpectThat(t, err).Is(Error(context.DeadlineExceeded))
+	ExpectThat(t, time.Since(start) < 100*time.Millisecond).Is(Equal(true))
+
+	_, err = client.Get(context.Background(), "/page")
+	ExpectThat(t, err).Is(Error(robots.ErrDisallowed))
+}
+
+func TestWithCrawling_contextOptions(t *testing.T) {
+	var robotsHeader, pageHeader string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsHeader = r.Header.Get("X-Tenant")
+			return
+		}
+		pageHeader = r.Header.Get("X-Tenant")
+	}))
+	defer testServer.Close()
+
+	c := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+	client := c.With(robots.WithCrawling("examplebot", robots.WithClient(c)))
+
+	ctx := httpclient.ContextWithOptions(context.Background(), httpclient.WithRequestHeader("X-Tenant", "acme"))
+	_, err := client.Get(ctx, "/page")
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, robotsHeader).Is(Equal(""))
+	ExpectThat(t, pageHeader).Is(Equal("acme"))
+}
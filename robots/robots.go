@@ -0,0 +1,174 @@
+// Package robots implements a polite crawling mode for httpclient honoring
+// the Robots Exclusion Protocol as defined by RFC 9309. The robots.txt of
+// every host is fetched and cached, requests for disallowed paths are
+// refused and the Crawl-delay requested by a host is enforced between
+// requests.
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Robots is a parsed robots.txt file.
+type Robots struct {
+	groups []group
+}
+
+// group is a group of rules applying to a set of user agents.
+type group struct {
+	agents []string
+	rules  []rule
+	delay  time.Duration
+}
+
+// rule is an allow or disallow rule.
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// Parse parses the content of a robots.txt file. Unknown or malformed lines
+// are ignored, as required by RFC 9309.
+func Parse(b []byte) *Robots {
+	var r Robots
+	var current *group
+	inRules := false
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || inRules {
+				r.groups = append(r.groups, group{})
+				current = &r.groups[len(r.groups)-1]
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if value != "" {
+				current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+			}
+
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+				current.delay = time.Duration(secs * float64(time.Second))
+			}
+		}
+	}
+
+	return &r
+}
+
+// groupsFor returns the groups applying to userAgent: the groups naming its
+// product token or the groups for "*" if there are none.
+func (r *Robots) groupsFor(userAgent string) []group {
+	userAgent = strings.ToLower(userAgent)
+
+	var matching, wildcard []group
+	for _, g := range r.groups {
+		for _, a := range g.agents {
+			if a == userAgent {
+				matching = append(matching, g)
+				break
+			}
+			if a == "*" {
+				wildcard = append(wildcard, g)
+				break
+			}
+		}
+	}
+
+	if len(matching) > 0 {
+		return matching
+	}
+	return wildcard
+}
+
+// Allowed reports whether userAgent, given as its product token such as
+// "examplebot", may fetch path, which includes the query string if any. The
+// most specific matching rule decides; if an allow and a disallow rule are
+// equally specific, the path is allowed.
+func (r *Robots) Allowed(userAgent, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allowed, length := true, -1
+	for _, g := range r.groupsFor(userAgent) {
+		for _, rl := range g.rules {
+			if !match(rl.pattern, path) {
+				continue
+			}
+			if len(rl.pattern) > length || (len(rl.pattern) == length && rl.allow) {
+				allowed, length = rl.allow, len(rl.pattern)
+			}
+		}
+	}
+
+	return allowed
+}
+
+// CrawlDelay returns the delay userAgent must wait between requests as
+// requested by the Crawl-delay directive or 0 if there is none.
+func (r *Robots) CrawlDelay(userAgent string) time.Duration {
+	var d time.Duration
+	for _, g := range r.groupsFor(userAgent) {
+		d = max(d, g.delay)
+	}
+	return d
+}
+
+// match reports whether path matches pattern, which may contain * matching
+// any sequence of characters and end with $ anchoring it at the end of path.
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	path = path[len(parts[0]):]
+
+	if len(parts) == 1 {
+		return !anchored || path == ""
+	}
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(path, part)
+		if i < 0 {
+			return false
+		}
+		path = path[i+len(part):]
+	}
+
+	last := parts[len(parts)-1]
+	if anchored {
+		return strings.HasSuffix(path, last)
+	}
+	return strings.Contains(path, last)
+}
@@ -0,0 +1,62 @@
+package robots_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient/robots"
+)
+
+const robotsTxt = `
+# comment
+User-Agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 1
+
+user-agent: ExampleBot
+user-agent: OtherBot
+disallow: /
+allow: /$
+allow: /docs/
+crawl-delay: 0.5
+
+sitemap: https://example.com/sitemap.xml
+`
+
+func TestRobots(t *testing.T) {
+	r := robots.Parse([]byte(robotsTxt))
+
+	tests := []struct {
+		userAgent string
+		path      string
+		allowed   bool
+	}{
+		{"somebot", "/", true},
+		{"somebot", "/private", false},
+		{"somebot", "/private/x", false},
+		{"somebot", "/private/public/x", true},
+		{"somebot", "/files/report.pdf", false},
+		{"somebot", "/files/report.pdf?x", true},
+		{"examplebot", "/", true},
+		{"examplebot", "/index.html", false},
+		{"examplebot", "/docs/index.html", true},
+		{"OTHERBOT", "/index.html", false},
+		{"examplebot", "/robots.txt", true},
+	}
+
+	for _, test := range tests {
+		ExpectThat(t, r.Allowed(test.userAgent, test.path)).Is(Equal(test.allowed))
+	}
+
+	ExpectThat(t, r.CrawlDelay("somebot")).Is(Equal(time.Second))
+	ExpectThat(t, r.CrawlDelay("examplebot")).Is(Equal(500 * time.Millisecond))
+}
+
+func TestRobots_empty(t *testing.T) {
+	r := robots.Parse(nil)
+	ExpectThat(t, r.Allowed("examplebot", "/anything")).Is(Equal(true))
+	ExpectThat(t, r.CrawlDelay("examplebot")).Is(Equal(time.Duration(0)))
+}
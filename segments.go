@@ -0,0 +1,212 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// downloadSegments is the RequestOption returned by WithDownloadSegments.
+type downloadSegments int
+
+func (downloadSegments) reqOpt() {}
+
+// WithDownloadSegments creates a RequestOption causing Client.Download and
+// Client.DownloadFile to download the resource using n concurrent range
+// requests, each writing its segment of the body at the corresponding offset
+// of the destination. This speeds up downloads of large files from servers
+// limiting the bandwidth per connection.
+//
+// A HEAD request is sent first to determine the size of the resource. The
+// body is downloaded using a single request if the server doesn't advertise
+// support for byte ranges using an Accept-Ranges header, if the size is
+// unknown or if the destination given to Client.Download is not an
+// io.WriterAt. Every segment must be answered with a 206 status code and a
+// Content-Range header matching the requested range. If a segment is answered
+// otherwise, i.e. with a 200 status code or a redirect, the remaining segments
+// are canceled and the body is downloaded again using a single request. If
+// the resource has a strong ETag, the segments are requested using If-Range,
+// so a resource modified during the download is downloaded again as a whole
+// instead of producing a corrupt file. The remaining segments are canceled
+// once a segment failed.
+//
+// When combined with WithChecksum, the assembled destination is read back
+// and verified once all segments have been downloaded. A destination that is
+// not an io.ReaderAt is downloaded using a single request instead.
+func WithDownloadSegments(n int) RequestOption {
+	return downloadSegments(n)
+}
+
+// segmentsOf returns the number of segments configured in opts or 0.
+func segmentsOf(opts []RequestOption) int {
+	var n int
+	for _, opt := range opts {
+		if s, ok := opt.(downloadSegments); ok {
+			n = int(s)
+		}
+	}
+	return n
+}
+
+// downloadSegmented implements Download using n segments written to w. dst
+// is used to download the body using a single request if the server doesn't
+// support byte ranges.
+func (c *Client) downloadSegmented(ctx context.Context, url string, dst io.Writer, w io.WriterAt, n int, opts []RequestOption) (int64, error) {
+	checksum := checksumOf(opts)
+	ra, ok := w.(io.ReaderAt)
+	if checksum != nil && !ok {
+		return c.download(ctx, url, dst, opts)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := c.Do(req, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return 0, newResponseError(ErrUnexpectedStatus, res, nil)
+	}
+
+	size := res.ContentLength
+	if size <= 0 || !acceptsByteRanges(res) {
+		return c.download(ctx, url, dst, opts)
+	}
+
+	var withIfRange []RequestOption
+	if etag := res.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		withIfRange = []RequestOption{WithRequestHeader("If-Range", etag)}
+	}
+
+	progress := newTransferProgress(responseContext(res), size)
+
+	segments := min(int64(n), size)
+	segmentSize := (size + segments - 1) / segments
+
+	segmentCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	for start := int64(0); start < size; start += segmentSize {
+		end := min(start+segmentSize, size) - 1
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			segmentOpts := append(append(opts[:len(opts):len(opts)], withIfRange...),
+				WithRequestHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end)))
+
+			if err := c.downloadSegment(segmentCtx, url, w, start, end, progress, segmentOpts); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if errors.Is(firstErr, errSegmentNotPartial) {
+		if t, ok := dst.(interface{ Truncate(int64) error }); ok {
+			if err := t.Truncate(0); err != nil {
+				return 0, err
+			}
+		}
+		return c.download(ctx, url, dst, opts)
+	}
+	if firstErr != nil {
+		return 0, firstErr
+	}
+
+	if checksum != nil {
+		if err := checksum.verify(res, io.NewSectionReader(ra, 0, size)); err != nil {
+			return 0, err
+		}
+	}
+
+	if progress != nil {
+		progress.report()
+	}
+
+	return size, nil
+}
+
+// downloadSegment downloads the bytes from start to end - both inclusive -
+// of the resource identified by url and writes them to w at offset start.
+func (c *Client) downloadSegment(ctx context.Context, url string, w io.WriterAt, start, end int64, progress *transferProgress, opts []RequestOption) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(req, opts, true)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusPartialContent || !matchesContentRange(res, start, end) {
+		return errSegmentNotPartial
+	}
+
+	var dst io.Writer = io.NewOffsetWriter(w, start)
+	if progress != nil {
+		dst = &progressWriter{dst, progress}
+	}
+
+	length := end - start + 1
+	written, err := io.Copy(dst, io.LimitReader(res.Body, length))
+	if err != nil {
+		return err
+	}
+	if written != length {
+		return newResponseError(ErrDecode, res, io.ErrUnexpectedEOF)
+	}
+
+	return nil
+}
+
+// errSegmentNotPartial is returned by downloadSegment if the response is not
+// a partial response for the requested range.
+var errSegmentNotPartial = errors.New("segment not answered with requested range")
+
+// matchesContentRange reports whether the Content-Range header of res
+// denotes the bytes from start to end - both inclusive.
+func matchesContentRange(res *http.Response, start, end int64) bool {
+	var gotStart, gotEnd int64
+	var size string
+	if _, err := fmt.Sscanf(res.Header.Get("Content-Range"), "bytes %d-%d/%s", &gotStart, &gotEnd, &size); err != nil {
+		return false
+	}
+	return gotStart == start && gotEnd == end
+}
+
+// acceptsByteRanges reports whether res advertises support for byte range
+// requests.
+func acceptsByteRanges(res *http.Response) bool {
+	for _, v := range strings.Split(res.Header.Get("Accept-Ranges"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "bytes") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package httpclient_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithDownloadSegments(t *testing.T) {
+	content := strings.Repeat("0123456789", 10001)
+
+	var ranges atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+		}
+
+		switch r.URL.Path {
+		case "/no-ranges":
+			w.Write([]byte(content))
+			return
+		case "/ignored-ranges":
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			if r.Method != http.MethodHead {
+				w.Write([]byte(content))
+			}
+			return
+		case "/wrong-ranges":
+			if r.Header.Get("Range") != "" {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-9/%d", len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(content[:10]))
+				return
+			}
+			http.ServeContent(w, r, "data.txt", time.Time{}, strings.NewReader(content))
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "data.txt", time.Time{}, strings.NewReader(content))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("segmented", func(t *testing.T) {
+		ranges.Store(0)
+		path := filepath.Join(t.TempDir(), "data.txt")
+
+		var transferred atomic.Int64
+		n, err := client.DownloadFile(context.Background(), "/data.txt", path,
+			httpclient.WithDownloadSegments(4),
+			httpclient.WithDownloadProgress(func(p httpclient.Progress) {
+				transferred.Store(p.Transferred)
+			}),
+		)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, n).Is(Equal(int64(len(content))))
+		ExpectThat(t, ranges.Load()).Is(Equal(int32(4)))
+		ExpectThat(t, transferred.Load()).Is(Equal(int64(len(content))))
+
+		b, err := os.ReadFile(path)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, string(b)).Is(Equal(content))
+	})
+
+	t.Run("noRanges", func(t *testing.T) {
+		ranges.Store(0)
+		path := filepath.Join(t.TempDir(), "data.txt")
+
+		_, err := client.DownloadFile(context.Background(), "/no-ranges", path, httpclient.WithDownloadSegments(4))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, ranges.Load()).Is(Equal(int32(0)))
+
+		b, err := os.ReadFile(path)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, string(b)).Is(Equal(content))
+	})
+	for _, p := range []string{"/ignored-ranges", "/wrong-ranges"} {
+		t.Run(p, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data.txt")
+
+			n, err := client.DownloadFile(context.Background(), p, path, httpclient.WithDownloadSegments(4))
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, n).Is(Equal(int64(len(content))))
+
+			b, err := os.ReadFile(path)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, string(b)).Is(Equal(content))
+		})
+	}
+
+	t.Run("checksum", func(t *testing.T) {
+		sum := sha256.Sum256([]byte(content))
+
+		ranges.Store(0)
+		path := filepath.Join(t.TempDir(), "data.txt")
+		_, err := client.DownloadFile(context.Background(), "/data.txt", path,
+			httpclient.WithDownloadSegments(4),
+			httpclient.WithChecksum(crypto.SHA256, hex.EncodeToString(sum[:])),
+		)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, ranges.Load()).Is(Equal(int32(4)))
+
+		ranges.Store(0)
+		path = filepath.Join(t.TempDir(), "corrupt.txt")
+		_, err = client.DownloadFile(context.Background(), "/data.txt", path,
+			httpclient.WithDownloadSegments(4),
+			httpclient.WithChecksum(crypto.SHA256, hex.EncodeToString(make([]byte, 32))),
+		)
+		ExpectThat(t, err).Is(Error(httpclient.ErrChecksumMismatch))
+		ExpectThat(t, ranges.Load()).Is(Equal(int32(4)))
+
+		_, err = os.Stat(path)
+		ExpectThat(t, os.IsNotExist(err)).Is(Equal(true))
+	})
+}
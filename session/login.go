@@ -0,0 +1,143 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+
+	"github.com/halimath/httpclient"
+)
+
+// ErrLoginFailed is returned by LoginFlow.Login if the service rejected the
+// login.
+var ErrLoginFailed = errors.New("session: login failed")
+
+// LoginFlow describes a form based login: the login page is fetched to
+// obtain cookies and a CSRF token, the credentials are posted as a form and
+// the resulting response - after following any redirects - is verified.
+type LoginFlow struct {
+	// LoginURL is the URL of the login page.
+	LoginURL string
+
+	// ActionURL is the URL the login form is posted to. It defaults to
+	// LoginURL.
+	ActionURL string
+
+	// Form contains the fields of the login form, i.e. the user name and
+	// password.
+	Form url.Values
+
+	// CSRFField is the name of the hidden form field carrying the CSRF token
+	// on the login page, i.e. "authenticity_token". If set, the field is
+	// copied to the posted form and the login fails if the page has no such
+	// field.
+	CSRFField string
+
+	// SuccessPath is the path of the page the service redirects to after a
+	// successful login, i.e. "/dashboard". If set, the login fails if the
+	// final response has been received for a different path, i.e. because
+	// the service redirected back to the login page.
+	SuccessPath string
+
+	// SuccessCookie is the name of a cookie the service sets on successful
+	// logins, i.e. the session cookie. If set, the login fails if the cookie
+	// is missing after the login.
+	SuccessCookie string
+
+	// Verify is called with the final response to check whether the login
+	// succeeded, i.e. by inspecting the body. It is called after the other
+	// checks passed.
+	Verify func(s *Session, r *http.Response) error
+}
+
+// Login performs the login flow using a Session created by New with c and
+// opts and returns the authenticated Session. Responses with a status code
+// other than 2xx and failed checks are reported as errors wrapping
+// ErrLoginFailed.
+func (f LoginFlow) Login(ctx context.Context, c *httpclient.Client, opts ...Option) (*Session, error) {
+	if f.CSRFField != "" {
+		opts = append(opts[:len(opts):len(opts)], WithCSRF(FromFormField(f.CSRFField)))
+	}
+	s := New(c, opts...)
+
+	res, err := s.Get(ctx, f.LoginURL)
+	if err != nil {
+		return nil, err
+	}
+	if !successful(res) {
+		return nil, fmt.Errorf("%w: login page responded with %d", ErrLoginFailed, res.StatusCode)
+	}
+
+	form := maps.Clone(f.Form)
+	if form == nil {
+		form = url.Values{}
+	}
+	if f.CSRFField != "" {
+		token := s.CSRFToken()
+		if token == "" {
+			return nil, fmt.Errorf("%w: login page has no %s field", ErrLoginFailed, f.CSRFField)
+		}
+		form.Set(f.CSRFField, token)
+	}
+
+	action := f.ActionURL
+	if action == "" {
+		action = f.LoginURL
+	}
+
+	// The final response is verified while its body is still open, so Verify
+	// may inspect it.
+	_, err = s.Post(ctx, action, httpclient.WithForm(form),
+		httpclient.InPhaseFunc(httpclient.PhasePostValidate, func(r *http.Response) (*http.Response, error) {
+			return r, f.verify(s, r)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// verify checks the final response of the login.
+func (f LoginFlow) verify(s *Session, res *http.Response) error {
+	if !successful(res) {
+		return fmt.Errorf("%w: responded with %d", ErrLoginFailed, res.StatusCode)
+	}
+
+	if f.SuccessPath != "" && (res.Request == nil || res.Request.URL.Path != f.SuccessPath) {
+		return fmt.Errorf("%w: expected to end up at %s", ErrLoginFailed, f.SuccessPath)
+	}
+
+	if f.SuccessCookie != "" && !hasCookie(s.jar, res.Request, f.SuccessCookie) {
+		return fmt.Errorf("%w: missing cookie %s", ErrLoginFailed, f.SuccessCookie)
+	}
+
+	if f.Verify != nil {
+		if err := f.Verify(s, res); err != nil {
+			return fmt.Errorf("%w: %w", ErrLoginFailed, err)
+		}
+	}
+
+	return nil
+}
+
+func successful(res *http.Response) bool {
+	return res.StatusCode >= 200 && res.StatusCode <= 299
+}
+
+// hasCookie reports whether jar has a cookie name for the URL of r.
+func hasCookie(jar http.CookieJar, r *http.Request, name string) bool {
+	if r == nil {
+		return false
+	}
+	for _, c := range jar.Cookies(r.URL) {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
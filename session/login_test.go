@@ -0,0 +1,92 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/session"
+)
+
+func TestLoginFlow(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "pre", Value: "1", Path: "/"})
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<form method="post"><input type="hidden" name="authenticity_token" value="a1"></form>`))
+	})
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		_, err := r.Cookie("pre")
+		if err != nil || r.FormValue("authenticity_token") != "a1" || r.FormValue("user") != "jane" || r.FormValue("password") != "secret" {
+			http.Redirect(w, r, "/login?error", http.StatusFound)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s1", Path: "/"})
+		http.Redirect(w, r, "/dashboard", http.StatusFound)
+	})
+	mux.HandleFunc("GET /dashboard", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("sid"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("Welcome, jane"))
+	})
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	c := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	flow := session.LoginFlow{
+		LoginURL:      "/login",
+		Form:          url.Values{"user": {"jane"}, "password": {"secret"}},
+		CSRFField:     "authenticity_token",
+		SuccessPath:   "/dashboard",
+		SuccessCookie: "sid",
+	}
+
+	s, err := flow.Login(context.Background(), c)
+	ExpectThat(t, err).Is(NoError())
+
+	res, err := s.Get(context.Background(), "/dashboard")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusOK))
+
+	t.Run("wrongPassword", func(t *testing.T) {
+		flow := flow
+		flow.Form = url.Values{"user": {"jane"}, "password": {"wrong"}}
+		_, err := flow.Login(context.Background(), c)
+		ExpectThat(t, err).Is(Error(session.ErrLoginFailed))
+	})
+
+	t.Run("verifyBody", func(t *testing.T) {
+		var body string
+		flow := flow
+		flow.Verify = func(s *session.Session, r *http.Response) error {
+			b, err := io.ReadAll(r.Body)
+			body = string(b)
+			return err
+		}
+		_, err := flow.Login(context.Background(), c)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, body).Is(Equal("Welcome, jane"))
+
+		flow.Verify = func(s *session.Session, r *http.Response) error {
+			return errors.New("unexpected body")
+		}
+		_, err = flow.Login(context.Background(), c)
+		ExpectThat(t, err).Is(Error(session.ErrLoginFailed))
+	})
+
+	t.Run("missingCSRFField", func(t *testing.T) {
+		flow := flow
+		flow.CSRFField = "csrf"
+		_, err := flow.Login(context.Background(), c)
+		ExpectThat(t, err).Is(Error(session.ErrLoginFailed))
+	})
+}
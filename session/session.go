@@ -0,0 +1,233 @@
+// Package session maintains browser-like sessions with services using form
+// based login. A Session keeps cookies in a cookie jar and extracts a CSRF
+// token from responses - from a cookie, a meta tag, a hidden form field or a
+// header - which it sends with every mutating request. LoginFlow automates
+// the login itself for services providing no token based API.
+package session
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/halimath/httpclient"
+)
+
+// DefaultCSRFHeader is the header the CSRF token is sent in unless
+// configured otherwise using WithCSRFHeader.
+const DefaultCSRFHeader = "X-CSRF-Token"
+
+// CSRFSource extracts a CSRF token from responses. Use FromCookie,
+// FromMetaTag, FromFormField or FromHeader to create one.
+type CSRFSource interface {
+	csrfToken(s *Session, r *http.Response) (string, bool)
+}
+
+type csrfSourceFunc func(s *Session, r *http.Response) (string, bool)
+
+func (f csrfSourceFunc) csrfToken(s *Session, r *http.Response) (string, bool) { return f(s, r) }
+
+// FromCookie creates a CSRFSource taking the token from the cookie name,
+// i.e. "XSRF-TOKEN" or "csrftoken". The cookie is looked up in the
+// session's jar, so cookies set by redirect responses are found as well.
+func FromCookie(name string) CSRFSource {
+	return csrfSourceFunc(func(s *Session, r *http.Response) (string, bool) {
+		if r.Request == nil {
+			return "", false
+		}
+		for _, c := range s.jar.Cookies(r.Request.URL) {
+			if c.Name == name && c.Value != "" {
+				return c.Value, true
+			}
+		}
+		return "", false
+	})
+}
+
+// FromHeader creates a CSRFSource taking the token from the response header
+// name.
+func FromHeader(name string) CSRFSource {
+	return csrfSourceFunc(func(_ *Session, r *http.Response) (string, bool) {
+		v := r.Header.Get(name)
+		return v, v != ""
+	})
+}
+
+// FromMetaTag creates a CSRFSource taking the token from the content
+// attribute of the meta tag named name, i.e. "csrf-token", found in HTML
+// responses.
+func FromMetaTag(name string) CSRFSource {
+	return htmlSource("meta", "name", name, "content")
+}
+
+// FromFormField creates a CSRFSource taking the token from the value of the
+// input element named name, i.e. a hidden field "authenticity_token", found
+// in HTML responses.
+func FromFormField(name string) CSRFSource {
+	return htmlSource("input", "name", name, "value")
+}
+
+var (
+	tagPattern  = regexp.MustCompile(`(?is)<(meta|input)\b[^>]*>`)
+	attrPattern = regexp.MustCompile(`(?s)([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+))`)
+)
+
+// htmlSource creates a CSRFSource taking the token from the attribute value
+// of the first element tag whose attribute key equals name.
+func htmlSource(tag, key, name, value string) CSRFSource {
+	return csrfSourceFunc(func(_ *Session, r *http.Response) (string, bool) {
+		b, ok := htmlBody(r)
+		if !ok {
+			return "", false
+		}
+
+		for _, m := range tagPattern.FindAllSubmatch(b, -1) {
+			if !strings.EqualFold(string(m[1]), tag) {
+				continue
+			}
+			attrs := attributes(m[0])
+			if attrs[key] == name && attrs[value] != "" {
+				return attrs[value], true
+			}
+		}
+
+		return "", false
+	})
+}
+
+// attributes parses the attributes of the element tag t.
+func attributes(t []byte) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrPattern.FindAllSubmatch(t, -1) {
+		k := strings.ToLower(string(m[1]))
+		if _, ok := attrs[k]; !ok {
+			attrs[k] = html.UnescapeString(string(m[2]) + string(m[3]) + string(m[4]))
+		}
+	}
+	return attrs
+}
+
+// htmlBody reads the body of r if it is an HTML document. The body remains
+// readable.
+func htmlBody(r *http.Response) ([]byte, bool) {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if (mt != "text/html" && mt != "application/xhtml+xml") || r.Body == nil || r.Body == http.NoBody {
+		return nil, false
+	}
+
+	b, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(b))
+
+	return b, err == nil
+}
+
+// Option customizes a Session.
+type Option func(*Session)
+
+// WithCSRF makes the Session extract the CSRF token from every response
+// using source. Give multiple sources to try them in order.
+func WithCSRF(sources ...CSRFSource) Option {
+	return func(s *Session) {
+		s.sources = append(s.sources, sources...)
+	}
+}
+
+// WithCSRFHeader sets the header the CSRF token is sent in. It defaults to
+// DefaultCSRFHeader.
+func WithCSRFHeader(name string) Option {
+	return func(s *Session) {
+		s.header = name
+	}
+}
+
+// Session is a Client keeping cookies and a CSRF token across requests. A
+// Session is safe for concurrent use.
+type Session struct {
+	*httpclient.Client
+
+	jar     http.CookieJar
+	sources []CSRFSource
+	header  string
+
+	mu    sync.Mutex
+	token string
+}
+
+// New creates a Session sending requests using a Client derived from c. The
+// derived Client uses a new cookie jar and sends the CSRF token with POST,
+// PUT, PATCH and DELETE requests unless they already carry the header. The
+// token is updated whenever a response provides one, before the response is
+// validated, so tokens sent along with error pages are picked up as well.
+func New(c *httpclient.Client, opts ...Option) *Session {
+	jar, _ := cookiejar.New(nil)
+
+	s := &Session{
+		jar:    jar,
+		header: DefaultCSRFHeader,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Client = c.With(
+		httpclient.HTTPClientOption(func(hc *http.Client) {
+			hc.Jar = jar
+		}),
+		httpclient.WithRequestInterceptorFunc(s.attachToken),
+		httpclient.InPhaseFunc(httpclient.PhasePreValidate, s.extractToken),
+	)
+
+	return s
+}
+
+// Jar returns the session's cookie jar.
+func (s *Session) Jar() http.CookieJar {
+	return s.jar
+}
+
+// CSRFToken returns the current CSRF token or an empty string if none has
+// been received yet.
+func (s *Session) CSRFToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// SetCSRFToken sets the CSRF token, i.e. when it has been obtained from a
+// response the Session can't extract it from.
+func (s *Session) SetCSRFToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+func (s *Session) attachToken(r *http.Request) (*http.Request, error) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return r, nil
+	}
+
+	if token := s.CSRFToken(); token != "" && r.Header.Get(s.header) == "" {
+		r.Header.Set(s.header, token)
+	}
+
+	return r, nil
+}
+
+func (s *Session) extractToken(r *http.Response) (*http.Response, error) {
+	for _, src := range s.sources {
+		if token, ok := src.csrfToken(s, r); ok {
+			s.SetCSRFToken(token)
+			break
+		}
+	}
+	return r, nil
+}
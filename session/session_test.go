@@ -0,0 +1,89 @@
+package session_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/session"
+)
+
+func TestSession(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /page", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s1", Path: "/"})
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><meta content="t&amp;1" name='csrf-token'></head></html>`))
+	})
+	mux.HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("sid")
+		if err != nil || c.Value != "s1" || r.Header.Get("X-CSRF-Token") != "t&1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-CSRF-Token", "t2")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("DELETE /items", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-CSRF-Token") != "t2" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	s := session.New(
+		httpclient.New(httpclient.WithURLPrefix(testServer.URL), httpclient.ExpectedStatusCode(http.StatusOK, http.StatusCreated, http.StatusNoContent)),
+		session.WithCSRF(session.FromHeader("X-CSRF-Token"), session.FromMetaTag("csrf-token")),
+	)
+
+	_, err := s.Post(context.Background(), "/items")
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+
+	_, err = s.Get(context.Background(), "/page")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, s.CSRFToken()).Is(Equal("t&1"))
+
+	_, err = s.Post(context.Background(), "/items")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, s.CSRFToken()).Is(Equal("t2"))
+
+	_, err = s.Delete(context.Background(), "/items")
+	ExpectThat(t, err).Is(NoError())
+}
+
+func TestFromCookie(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "XSRF-TOKEN", Value: "c1", Path: "/"})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+	mux.HandleFunc("GET /home", func(w http.ResponseWriter, r *http.Request) {})
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	s := session.New(httpclient.New(httpclient.WithURLPrefix(testServer.URL)), session.WithCSRF(session.FromCookie("XSRF-TOKEN")))
+
+	_, err := s.Get(context.Background(), "/login")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, s.CSRFToken()).Is(Equal("c1"))
+}
+
+func TestFromFormField(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<form><input type="hidden" name="other" value="x"><INPUT TYPE=hidden NAME=authenticity_token VALUE=f1></form>`))
+	}))
+	defer testServer.Close()
+
+	s := session.New(httpclient.New(httpclient.WithURLPrefix(testServer.URL)), session.WithCSRF(session.FromFormField("authenticity_token")))
+
+	_, err := s.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, s.CSRFToken()).Is(Equal("f1"))
+}
@@ -0,0 +1,233 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// SOAP11Namespace is the namespace of SOAP 1.1 envelopes.
+	SOAP11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+
+	// SOAP12Namespace is the namespace of SOAP 1.2 envelopes.
+	SOAP12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// WithSOAP creates a RequestInterceptorOption that uses body wrapped in a SOAP
+// 1.1 envelope as the request's body. body is encoded using encoding/xml and
+// should declare its namespace using an XMLName field. The SOAPAction header
+// is set to action. Send it using Post and decode the response using ForSOAP.
+func WithSOAP(action string, body any) RequestInterceptorOption {
+	return withSOAP(SOAP11Namespace, "text/xml; charset=utf-8", action, body)
+}
+
+// WithSOAP12 is like WithSOAP but uses a SOAP 1.2 envelope, which carries
+// action as a parameter of the Content-Type header.
+func WithSOAP12(action string, body any) RequestInterceptorOption {
+	ct := "application/soap+xml; charset=utf-8"
+	if action != "" {
+		ct += fmt.Sprintf("; action=%q", action)
+	}
+	return withSOAP(SOAP12Namespace, ct, "", body)
+}
+
+func withSOAP(namespace, contentType, action string, body any) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		content, err := xml.Marshal(body)
+		if err != nil {
+			return r, err
+		}
+
+		var b bytes.Buffer
+		b.WriteString(xml.Header)
+		fmt.Fprintf(&b, `<soap:Envelope xmlns:soap="%s"><soap:Body>`, namespace)
+		b.Write(content)
+		b.WriteString(`</soap:Body></soap:Envelope>`)
+
+		if action != "" {
+			r.Header.Set("SOAPAction", fmt.Sprintf("%q", action))
+		}
+
+		payload := b.Bytes()
+		r, err = withBody(bytes.NewReader(payload), contentType, int64(len(payload))).InterceptRequest(r)
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(payload)), nil
+		}
+		return r, err
+	})
+}
+
+// SOAPFault is returned by ForSOAP if the response contains a SOAP Fault.
+// Both SOAP 1.1 and SOAP 1.2 faults are mapped to a SOAPFault.
+type SOAPFault struct {
+	// Code is the fault code, i.e. "soap:Server" for SOAP 1.1 or
+	// "soap:Receiver" for SOAP 1.2.
+	Code string
+
+	// String is the human readable explanation of the fault.
+	String string
+
+	// Actor identifies the node that caused the fault, if given.
+	Actor string
+
+	// Detail contains the raw XML of the fault's detail element.
+	Detail []byte
+}
+
+func (f *SOAPFault) Error() string {
+	return fmt.Sprintf("soap fault: %s: %s", f.Code, f.String)
+}
+
+type soap11Fault struct {
+	Code   string `xml:"faultcode"`
+	String string `xml:"faultstring"`
+	Actor  string `xml:"faultactor"`
+	Detail struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"detail"`
+}
+
+type soap12Fault struct {
+	Code struct {
+		Value string `xml:"Value"`
+	} `xml:"Code"`
+	Reason struct {
+		Text string `xml:"Text"`
+	} `xml:"Reason"`
+	Node   string `xml:"Node"`
+	Detail struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"Detail"`
+}
+
+// ForSOAP creates a RequestOption that unwraps the body of a SOAP 1.1 or 1.2
+// envelope and decodes the body's content into resp using encoding/xml. If
+// the body contains a Fault, a *SOAPFault is returned.
+//
+// As SOAP services report faults using a 500 status code, the interceptor
+// runs in PhasePreValidate, so faults are reported even if status codes are
+// validated. Responses not containing a SOAP envelope cause an *Error of
+// kind ErrDecode unless their status code denotes an error, in which case
+// they are left to status validation. The response body remains readable
+// after it has been decoded.
+func ForSOAP(resp any) RequestOption {
+	return &forSOAP{resp}
+}
+
+// forSOAP is the RequestInterceptor and ResponseInterceptor created by
+// ForSOAP.
+type forSOAP struct {
+	resp any
+}
+
+func (*forSOAP) clientOpt() {}
+func (*forSOAP) reqOpt()    {}
+
+func (*forSOAP) Phase() Phase { return PhasePreValidate }
+
+func (*forSOAP) InterceptRequest(r *http.Request) (*http.Request, error) {
+	r.Header.Add("Accept", "text/xml, application/soap+xml")
+	return r, nil
+}
+
+func (s *forSOAP) InterceptResponse(r *http.Response) (*http.Response, error) {
+	ct := r.Header.Get("Content-Type")
+	if !hasMediaType(ct, []string{"text/xml", "application/xml", "application/soap+xml"}) {
+		if r.StatusCode >= 400 {
+			return r, nil
+		}
+		return r, newResponseError(ErrDecode, r, fmt.Errorf("expected SOAP response but got %s", ct))
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return r, err
+	}
+	r.Body = &multiReadCloser{bytes.NewReader(b), r.Body}
+
+	if err := s.decode(b); err != nil {
+		if _, ok := err.(*SOAPFault); ok {
+			return r, err
+		}
+		return r, newResponseError(ErrDecode, r, err)
+	}
+
+	return r, nil
+}
+
+// decode decodes the SOAP envelope contained in b.
+func (s *forSOAP) decode(b []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+
+	// depth tracks the element nesting: 1 is the envelope, 2 the body.
+	var depth int
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return fmt.Errorf("missing SOAP body")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 1 && (t.Name.Local != "Envelope" || !isSOAPNamespace(t.Name.Space)):
+				return fmt.Errorf("expected SOAP envelope but got %s", t.Name.Local)
+			case depth == 2 && t.Name.Local == "Body":
+				return s.decodeBody(dec)
+			case depth == 2:
+				// Skip headers.
+				if err := dec.Skip(); err != nil {
+					return err
+				}
+				depth--
+			}
+		}
+	}
+}
+
+// decodeBody decodes the first element of a SOAP body.
+func (s *forSOAP) decodeBody(dec *xml.Decoder) error {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.EndElement:
+			// Empty body.
+			return nil
+		case xml.StartElement:
+			if t.Name.Local != "Fault" || !isSOAPNamespace(t.Name.Space) {
+				return dec.DecodeElement(s.resp, &t)
+			}
+
+			if t.Name.Space == SOAP12Namespace {
+				var f soap12Fault
+				if err := dec.DecodeElement(&f, &t); err != nil {
+					return err
+				}
+				return &SOAPFault{Code: f.Code.Value, String: f.Reason.Text, Actor: f.Node, Detail: bytes.TrimSpace(f.Detail.Inner)}
+			}
+
+			var f soap11Fault
+			if err := dec.DecodeElement(&f, &t); err != nil {
+				return err
+			}
+			return &SOAPFault{Code: f.Code, String: f.String, Actor: f.Actor, Detail: bytes.TrimSpace(f.Detail.Inner)}
+		}
+	}
+}
+
+func isSOAPNamespace(ns string) bool {
+	return ns == SOAP11Namespace || ns == SOAP12Namespace || ns == ""
+}
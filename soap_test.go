@@ -0,0 +1,96 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestSOAP(t *testing.T) {
+	var soapAction, contentType, body string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		soapAction = r.Header.Get("SOAPAction")
+		contentType = r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+
+		switch {
+		case strings.Contains(body, "<City>Nowhere</City>"):
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<?xml version="1.0"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>Unknown city</faultstring><detail><code>42</code></detail></soap:Fault></soap:Body></soap:Envelope>`))
+		case strings.Contains(body, "<City>Atlantis</City>"):
+			w.Header().Set("Content-Type", "application/soap+xml")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault><env:Code><env:Value>env:Sender</env:Value></env:Code><env:Reason><env:Text xml:lang="en">Sunk</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`))
+		default:
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Header><Session>1</Session></soap:Header><soap:Body><GetWeatherResponse xmlns="http://example.com/weather"><Temperature>21</Temperature></GetWeatherResponse></soap:Body></soap:Envelope>`))
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+
+	type getWeather struct {
+		XMLName xml.Name `xml:"http://example.com/weather GetWeather"`
+		City    string   `xml:"City"`
+	}
+
+	type getWeatherResponse struct {
+		Temperature int `xml:"Temperature"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var res getWeatherResponse
+		_, err := client.Post(context.Background(), "/",
+			httpclient.WithSOAP("http://example.com/weather/GetWeather", getWeather{City: "Berlin"}),
+			httpclient.ForSOAP(&res),
+		)
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.Temperature).Is(Equal(21))
+		ExpectThat(t, soapAction).Is(Equal(`"http://example.com/weather/GetWeather"`))
+		ExpectThat(t, contentType).Is(Equal("text/xml; charset=utf-8"))
+		ExpectThat(t, body).Is(Equal(xml.Header + `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><GetWeather xmlns="http://example.com/weather"><City>Berlin</City></GetWeather></soap:Body></soap:Envelope>`))
+	})
+
+	t.Run("SOAP 1.1 fault", func(t *testing.T) {
+		var res getWeatherResponse
+		_, err := client.Post(context.Background(), "/",
+			httpclient.WithSOAP("http://example.com/weather/GetWeather", getWeather{City: "Nowhere"}),
+			httpclient.ForSOAP(&res),
+		)
+
+		var fault *httpclient.SOAPFault
+		ExpectThat(t, errors.As(err, &fault)).Is(Equal(true))
+		ExpectThat(t, *fault).Is(DeepEqual(httpclient.SOAPFault{Code: "soap:Client", String: "Unknown city", Detail: []byte("<code>42</code>")}))
+	})
+
+	t.Run("SOAP 1.2 fault", func(t *testing.T) {
+		var res getWeatherResponse
+		_, err := client.Post(context.Background(), "/",
+			httpclient.WithSOAP12("http://example.com/weather/GetWeather", getWeather{City: "Atlantis"}),
+			httpclient.ForSOAP(&res),
+		)
+
+		ExpectThat(t, contentType).Is(Equal(`application/soap+xml; charset=utf-8; action="http://example.com/weather/GetWeather"`))
+
+		var fault *httpclient.SOAPFault
+		ExpectThat(t, errors.As(err, &fault)).Is(Equal(true))
+		ExpectThat(t, fault.Code).Is(Equal("env:Sender"))
+		ExpectThat(t, fault.String).Is(Equal("Sunk"))
+	})
+}
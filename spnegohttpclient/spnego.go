@@ -0,0 +1,118 @@
+// Package spnegohttpclient integrates httpclient with Kerberos to
+// authenticate requests using SPNEGO as defined by RFC 4559, i.e. when
+// calling services running inside Windows or Active Directory environments
+// that require "Negotiate" authentication. Kerberos is implemented by
+// github.com/jcmturner/gokrb5 in pure Go; it is provided as a separate
+// module so that applications not using Kerberos don't depend on it.
+package spnegohttpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/halimath/httpclient"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+// DefaultConfigPath is the path of the Kerberos configuration used by
+// SystemClient unless KRB5_CONFIG is set.
+const DefaultConfigPath = "/etc/krb5.conf"
+
+// SystemClient creates a Kerberos client using the credentials of the
+// current user as acquired by kinit or a system login. The credential cache
+// is taken from KRB5CCNAME, defaulting to /tmp/krb5cc_<uid>, and the
+// configuration from KRB5_CONFIG, defaulting to DefaultConfigPath. Only file
+// based credential caches are supported; create a client using
+// client.NewWithKeytab or client.NewWithPassword otherwise, i.e. on Windows.
+func SystemClient() (*client.Client, error) {
+	cfgPath := os.Getenv("KRB5_CONFIG")
+	if cfgPath == "" {
+		cfgPath = DefaultConfigPath
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("spnegohttpclient: %w", err)
+	}
+
+	ccPath, err := credentialCachePath()
+	if err != nil {
+		return nil, fmt.Errorf("spnegohttpclient: %w", err)
+	}
+
+	cc, err := credentials.LoadCCache(ccPath)
+	if err != nil {
+		return nil, fmt.Errorf("spnegohttpclient: failed to load credential cache %s: %w", ccPath, err)
+	}
+
+	cl, err := client.NewFromCCache(cc, cfg, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, fmt.Errorf("spnegohttpclient: %w", err)
+	}
+
+	return cl, nil
+}
+
+// credentialCachePath returns the path of the file based credential cache of
+// the current user.
+func credentialCachePath() (string, error) {
+	name := os.Getenv("KRB5CCNAME")
+	if name == "" {
+		uid := os.Getuid()
+		if uid < 0 {
+			return "", errors.New("no default credential cache on this platform")
+		}
+		return "/tmp/krb5cc_" + strconv.Itoa(uid), nil
+	}
+
+	if typ, path, ok := strings.Cut(name, ":"); ok && !strings.Contains(typ, "/") {
+		if typ != "FILE" {
+			return "", fmt.Errorf("unsupported credential cache type %s", typ)
+		}
+		return path, nil
+	}
+
+	return name, nil
+}
+
+// Option customizes WithNegotiate.
+type Option func(*negotiate)
+
+// WithSPN sets the service principal name tickets are requested for. It
+// defaults to HTTP/<host> using the canonical name of the request's host.
+func WithSPN(spn string) Option {
+	return func(n *negotiate) {
+		n.spn = spn
+	}
+}
+
+type negotiate struct {
+	spn string
+}
+
+// WithNegotiate creates a RequestInterceptorOption authenticating requests
+// with a Kerberos service ticket acquired using cl, which is sent in an
+// Authorization header using the Negotiate scheme. Tickets are obtained from
+// the KDC when needed and cached by cl. Every request is authenticated
+// preemptively, so no request has to be sent twice to answer a challenge.
+// The header is set in httpclient.RequestPhaseAuth.
+func WithNegotiate(cl *client.Client, opts ...Option) httpclient.RequestInterceptorOption {
+	var n negotiate
+	for _, opt := range opts {
+		opt(&n)
+	}
+
+	return httpclient.InRequestPhaseFunc(httpclient.RequestPhaseAuth, func(r *http.Request) (*http.Request, error) {
+		if err := spnego.SetSPNEGOHeader(cl, r, n.spn); err != nil {
+			return r, fmt.Errorf("spnegohttpclient: %w", err)
+		}
+		return r, nil
+	})
+}
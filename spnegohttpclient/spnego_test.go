@@ -0,0 +1,44 @@
+package spnegohttpclient_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/spnegohttpclient"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+)
+
+func TestSystemClient(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "krb5.conf")
+	ExpectThat(t, os.WriteFile(cfg, []byte("[libdefaults]\n default_realm = EXAMPLE.COM\n"), 0o600)).Is(NoError())
+	t.Setenv("KRB5_CONFIG", cfg)
+
+	t.Run("missingCache", func(t *testing.T) {
+		t.Setenv("KRB5CCNAME", "FILE:"+filepath.Join(dir, "missing"))
+		_, err := spnegohttpclient.SystemClient()
+		ExpectThat(t, err).Is(NotNil())
+	})
+
+	t.Run("unsupportedCache", func(t *testing.T) {
+		t.Setenv("KRB5CCNAME", "KEYRING:persistent:1000")
+		_, err := spnegohttpclient.SystemClient()
+		ExpectThat(t, err).Is(NotNil())
+	})
+}
+
+func TestWithNegotiate_failure(t *testing.T) {
+	cfg := config.New()
+	cfg.LibDefaults.DefaultRealm = "EXAMPLE.COM"
+	cl := client.NewWithPassword("user", "EXAMPLE.COM", "password", cfg)
+
+	c := httpclient.New(spnegohttpclient.WithNegotiate(cl, spnegohttpclient.WithSPN("HTTP/service.example.com")))
+
+	_, err := c.Get(context.Background(), "http://service.example.com/")
+	ExpectThat(t, err).Is(NotNil())
+}
@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+)
+
+// WithResponseSpooling creates a ResponseInterceptorOption reading response
+// bodies completely before they are processed. Bodies of up to threshold
+// bytes are buffered in memory; larger bodies are spooled to a temporary
+// file which is removed when the body is closed. This frees the connection
+// early and keeps huge payloads out of memory when they are decoded using
+// decoders streaming their input, i.e. ForJSON with StreamJSON, ForCSVRows
+// or ForWriter. The interceptor runs in PhasePreValidate.
+func WithResponseSpooling(threshold int64) ResponseInterceptorOption {
+	return InPhaseFunc(PhasePreValidate, func(r *http.Response) (*http.Response, error) {
+		if r.Body == nil || r.Body == http.NoBody {
+			return r, nil
+		}
+
+		body, err := spool(r.Body, threshold)
+		if err != nil {
+			return r, err
+		}
+
+		r.Body = body
+		return r, nil
+	})
+}
+
+// spool reads body completely and closes it. It returns a body reading the
+// same bytes from memory or from a temporary file if body contains more than
+// threshold bytes.
+func spool(body io.ReadCloser, threshold int64) (io.ReadCloser, error) {
+	defer body.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, body, threshold+1); err == io.EOF {
+		return io.NopCloser(&buf), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp("", "httpclient-spool-*")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spoolFile{f}
+	if _, err := io.Copy(f, io.MultiReader(&buf, body)); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// spoolFile is a body read from a temporary file which is removed on Close.
+type spoolFile struct {
+	*os.File
+}
+
+func (s *spoolFile) Close() error {
+	err := s.File.Close()
+	if rmErr := os.Remove(s.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
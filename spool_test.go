@@ -0,0 +1,71 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithResponseSpooling(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`["` + strings.Repeat("a", 100) + `"]`))
+	}))
+	defer testServer.Close()
+
+	spooled := func(t *testing.T) int {
+		entries, err := os.ReadDir(tmp)
+		ExpectThat(t, err).Is(NoError())
+		return len(entries)
+	}
+
+	for name, threshold := range map[string]int64{"memory": 1000, "file": 10} {
+		t.Run(name, func(t *testing.T) {
+			client := httpclient.New(httpclient.WithURLPrefix(testServer.URL), httpclient.WithResponseSpooling(threshold))
+
+			var files int
+			var v []string
+			_, err := client.Get(context.Background(), "/",
+				httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+					files = spooled(t)
+					return r, nil
+				}),
+				httpclient.ForJSON(&v, httpclient.StreamJSON()),
+			)
+			ExpectThat(t, err).Is(NoError())
+			ExpectThat(t, len(v[0])).Is(Equal(100))
+			ExpectThat(t, spooled(t)).Is(Equal(0))
+
+			if threshold < 100 {
+				ExpectThat(t, files).Is(Equal(1))
+			} else {
+				ExpectThat(t, files).Is(Equal(0))
+			}
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		client := httpclient.New(httpclient.WithURLPrefix(testServer.URL), httpclient.WithResponseSpooling(10))
+
+		res, err := client.Fetch(context.Background(), http.MethodGet, "/")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, spooled(t)).Is(Equal(1))
+
+		b, err := io.ReadAll(res.Body)
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, len(b)).Is(Equal(104))
+
+		ExpectThat(t, res.Close()).Is(NoError())
+		ExpectThat(t, spooled(t)).Is(Equal(0))
+	})
+}
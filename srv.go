@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SRVLookuper defines the interface used to resolve DNS SRV records. It is
+// implemented by *net.Resolver.
+type SRVLookuper interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// SRVOption customizes the behaviour of NewSRVLoadBalancer and WithSRV.
+type SRVOption func(*srvSource)
+
+// SRVResolver sets the SRVLookuper used to resolve records. It defaults to
+// net.DefaultResolver.
+func SRVResolver(r SRVLookuper) SRVOption {
+	return func(s *srvSource) {
+		s.resolver = r
+	}
+}
+
+// SRVRefreshInterval sets the interval after which the records are resolved
+// again, i.e. the TTL of the records. As the standard library does not expose
+// the TTL of DNS records, the interval defaults to 30 seconds.
+func SRVRefreshInterval(d time.Duration) SRVOption {
+	return func(s *srvSource) {
+		s.refresh = d
+	}
+}
+
+// NewSRVLoadBalancer creates a LoadBalancer distributing requests over the
+// targets of the DNS SRV records named name, i.e. "_https._tcp.example.com",
+// using strategy. Each record becomes an Endpoint with the record's Priority
+// and Weight, so - using WeightedRoundRobin - targets are selected as defined
+// by RFC 2782: targets with the lowest priority are preferred and chosen
+// proportionally to their weights. Health checks started with CheckHealth and
+// the failover performed by WithLoadBalancer apply to the targets like to any
+// other endpoints.
+//
+// The URLs of the endpoints use the https scheme if the service label of name
+// is "_https" and http otherwise. Besides requests with relative URLs,
+// requests with absolute URLs for the domain the records are defined for -
+// example.com in the example above - are sent to the targets as well.
+//
+// Records are resolved on the first request or health check and resolved
+// again once the interval configured with SRVRefreshInterval has passed.
+// Endpoints of targets that are still present keep their health state. If
+// resolving fails, the previously resolved endpoints are kept.
+func NewSRVLoadBalancer(name string, strategy LoadBalancingStrategy, opts ...SRVOption) *LoadBalancer {
+	s := &srvSource{
+		name:     name,
+		domain:   srvDomain(name),
+		scheme:   srvScheme(name),
+		resolver: net.DefaultResolver,
+		refresh:  30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return &LoadBalancer{
+		strategy: strategy,
+		srv:      s,
+	}
+}
+
+// WithSRV creates a ClientOption sending requests to the targets of the DNS
+// SRV records named name. It is a shorthand for using WithLoadBalancer with
+// a LoadBalancer created by NewSRVLoadBalancer using WeightedRoundRobin; use
+// those directly to run health checks for the targets.
+func WithSRV(name string, opts ...SRVOption) ClientOption {
+	return WithLoadBalancer(NewSRVLoadBalancer(name, WeightedRoundRobin(), opts...))
+}
+
+// srvDomain returns the domain of the SRV records named name by stripping
+// the leading service and protocol labels.
+func srvDomain(name string) string {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for len(labels) > 1 && strings.HasPrefix(labels[0], "_") {
+		labels = labels[1:]
+	}
+	return strings.Join(labels, ".")
+}
+
+// srvScheme returns the URL scheme for the service of the SRV records named
+// name.
+func srvScheme(name string) string {
+	if strings.HasPrefix(strings.ToLower(name), "_https.") {
+		return "https"
+	}
+	return "http"
+}
+
+// srvSource resolves the endpoints of a LoadBalancer from DNS SRV records.
+type srvSource struct {
+	name     string
+	domain   string
+	scheme   string
+	resolver SRVLookuper
+	refresh  time.Duration
+
+	// resolving is held while records are resolved, so only a single lookup
+	// is in flight. Callers finding endpoints that are merely stale don't
+	// wait for it.
+	resolving sync.Mutex
+	resolved  time.Time
+}
+
+// endpoints returns the endpoints of lb resolving them if needed.
+func (s *srvSource) endpoints(ctx context.Context, lb *LoadBalancer) ([]*Endpoint, error) {
+	now := ClockFromContext(ctx).Now()
+
+	endpoints := lb.Endpoints()
+	if endpoints != nil {
+		if !s.resolving.TryLock() {
+			return endpoints, nil
+		}
+	} else {
+		s.resolving.Lock()
+	}
+	defer s.resolving.Unlock()
+
+	endpoints = lb.Endpoints()
+	if endpoints != nil && now.Sub(s.resolved) < s.refresh {
+		return endpoints, nil
+	}
+
+	_, records, err := s.resolver.LookupSRV(ctx, "", "", s.name)
+	if err == nil && len(records) == 0 {
+		err = fmt.Errorf("no SRV records found for %s", s.name)
+	}
+
+	if err != nil {
+		if endpoints != nil {
+			return endpoints, nil
+		}
+		return nil, err
+	}
+
+	endpoints = s.update(endpoints, records)
+	s.resolved = now
+
+	lb.mutex.Lock()
+	lb.endpoints = endpoints
+	lb.mutex.Unlock()
+
+	return endpoints, nil
+}
+
+// update converts records to endpoints ordered by priority. Endpoints in
+// previous matching a record are reused.
+func (s *srvSource) update(previous []*Endpoint, records []*net.SRV) []*Endpoint {
+	endpoints := make([]*Endpoint, 0, len(records))
+	for _, r := range records {
+		u := url.URL{
+			Scheme: s.scheme,
+			Host:   net.JoinHostPort(strings.TrimSuffix(r.Target, "."), strconv.Itoa(int(r.Port))),
+		}
+
+		i := slices.IndexFunc(previous, func(e *Endpoint) bool {
+			return e.URL == u.String() && e.Weight == int(r.Weight) && e.Priority == int(r.Priority)
+		})
+		if i >= 0 {
+			endpoints = append(endpoints, previous[i])
+			continue
+		}
+
+		endpoints = append(endpoints, &Endpoint{
+			URL:      u.String(),
+			Weight:   int(r.Weight),
+			Priority: int(r.Priority),
+		})
+	}
+
+	slices.SortStableFunc(endpoints, func(a, b *Endpoint) int {
+		return a.Priority - b.Priority
+	})
+
+	return endpoints
+}
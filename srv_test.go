@@ -0,0 +1,172 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+type staticSRV struct {
+	lookups int
+	records []*net.SRV
+}
+
+func (s *staticSRV) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	s.lookups++
+	return name, s.records, nil
+}
+
+func TestWithSRV(t *testing.T) {
+	var host string
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer live.Close()
+
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	resolver := &staticSRV{
+		records: []*net.SRV{
+			{Target: "127.0.0.1.", Port: port(t, live.URL), Priority: 20},
+			{Target: "127.0.0.1.", Port: port(t, dead.URL), Priority: 10},
+		},
+	}
+
+	clock := httpclienttest.NewFakeClock(time.Now())
+
+	client := httpclient.New(
+		httpclient.WithSRV("_http._tcp.example.com", httpclient.SRVResolver(resolver), httpclient.SRVRefreshInterval(time.Minute)),
+		httpclient.WithClock(clock),
+	)
+
+	res, err := client.Get(context.Background(), "http://example.com/status")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusNoContent))
+	ExpectThat(t, host).Is(Equal("127.0.0.1:" + strconv.Itoa(int(port(t, live.URL)))))
+
+	_, err = client.Get(context.Background(), "http://example.com/status")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, resolver.lookups).Is(Equal(1))
+
+	clock.Advance(2 * time.Minute)
+
+	_, err = client.Get(context.Background(), "http://example.com/status")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, resolver.lookups).Is(Equal(2))
+
+	_, err = client.Get(context.Background(), live.URL)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, resolver.lookups).Is(Equal(2))
+}
+
+func port(t *testing.T, rawURL string) uint16 {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	ExpectThat(t, err).Is(NoError())
+
+	p, err := strconv.Atoi(u.Port())
+	ExpectThat(t, err).Is(NoError())
+
+	return uint16(p)
+}
+
+func TestNewSRVLoadBalancer(t *testing.T) {
+	var primaryHealthy atomic.Bool
+	var hits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" && !primaryHealthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("primary"))
+	}))
+	defer primary.Close()
+
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			hits.Add(1)
+		}
+		w.Write([]byte("backup"))
+	}))
+	defer backup.Close()
+
+	resolver := &staticSRV{
+		records: []*net.SRV{
+			{Target: "127.0.0.1.", Port: port(t, backup.URL), Priority: 20},
+			{Target: "127.0.0.1.", Port: port(t, primary.URL), Priority: 10, Weight: 5},
+		},
+	}
+
+	clock := httpclienttest.NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(httpclient.ContextWithClock(context.Background(), clock))
+	defer cancel()
+
+	lb := httpclient.NewSRVLoadBalancer("_http._tcp.example.com", httpclient.WeightedRoundRobin(),
+		httpclient.SRVResolver(resolver),
+		httpclient.SRVRefreshInterval(time.Minute),
+	)
+
+	primaryHealthy.Store(true)
+	lb.CheckHealth(ctx, httpclient.HealthCheck{
+		Path:               "/healthz",
+		Interval:           time.Second,
+		UnhealthyThreshold: 1,
+	})
+
+	probed := func() {
+		for clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	probed()
+
+	endpoints := lb.Endpoints()
+	ExpectThat(t, len(endpoints)).Is(Equal(2))
+	ExpectThat(t, endpoints[0].URL).Is(Equal(primary.URL))
+	ExpectThat(t, endpoints[0].Weight).Is(Equal(5))
+	ExpectThat(t, endpoints[1].URL).Is(Equal(backup.URL))
+
+	client := httpclient.New(httpclient.WithLoadBalancer(lb), httpclient.WithClock(clock))
+
+	get := func() string {
+		var body string
+		_, err := client.Get(context.Background(), "/",
+			httpclient.WithResponseInterceptorFunc(func(r *http.Response) (*http.Response, error) {
+				b, err := io.ReadAll(r.Body)
+				body = string(b)
+				return r, err
+			}),
+		)
+		ExpectThat(t, err).Is(NoError())
+		return body
+	}
+
+	ExpectThat(t, get()).Is(Equal("primary"))
+	ExpectThat(t, hits.Load()).Is(Equal(int32(0)))
+
+	primaryHealthy.Store(false)
+	clock.Advance(time.Second)
+	probed()
+	ExpectThat(t, endpoints[0].Healthy()).Is(Equal(false))
+	ExpectThat(t, get()).Is(Equal("backup"))
+
+	clock.Advance(time.Minute)
+	probed()
+	ExpectThat(t, resolver.lookups).Is(Equal(2))
+	ExpectThat(t, lb.Endpoints()[0]).Is(Equal(endpoints[0]))
+	ExpectThat(t, get()).Is(Equal("backup"))
+}
@@ -0,0 +1,255 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSSERetry is the time Subscribe waits before reconnecting unless the
+// server requests a different time using the retry field.
+const DefaultSSERetry = 3 * time.Second
+
+// ServerSentEvent is an event received from a text/event-stream response.
+type ServerSentEvent struct {
+	// ID is the event's ID or the ID of the last event that had one.
+	ID string
+
+	// Type is the event's type. It defaults to "message".
+	Type string
+
+	// Data is the event's payload. Multiple data fields are joined using
+	// line feeds.
+	Data string
+}
+
+// Subscribe sends a GET request for url using ctx and opts and delivers the
+// Server-Sent Events of the text/event-stream response over the returned
+// channel. An error is returned if the initial request fails or if the
+// response has a status code other than 200 or a different content type.
+//
+// Once the stream ends or the connection is lost, Subscribe reconnects after
+// the time requested by the server using the retry field, DefaultSSERetry
+// otherwise, sending the ID of the last event received in a Last-Event-ID
+// request header. The channel is closed when ctx is done or when a
+// reconnection attempt fails permanently, i.e. because the server responds
+// with a 204 status code to signal the end of the stream. Reconnection
+// attempts failing with a network error are repeated.
+//
+// As streams may stay open indefinitely, c should not limit the time spent on
+// requests with http.Client.Timeout; use ctx instead.
+func (c *Client) Subscribe(ctx context.Context, url string, opts ...RequestOption) (<-chan ServerSentEvent, error) {
+	s := newSubscription(ctx, c, url, opts)
+
+	res, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan ServerSentEvent)
+	go func() {
+		defer close(events)
+		s.stream(ctx, res, func(ev ServerSentEvent) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return events, nil
+}
+
+// Events creates an iterator over the Server-Sent Events received for url
+// just like Client.Subscribe does. The request is sent using c and opts when
+// the iteration starts, so every iteration opens a new subscription.
+//
+// Any error that occurs while sending the initial request, or that stops
+// reconnecting, is yielded as the final element of the iteration with the
+// zero value of ServerSentEvent. This includes the error of ctx, once it is
+// done. A reconnection attempt answered with a 204 status code ends the
+// iteration without an error. When the consumer stops the iteration early,
+// i.e. by breaking out of a range loop, the response body is closed.
+//
+//	for ev, err := range httpclient.Events(ctx, client, "/events") {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+func Events(ctx context.Context, c *Client, url string, opts ...RequestOption) iter.Seq2[ServerSentEvent, error] {
+	return func(yield func(ServerSentEvent, error) bool) {
+		s := newSubscription(ctx, c, url, opts)
+
+		res, err := s.connect(ctx)
+		if err != nil {
+			yield(ServerSentEvent{}, err)
+			return
+		}
+
+		err = s.stream(ctx, res, func(ev ServerSentEvent) bool {
+			return yield(ev, nil)
+		})
+		if err != nil {
+			yield(ServerSentEvent{}, err)
+		}
+	}
+}
+
+// subscription implements the reconnection logic of Subscribe.
+type subscription struct {
+	c           *Client
+	url         string
+	opts        []RequestOption
+	clock       Clock
+	retry       time.Duration
+	lastEventID string
+}
+
+func newSubscription(ctx context.Context, c *Client, url string, opts []RequestOption) *subscription {
+	s := &subscription{
+		c:     c,
+		url:   url,
+		opts:  opts,
+		retry: DefaultSSERetry,
+		clock: c.clock,
+	}
+	if s.clock == nil {
+		s.clock = ClockFromContext(ctx)
+	}
+	return s
+}
+
+func (s *subscription) connect(ctx context.Context) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if s.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+
+	res, err := s.c.do(req, s.opts, true)
+	if err != nil {
+		if res != nil {
+			res.Body.Close()
+		}
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		err := newResponseError(ErrUnexpectedStatus, res, nil)
+		res.Body.Close()
+		return nil, err
+	}
+
+	if ct := res.Header.Get("Content-Type"); !hasMediaType(ct, []string{"text/event-stream"}) {
+		res.Body.Close()
+		return nil, newResponseError(ErrDecode, res, fmt.Errorf("expected event stream response but got %s", ct))
+	}
+
+	return res, nil
+}
+
+// stream passes the events read from res and from the responses of
+// subsequent reconnections to emit until emit returns false or reconnecting
+// fails permanently. It returns the error that stopped reconnecting, which
+// is nil if the server ended the stream with a 204 status code or if emit
+// returned false.
+func (s *subscription) stream(ctx context.Context, res *http.Response, emit func(ServerSentEvent) bool) error {
+	for {
+		ok := s.read(res.Body, emit)
+		res.Body.Close()
+		if !ok {
+			return nil
+		}
+
+		for {
+			if err := sleep(ctx, s.clock, s.retry); err != nil {
+				return err
+			}
+
+			var err error
+			res, err = s.connect(ctx)
+			if err == nil {
+				break
+			}
+
+			var e *Error
+			if errors.As(err, &e) && e.StatusCode == http.StatusNoContent {
+				return nil
+			}
+			if !IsRetryable(err) {
+				return err
+			}
+		}
+	}
+}
+
+// read parses the event stream read from body and passes the events received
+// to emit until body is exhausted. It returns false if emit returned false.
+func (s *subscription) read(body io.Reader, emit func(ServerSentEvent) bool) bool {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, 1<<20)
+
+	var eventType string
+	var data strings.Builder
+	var hasData bool
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if hasData {
+				ev := ServerSentEvent{ID: s.lastEventID, Type: eventType, Data: data.String()}
+				if ev.Type == "" {
+					ev.Type = "message"
+				}
+				if !emit(ev) {
+					return false
+				}
+			}
+			eventType, hasData = "", false
+			data.Reset()
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,124 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestSubscribe(t *testing.T) {
+	var connections atomic.Int32
+	var lastEventID atomic.Value
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch connections.Add(1) {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte(": comment\nretry: 1\ndata: first\ndata: line\n\nevent: update\nid: 42\ndata: second\n\n"))
+		case 2:
+			lastEventID.Store(r.Header.Get("Last-Event-ID"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("data: third\n\ndata: incomplete"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	events, err := client.Subscribe(context.Background(), "/events")
+	ExpectThat(t, err).Is(NoError())
+
+	var got []httpclient.ServerSentEvent
+	for ev := range events {
+		got = append(got, ev)
+	}
+
+	ExpectThat(t, got).Is(DeepEqual([]httpclient.ServerSentEvent{
+		{Type: "message", Data: "first\nline"},
+		{ID: "42", Type: "update", Data: "second"},
+		{ID: "42", Type: "message", Data: "third"},
+	}))
+	ExpectThat(t, lastEventID.Load()).Is(Equal("42"))
+	ExpectThat(t, connections.Load()).Is(Equal(int32(3)))
+}
+
+func TestSubscribe_unexpectedStatus(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	_, err := client.Subscribe(context.Background(), "/events")
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+}
+
+func TestEvents(t *testing.T) {
+	var connections atomic.Int32
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/endless" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := 0; r.Context().Err() == nil; i++ {
+				fmt.Fprintf(w, "data: %d\n\n", i)
+				w.(http.Flusher).Flush()
+				time.Sleep(time.Millisecond)
+			}
+			return
+		}
+
+		if connections.Add(1) == 1 {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("retry: 1\ndata: first\n\nid: 1\ndata: second\n\n"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("all", func(t *testing.T) {
+		var got []httpclient.ServerSentEvent
+		for ev, err := range httpclient.Events(context.Background(), client, "/events") {
+			ExpectThat(t, err).Is(NoError())
+			got = append(got, ev)
+		}
+
+		ExpectThat(t, got).Is(DeepEqual([]httpclient.ServerSentEvent{
+			{Type: "message", Data: "first"},
+			{ID: "1", Type: "message", Data: "second"},
+		}))
+		ExpectThat(t, connections.Load()).Is(Equal(int32(2)))
+	})
+
+	t.Run("stopEarly", func(t *testing.T) {
+		var data []string
+		for ev, err := range httpclient.Events(context.Background(), client, "/endless") {
+			ExpectThat(t, err).Is(NoError())
+			data = append(data, ev.Data)
+			if len(data) == 2 {
+				break
+			}
+		}
+		ExpectThat(t, data).Is(DeepEqual([]string{"0", "1"}))
+	})
+
+	t.Run("unexpectedStatus", func(t *testing.T) {
+		var lastErr error
+		for _, err := range httpclient.Events(context.Background(), client, "/gone") {
+			lastErr = err
+		}
+		ExpectThat(t, lastErr).Is(Error(httpclient.ErrUnexpectedStatus))
+	})
+}
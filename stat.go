@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ResourceInfo describes a resource as reported by the headers of a response
+// to a HEAD request sent by Client.Stat.
+type ResourceInfo struct {
+	// ContentLength is the size of the resource in bytes or -1 if unknown.
+	ContentLength int64
+
+	// ContentType is the resource's media type.
+	ContentType string
+
+	// LastModified is the time the resource has been modified last or the
+	// zero time if unknown.
+	LastModified time.Time
+
+	// ETag is the resource's entity tag including quotes and any weakness
+	// indicator, as required for If-Match or If-None-Match headers.
+	ETag string
+
+	// AcceptRanges reports whether the server supports byte range requests
+	// for the resource.
+	AcceptRanges bool
+}
+
+// Stat sends a HEAD request for url using ctx and opts and returns the
+// metadata of the resource reported by the response's headers, i.e. to
+// decide whether to download it. Responses with a status code other than 2xx
+// are reported as an *Error of kind ErrUnexpectedStatus.
+func (c *Client) Stat(ctx context.Context, url string, opts ...RequestOption) (ResourceInfo, error) {
+	res, err := c.Head(ctx, url, opts...)
+	if err != nil {
+		return ResourceInfo{}, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return ResourceInfo{}, newResponseError(ErrUnexpectedStatus, res, nil)
+	}
+
+	info := ResourceInfo{
+		ContentLength: res.ContentLength,
+		ContentType:   res.Header.Get("Content-Type"),
+		ETag:          res.Header.Get("ETag"),
+		AcceptRanges:  acceptsByteRanges(res),
+	}
+
+	if lm := res.Header.Get("Last-Modified"); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil {
+			info.LastModified = t
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,42 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestClient_Stat(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "archive.zip", modified, strings.NewReader("0123456789"))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	info, err := client.Stat(context.Background(), "/archive.zip")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, info).Is(DeepEqual(httpclient.ResourceInfo{
+		ContentLength: 10,
+		ContentType:   "application/zip",
+		LastModified:  modified,
+		ETag:          `"v1"`,
+		AcceptRanges:  true,
+	}))
+
+	_, err = client.Stat(context.Background(), "/missing")
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+}
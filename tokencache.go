@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Token is an access token cached in a TokenCache.
+type Token struct {
+	// Value is the token as sent to servers.
+	Value string `json:"value"`
+
+	// Expiry is the time the token expires or the zero time if unknown.
+	Expiry time.Time `json:"expiry"`
+}
+
+// TokenCache caches tokens obtained by auth providers, such as the ones
+// provided by packages azuread and googlehttpclient, keyed by a string
+// identifying the credentials and scopes the token has been issued for.
+// Providers check the expiry of tokens returned from the cache themselves.
+// Use MemoryTokenCache to share tokens within a process and FileTokenCache
+// to reuse them across runs of short-lived processes, i.e. CLIs.
+type TokenCache interface {
+	// Get returns the token stored for key.
+	Get(key string) (Token, bool)
+
+	// Set stores t for key. Providers ignore errors, as the token remains
+	// usable for the current process.
+	Set(key string, t Token) error
+}
+
+// MemoryTokenCache is a TokenCache keeping tokens in memory. The zero value
+// is ready to use. It is safe for concurrent use.
+type MemoryTokenCache struct {
+	m sync.Map
+}
+
+func (c *MemoryTokenCache) Get(key string) (Token, bool) {
+	t, ok := c.m.Load(key)
+	if !ok {
+		return Token{}, false
+	}
+	return t.(Token), true
+}
+
+func (c *MemoryTokenCache) Set(key string, t Token) error {
+	c.m.Store(key, t)
+	return nil
+}
+
+// FileTokenCache is a TokenCache storing tokens in a file encrypted using
+// AES-GCM, so tokens are reused across runs of a process. The file is
+// created with permissions 0600 and replaced atomically on every Set, which
+// reads the file first, so processes sharing the file don't drop each
+// other's tokens. Expired tokens are removed when the file is written. A
+// missing, unreadable or corrupt file is treated as an empty cache. A
+// FileTokenCache is safe for concurrent use.
+type FileTokenCache struct {
+	path  string
+	aead  cipher.AEAD
+	clock Clock
+	mu    sync.Mutex
+}
+
+// FileTokenCacheOption customizes a FileTokenCache.
+type FileTokenCacheOption func(*FileTokenCache)
+
+// TokenCacheClock sets the Clock used to determine expired tokens, i.e. the
+// Clock given to the auth providers using the cache. It defaults to
+// SystemClock.
+func TokenCacheClock(clock Clock) FileTokenCacheOption {
+	return func(c *FileTokenCache) {
+		c.clock = clock
+	}
+}
+
+// NewFileTokenCache creates a FileTokenCache storing tokens in the file at
+// path encrypted with key, which must be 16, 24 or 32 bytes long. Keep key
+// outside the file system, i.e. in the operating system's key store.
+func NewFileTokenCache(path string, key []byte, opts ...FileTokenCacheOption) (*FileTokenCache, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token cache key: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &FileTokenCache{path: path, aead: aead, clock: SystemClock}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+func (c *FileTokenCache) Get(key string) (Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tokens, _ := c.load()
+	t, ok := tokens[key]
+	return t, ok
+}
+
+func (c *FileTokenCache) Set(key string, t Token) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tokens, err := c.load()
+	if err != nil {
+		tokens = make(map[string]Token)
+	}
+
+	now := c.clock.Now()
+	for k, v := range tokens {
+		if !v.Expiry.IsZero() && v.Expiry.Before(now) {
+			delete(tokens, k)
+		}
+	}
+	tokens[key] = t
+
+	return c.store(tokens)
+}
+
+// load reads and decrypts the tokens stored in the file. A missing file
+// yields an empty map.
+func (c *FileTokenCache) load() (map[string]Token, error) {
+	tokens := make(map[string]Token)
+
+	b, err := os.ReadFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < c.aead.NonceSize() {
+		return nil, errors.New("token cache file corrupt")
+	}
+
+	plaintext, err := c.aead.Open(nil, b[:c.aead.NonceSize()], b[c.aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// store encrypts tokens and replaces the file.
+func (c *FileTokenCache) store(tokens map[string]Token) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(c.aead.Seal(nonce, nonce, plaintext, nil)); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path)
+}
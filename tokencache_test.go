@@ -0,0 +1,84 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/httpclienttest"
+)
+
+func TestMemoryTokenCache(t *testing.T) {
+	var cache httpclient.MemoryTokenCache
+
+	_, ok := cache.Get("k")
+	ExpectThat(t, ok).Is(Equal(false))
+
+	ExpectThat(t, cache.Set("k", httpclient.Token{Value: "v"})).Is(NoError())
+	tok, ok := cache.Get("k")
+	ExpectThat(t, ok).Is(Equal(true))
+	ExpectThat(t, tok.Value).Is(Equal("v"))
+}
+
+func TestFileTokenCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+
+	cache, err := httpclient.NewFileTokenCache(path, key)
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, cache.Set("expired", httpclient.Token{Value: "old", Expiry: time.Now().Add(-time.Minute)})).Is(NoError())
+	ExpectThat(t, cache.Set("k", httpclient.Token{Value: "v", Expiry: expiry})).Is(NoError())
+
+	info, err := os.Stat(path)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, info.Mode().Perm()).Is(Equal(os.FileMode(0o600)))
+
+	b, err := os.ReadFile(path)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, bytes.Contains(b, []byte(`"value"`))).Is(Equal(false))
+
+	other, err := httpclient.NewFileTokenCache(path, key)
+	ExpectThat(t, err).Is(NoError())
+
+	tok, ok := other.Get("k")
+	ExpectThat(t, ok).Is(Equal(true))
+	ExpectThat(t, tok).Is(DeepEqual(httpclient.Token{Value: "v", Expiry: expiry}))
+
+	ExpectThat(t, other.Set("l", httpclient.Token{Value: "w"})).Is(NoError())
+	_, ok = cache.Get("k")
+	ExpectThat(t, ok).Is(Equal(true))
+	_, ok = cache.Get("expired")
+	ExpectThat(t, ok).Is(Equal(false))
+
+	wrongKey, err := httpclient.NewFileTokenCache(path, make([]byte, 32))
+	ExpectThat(t, err).Is(NoError())
+	_, ok = wrongKey.Get("k")
+	ExpectThat(t, ok).Is(Equal(false))
+
+	_, err = httpclient.NewFileTokenCache(path, []byte("short"))
+	ExpectThat(t, err).Is(NotNil())
+}
+
+func TestFileTokenCache_clock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	clock := httpclienttest.NewFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cache, err := httpclient.NewFileTokenCache(path, make([]byte, 32), httpclient.TokenCacheClock(clock))
+	ExpectThat(t, err).Is(NoError())
+
+	ExpectThat(t, cache.Set("k", httpclient.Token{Value: "v", Expiry: clock.Now().Add(time.Hour)})).Is(NoError())
+	ExpectThat(t, cache.Set("l", httpclient.Token{Value: "w"})).Is(NoError())
+	_, ok := cache.Get("k")
+	ExpectThat(t, ok).Is(Equal(true))
+
+	clock.Advance(2 * time.Hour)
+	ExpectThat(t, cache.Set("l", httpclient.Token{Value: "w"})).Is(NoError())
+	_, ok = cache.Get("k")
+	ExpectThat(t, ok).Is(Equal(false))
+}
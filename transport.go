@@ -0,0 +1,34 @@
+package httpclient
+
+import "net/http"
+
+// Transport returns an http.RoundTripper sending requests using c, applying
+// all of c's interceptors and options. This allows using c with libraries
+// that only accept an http.RoundTripper or an *http.Client:
+//
+//	hc := &http.Client{Transport: c.Transport()}
+//
+// Unlike responses returned by Do, the body of responses returned by the
+// RoundTripper is left open and must be closed by the caller. Requests are
+// cloned before being intercepted, so the requests passed to RoundTrip are
+// never modified. Redirects are followed by c, so an *http.Client using the
+// RoundTripper only sees the final response.
+func (c *Client) Transport() http.RoundTripper {
+	return clientTransport{c}
+}
+
+type clientTransport struct {
+	c *Client
+}
+
+func (t clientTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := t.c.do(r.Clone(r.Context()), nil, true)
+	if err != nil {
+		if res != nil {
+			res.Body.Close()
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
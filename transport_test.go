@@ -0,0 +1,44 @@
+package httpclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestClient_Transport(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("hello, world"))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithRequestHeader("Authorization", "token"),
+		httpclient.ExpectedStatusCode(http.StatusOK),
+	)
+	hc := &http.Client{Transport: client.Transport()}
+
+	req, err := http.NewRequest(http.MethodGet, testServer.URL, nil)
+	ExpectThat(t, err).Is(NoError())
+
+	res, err := hc.Do(req)
+	ExpectThat(t, err).Is(NoError())
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, string(body)).Is(Equal("hello, world"))
+	ExpectThat(t, req.Header.Get("Authorization")).Is(Equal(""))
+
+	unauthorized := &http.Client{Transport: client.With(httpclient.WithoutRequestHeader("Authorization")).Transport()}
+	_, err = unauthorized.Get(testServer.URL)
+	ExpectThat(t, err).Is(NotNil())
+}
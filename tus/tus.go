@@ -0,0 +1,314 @@
+// Package tus implements resumable uploads using the tus protocol 1.0 on top
+// of an httpclient.Client. An upload is created on the server and its data is
+// sent using a sequence of PATCH requests, each carrying a chunk of the data
+// along with its offset. If a chunk fails, the offset acknowledged by the
+// server is queried and the upload continues from there, so a failure never
+// requires sending the whole file again. Uploads interrupted for longer, i.e.
+// by a restart of the process, can be continued using Client.Resume given the
+// upload's URL.
+//
+// Only the core protocol and the creation extension are implemented. The
+// size of an upload must be known when it is created.
+//
+// See https://tus.io/protocols/resumable-upload for the protocol.
+package tus
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/halimath/httpclient"
+)
+
+// Version is the version of the tus protocol implemented by this package.
+const Version = "1.0.0"
+
+// DefaultChunkSize is the number of bytes sent with every PATCH request
+// unless configured otherwise using WithChunkSize.
+const DefaultChunkSize = 4 << 20
+
+// DefaultResumeAttempts is the number of times a failed upload is resumed
+// unless configured otherwise using WithResume.
+const DefaultResumeAttempts = 3
+
+// offsetContentType is the content type of PATCH request bodies.
+const offsetContentType = "application/offset+octet-stream"
+
+// errOffsetNotAdvanced is returned if the server acknowledges a chunk
+// without advancing the offset.
+var errOffsetNotAdvanced = errors.New("tus: upload offset not advanced")
+
+// Option customizes a Client.
+type Option func(*Client)
+
+// WithChunkSize sets the number of bytes sent with every PATCH request to n.
+func WithChunkSize(n int64) Option {
+	return func(c *Client) {
+		c.chunkSize = n
+	}
+}
+
+// WithResume configures how often a failed upload is resumed without
+// returning an error and how long to wait before each resumption. Setting
+// attempts to 0 disables resuming.
+func WithResume(attempts int, backoff httpclient.Backoff) Option {
+	return func(c *Client) {
+		c.resumeAttempts = attempts
+		c.backoff = backoff
+	}
+}
+
+// Client performs uploads to a tus server.
+type Client struct {
+	c              *httpclient.Client
+	endpoint       string
+	chunkSize      int64
+	resumeAttempts int
+	backoff        httpclient.Backoff
+}
+
+// New creates a new Client creating uploads at endpoint using c. Requests
+// are sent with the options configured for c, so authentication and logging
+// work as they do for any other request.
+func New(c *httpclient.Client, endpoint string, opts ...Option) *Client {
+	t := &Client{
+		c:              c,
+		endpoint:       endpoint,
+		chunkSize:      DefaultChunkSize,
+		resumeAttempts: DefaultResumeAttempts,
+		backoff:        httpclient.ExponentialBackoff(time.Second, 30*time.Second),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Upload describes an upload created on a tus server.
+type Upload struct {
+	// URL is the URL of the upload. Store it to resume the upload using
+	// Client.Resume.
+	URL string
+
+	// Size is the total number of bytes of the upload.
+	Size int64
+
+	// Offset is the number of bytes acknowledged by the server.
+	Offset int64
+}
+
+// Done reports whether all bytes of the upload have been acknowledged.
+func (u *Upload) Done() bool {
+	return u.Offset >= u.Size
+}
+
+// Upload creates an upload of size bytes described by metadata and sends the
+// data read from r. If an error is returned along with a non-nil *Upload,
+// the upload has been created and can be continued using Client.Send or
+// Client.Resume.
+func (t *Client) Upload(ctx context.Context, r io.ReadSeeker, size int64, metadata map[string]string, opts ...httpclient.RequestOption) (*Upload, error) {
+	u, err := t.Create(ctx, size, metadata, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, t.Send(ctx, u, r, opts...)
+}
+
+// Create creates an upload of size bytes described by metadata, i.e. a
+// filename, without sending any data.
+func (t *Client) Create(ctx context.Context, size int64, metadata map[string]string, opts ...httpclient.RequestOption) (*Upload, error) {
+	opts = append(opts[:len(opts):len(opts)],
+		httpclient.WithRequestHeader("Tus-Resumable", Version),
+		httpclient.WithRequestHeader("Upload-Length", strconv.FormatInt(size, 10)),
+		httpclient.ExpectedStatusCode(http.StatusCreated),
+	)
+	if len(metadata) > 0 {
+		opts = append(opts, httpclient.WithRequestHeader("Upload-Metadata", encodeMetadata(metadata)))
+	}
+
+	res, err := t.c.Post(ctx, t.endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := res.Location()
+	if err != nil {
+		return nil, fmt.Errorf("tus: invalid upload location: %w", err)
+	}
+
+	return &Upload{URL: loc.String(), Size: size}, nil
+}
+
+// Resume continues the upload at uploadURL sending the data read from r,
+// which must contain the whole data of the upload, starting at the offset
+// acknowledged by the server.
+func (t *Client) Resume(ctx context.Context, uploadURL string, r io.ReadSeeker, opts ...httpclient.RequestOption) (*Upload, error) {
+	u := &Upload{URL: uploadURL}
+
+	var err error
+	u.Offset, u.Size, err = t.status(ctx, uploadURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if u.Size < 0 {
+		return nil, errors.New("tus: server didn't report upload length")
+	}
+
+	return u, t.Send(ctx, u, r, opts...)
+}
+
+// Send sends the data read from r starting at u.Offset using PATCH requests
+// of the configured chunk size. r must contain the whole data of the upload
+// and is positioned at u.Offset before every chunk. u.Offset is updated with
+// every acknowledged chunk. If a chunk fails with a retryable error or a
+// status code indicating a temporary failure or an offset conflict, the
+// offset acknowledged by the server is queried and the upload is resumed as
+// configured using WithResume.
+func (t *Client) Send(ctx context.Context, u *Upload, r io.ReadSeeker, opts ...httpclient.RequestOption) error {
+	clock := httpclient.ClockFromContext(ctx)
+
+	var attempts int
+	for !u.Done() {
+		err := t.sendChunk(ctx, u, r, opts)
+		if err == nil {
+			attempts = 0
+			continue
+		}
+
+		if ctx.Err() != nil || attempts >= t.resumeAttempts || !resumable(err) {
+			return err
+		}
+		attempts++
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(t.backoff(attempts)):
+		}
+
+		offset, _, statusErr := t.status(ctx, u.URL, opts)
+		if statusErr != nil {
+			return errors.Join(err, statusErr)
+		}
+		u.Offset = offset
+	}
+
+	return nil
+}
+
+// sendChunk sends the next chunk of u read from r.
+func (t *Client) sendChunk(ctx context.Context, u *Upload, r io.ReadSeeker, opts []httpclient.RequestOption) error {
+	if _, err := r.Seek(u.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	n := min(t.chunkSize, u.Size-u.Offset)
+
+	res, err := t.c.Patch(ctx, u.URL, append(opts[:len(opts):len(opts)],
+		httpclient.WithRequestHeader("Tus-Resumable", Version),
+		httpclient.WithRequestHeader("Upload-Offset", strconv.FormatInt(u.Offset, 10)),
+		httpclient.WithBody(io.LimitReader(r, n), offsetContentType, n),
+		httpclient.ExpectedStatusCode(http.StatusNoContent),
+	)...)
+	if err != nil {
+		return err
+	}
+
+	offset, err := parseOffset(res)
+	if err != nil {
+		return err
+	}
+	if offset <= u.Offset {
+		return errOffsetNotAdvanced
+	}
+
+	u.Offset = offset
+	return nil
+}
+
+// status queries the offset and length of the upload at uploadURL. The
+// length is -1 if the server doesn't report it.
+func (t *Client) status(ctx context.Context, uploadURL string, opts []httpclient.RequestOption) (offset, length int64, err error) {
+	res, err := t.c.Head(ctx, uploadURL, append(opts[:len(opts):len(opts)],
+		httpclient.WithRequestHeader("Tus-Resumable", Version),
+		httpclient.ExpectedStatusCode(http.StatusOK, http.StatusNoContent),
+	)...)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset, err = parseOffset(res)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	length = -1
+	if v := res.Header.Get("Upload-Length"); v != "" {
+		length, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("tus: invalid Upload-Length: %w", err)
+		}
+	}
+
+	return offset, length, nil
+}
+
+// parseOffset returns the Upload-Offset reported by res.
+func parseOffset(res *http.Response) (int64, error) {
+	offset, err := strconv.ParseInt(res.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("tus: invalid Upload-Offset: %q", res.Header.Get("Upload-Offset"))
+	}
+	return offset, nil
+}
+
+// resumableStatusCodes lists the status codes of failed chunks after which
+// the upload is resumed.
+var resumableStatusCodes = []int{
+	http.StatusConflict,
+	http.StatusLocked,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// resumable reports whether the upload may be resumed after a chunk failed
+// with err.
+func resumable(err error) bool {
+	if httpclient.IsRetryable(err) || errors.Is(err, errOffsetNotAdvanced) {
+		return true
+	}
+
+	var e *httpclient.Error
+	return errors.As(err, &e) && errors.Is(e.Kind, httpclient.ErrUnexpectedStatus) && slices.Contains(resumableStatusCodes, e.StatusCode)
+}
+
+// encodeMetadata encodes metadata as the value of an Upload-Metadata header
+// with keys sorted for reproducibility.
+func encodeMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + " " + base64.StdEncoding.EncodeToString([]byte(metadata[k]))
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,133 @@
+package tus_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/tus"
+)
+
+// server is a minimal in-memory tus server failing the PATCH requests whose
+// numbers are contained in failPatches.
+type server struct {
+	mu          sync.Mutex
+	uploads     map[string]*bytes.Buffer
+	lengths     map[string]int64
+	metadata    string
+	patches     int
+	failPatches map[int]bool
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Tus-Resumable") != tus.Version {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		length, _ := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		id := "/files/" + strconv.Itoa(len(s.uploads)+1)
+		s.uploads[id] = new(bytes.Buffer)
+		s.lengths[id] = length
+		s.metadata = r.Header.Get("Upload-Metadata")
+		w.Header().Set("Location", id)
+		w.WriteHeader(http.StatusCreated)
+
+	case http.MethodHead:
+		buf, ok := s.uploads[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Upload-Offset", strconv.Itoa(buf.Len()))
+		w.Header().Set("Upload-Length", strconv.FormatInt(s.lengths[r.URL.Path], 10))
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodPatch:
+		s.patches++
+		buf := s.uploads[r.URL.Path]
+		if r.Header.Get("Content-Type") != "application/offset+octet-stream" || r.Header.Get("Upload-Offset") != strconv.Itoa(buf.Len()) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		if s.failPatches[s.patches] {
+			// Store part of the chunk to simulate an interrupted request.
+			io.CopyN(buf, r.Body, 2)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		io.Copy(buf, r.Body)
+		w.Header().Set("Upload-Offset", strconv.Itoa(buf.Len()))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newServer(failPatches ...int) *server {
+	s := &server{
+		uploads:     make(map[string]*bytes.Buffer),
+		lengths:     make(map[string]int64),
+		failPatches: make(map[int]bool),
+	}
+	for _, p := range failPatches {
+		s.failPatches[p] = true
+	}
+	return s
+}
+
+func TestClient_Upload(t *testing.T) {
+	data := strings.Repeat("0123456789", 10)
+
+	s := newServer(2, 3)
+	testServer := httptest.NewServer(s)
+	defer testServer.Close()
+
+	client := tus.New(httpclient.New(httpclient.WithURLPrefix(testServer.URL)), "/files",
+		tus.WithChunkSize(30),
+		tus.WithResume(3, httpclient.ConstantBackoff(time.Millisecond)),
+	)
+
+	u, err := client.Upload(context.Background(), strings.NewReader(data), int64(len(data)), map[string]string{"filename": "data.txt"})
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, u.Done()).Is(Equal(true))
+	ExpectThat(t, u.URL).Is(Equal(testServer.URL + "/files/1"))
+	ExpectThat(t, s.uploads["/files/1"].String()).Is(Equal(data))
+	ExpectThat(t, s.metadata).Is(Equal("filename ZGF0YS50eHQ="))
+}
+
+func TestClient_Resume(t *testing.T) {
+	data := strings.Repeat("0123456789", 10)
+
+	s := newServer(2)
+	testServer := httptest.NewServer(s)
+	defer testServer.Close()
+
+	client := tus.New(httpclient.New(httpclient.WithURLPrefix(testServer.URL)), "/files",
+		tus.WithChunkSize(30),
+		tus.WithResume(0, nil),
+	)
+
+	u, err := client.Upload(context.Background(), strings.NewReader(data), int64(len(data)), nil)
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+	ExpectThat(t, u.Done()).Is(Equal(false))
+
+	u, err = client.Resume(context.Background(), u.URL, strings.NewReader(data))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, u.Done()).Is(Equal(true))
+	ExpectThat(t, s.uploads["/files/1"].String()).Is(Equal(data))
+}
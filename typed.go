@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Result bundles a decoded response body with the metadata of the response
+// it has been decoded from, so callers don't need to keep both the
+// *http.Response and the decoded value around.
+type Result[T any] struct {
+	// Value is the decoded response body.
+	Value T
+
+	// StatusCode and Header are the response's status code and headers.
+	StatusCode int
+	Header     http.Header
+
+	// Duration is the time spent from sending the request until the
+	// response body has been decoded, including all retries.
+	Duration time.Duration
+
+	// Attempts is the number of attempts made to send the request, as
+	// denoted by the ExecutionState of the final attempt.
+	Attempts int
+}
+
+// ExecuteJSON sends a request for url using method, c and opts and returns a
+// Result carrying the JSON response body decoded into a value of type T.
+// Use WithJSON in opts to send a JSON request body. If a response has been
+// received, the returned Result carries its metadata even if an error is
+// returned, i.e. because the response didn't pass status validation.
+func ExecuteJSON[T any](ctx context.Context, c *Client, method, url string, opts ...RequestOption) (Result[T], error) {
+	clock := c.clock
+	if clock == nil {
+		clock = ClockFromContext(ctx)
+	}
+
+	var result Result[T]
+
+	start := clock.Now()
+	res, err := c.Execute(ctx, method, url, append(opts[:len(opts):len(opts)], ForJSON(&result.Value))...)
+	result.Duration = clock.Now().Sub(start)
+
+	if res != nil {
+		result.StatusCode = res.StatusCode
+		result.Header = res.Header
+		if res.Request != nil {
+			result.Attempts = ExecutionStateFromContext(res.Request.Context()).Attempt
+		}
+	}
+
+	return result, err
+}
+
+// GetJSON sends a GET request for url using c and opts and returns the JSON
+// response body decoded into a value of type T. It is a shorthand for using
+// ForJSON:
+//
+//	user, err := httpclient.GetJSON[User](ctx, client, "/users/42")
+//
+// Use ExecuteJSON to obtain the response's metadata as well.
+func GetJSON[T any](ctx context.Context, c *Client, url string, opts ...RequestOption) (T, error) {
+	r, err := ExecuteJSON[T](ctx, c, http.MethodGet, url, opts...)
+	return r.Value, err
+}
+
+// PostJSON sends a POST request for url using c and opts with body encoded
+// as JSON and returns the JSON response body decoded into a value of type
+// Resp. It is a shorthand for using WithJSON and ForJSON:
+//
+//	created, err := httpclient.PostJSON[NewUser, User](ctx, client, "/users", NewUser{Name: "Alice"})
+//
+// Use ExecuteJSON to obtain the response's metadata as well.
+func PostJSON[Req, Resp any](ctx context.Context, c *Client, url string, body Req, opts ...RequestOption) (Resp, error) {
+	r, err := ExecuteJSON[Resp](ctx, c, http.MethodPost, url, append(opts[:len(opts):len(opts)], WithJSON(body))...)
+	return r.Value, err
+}
@@ -0,0 +1,75 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestTypedJSON(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := user{ID: 42, Name: "Alice"}
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&u)
+			u.ID = 43
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(u)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("GetJSON", func(t *testing.T) {
+		u, err := httpclient.GetJSON[user](context.Background(), client, "/users/42")
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, u).Is(Equal(user{ID: 42, Name: "Alice"}))
+	})
+
+	t.Run("PostJSON", func(t *testing.T) {
+		u, err := httpclient.PostJSON[user, user](context.Background(), client, "/users", user{Name: "Bob"})
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, u).Is(Equal(user{ID: 43, Name: "Bob"}))
+	})
+}
+
+func TestExecuteJSON(t *testing.T) {
+	var requests atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Cost", "3")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`"created"`))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRetry(httpclient.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+
+	r, err := httpclient.ExecuteJSON[string](context.Background(), client, http.MethodPut, "/items/1", httpclient.WithJSON("item"))
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, r.Value).Is(Equal("created"))
+	ExpectThat(t, r.StatusCode).Is(Equal(http.StatusCreated))
+	ExpectThat(t, r.Header.Get("X-Request-Cost")).Is(Equal("3"))
+	ExpectThat(t, r.Attempts).Is(Equal(2))
+	ExpectThat(t, r.Duration > 0).Is(Equal(true))
+}
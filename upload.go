@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// WithFileBody creates a RequestInterceptorOption streaming the file at path
+// as the request body. The Content-Type is derived from the file's extension
+// or sniffed from its first bytes using http.DetectContentType if the
+// extension is unknown. Content-Length is set to the file's size and
+// GetBody reopens the file, so the request can be retried. The file is
+// opened when the request is prepared; failing to open it aborts the
+// request. Use WithUploadProgress to observe the upload.
+func WithFileBody(path string) RequestInterceptorOption {
+	return WithRequestInterceptorFunc(func(r *http.Request) (*http.Request, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return r, err
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return r, err
+		}
+
+		contentType, err := fileContentType(f)
+		if err != nil {
+			f.Close()
+			return r, err
+		}
+
+		r, err = withBody(f, contentType, info.Size()).InterceptRequest(r)
+		if err != nil {
+			f.Close()
+			return r, err
+		}
+
+		r.GetBody = func() (io.ReadCloser, error) {
+			return os.Open(path)
+		}
+
+		return r, nil
+	})
+}
+
+// fileContentType determines the content type of f from its extension or by
+// sniffing its first bytes. f is positioned at its start afterwards.
+func fileContentType(f *os.File) (string, error) {
+	if ct := mime.TypeByExtension(filepath.Ext(f.Name())); ct != "" {
+		return ct, nil
+	}
+
+	var buf [512]byte
+	n, err := io.ReadFull(f, buf[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// WithUploadProgress creates an Option reporting the progress of sending
+// request bodies to f. f is called after every chunk read from the body by
+// the transport and once the body has been read completely. Total is taken
+// from the request's ContentLength. Given after WithRetry, every attempt is
+// reported starting from zero.
+func WithUploadProgress(f ProgressFunc) Option {
+	return uploadProgress(f)
+}
+
+type uploadProgress ProgressFunc
+
+func (uploadProgress) clientOpt() {}
+func (uploadProgress) reqOpt()    {}
+
+func (u uploadProgress) wrapRoundTrip(r *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return next(r)
+	}
+
+	total := r.ContentLength
+	if total == 0 {
+		total = -1
+	}
+
+	out := r.WithContext(r.Context())
+	out.Body = &progressReadCloser{
+		ReadCloser: r.Body,
+		p:          startProgress(ProgressFunc(u), ClockFromContext(r.Context()), total),
+	}
+
+	return next(out)
+}
+
+// progressReadCloser is an io.ReadCloser adding the bytes read to p.
+type progressReadCloser struct {
+	io.ReadCloser
+	p    *transferProgress
+	done bool
+}
+
+func (pr *progressReadCloser) Read(b []byte) (int, error) {
+	n, err := pr.ReadCloser.Read(b)
+	if n > 0 {
+		pr.p.add(int64(n))
+	}
+	if err == io.EOF && !pr.done {
+		pr.done = true
+		pr.p.report()
+	}
+	return n, err
+}
@@ -0,0 +1,78 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWithFileBody(t *testing.T) {
+	dir := t.TempDir()
+	content := "[" + strings.Repeat(`{"a":1},`, 1000) + `{"a":1}]`
+
+	jsonPath := filepath.Join(dir, "data.json")
+	ExpectThat(t, os.WriteFile(jsonPath, []byte(content), 0o644)).Is(NoError())
+
+	rawPath := filepath.Join(dir, "data")
+	ExpectThat(t, os.WriteFile(rawPath, []byte("<html><body>hello</body></html>"), 0o644)).Is(NoError())
+
+	var attempts atomic.Int32
+	var contentType, body string
+	var contentLength int64
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		contentType = r.Header.Get("Content-Type")
+		contentLength = r.ContentLength
+		body = string(b)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(
+		httpclient.WithURLPrefix(testServer.URL),
+		httpclient.WithRetry(httpclient.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+
+	t.Run("extension", func(t *testing.T) {
+		attempts.Store(0)
+
+		var last httpclient.Progress
+		_, err := client.Put(context.Background(), "/upload", httpclient.WithFileBody(jsonPath),
+			httpclient.WithUploadProgress(func(p httpclient.Progress) { last = p }))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, attempts.Load()).Is(Equal(int32(2)))
+		ExpectThat(t, contentType).Is(Equal("application/json"))
+		ExpectThat(t, contentLength).Is(Equal(int64(len(content))))
+		ExpectThat(t, body).Is(Equal(content))
+		ExpectThat(t, last.Transferred).Is(Equal(int64(len(content))))
+		ExpectThat(t, last.Total).Is(Equal(int64(len(content))))
+	})
+
+	t.Run("sniffed", func(t *testing.T) {
+		attempts.Store(0)
+
+		_, err := client.Put(context.Background(), "/upload", httpclient.WithFileBody(rawPath))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, contentType).Is(Equal("text/html; charset=utf-8"))
+		ExpectThat(t, body).Is(Equal("<html><body>hello</body></html>"))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := client.Put(context.Background(), "/upload", httpclient.WithFileBody(filepath.Join(dir, "missing")))
+		ExpectThat(t, os.IsNotExist(err)).Is(Equal(true))
+	})
+}
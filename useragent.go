@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Version is the version of this module. It is part of DefaultUserAgent.
+const Version = "0.2.0-dev"
+
+// DefaultUserAgent is the User-Agent sent with requests that don't set one.
+var DefaultUserAgent = NewUserAgent("httpclient", Version, "+https://github.com/halimath/httpclient").String()
+
+// UserAgent builds User-Agent header values consisting of a list of products
+// as defined by RFC 9110, section 10.1.5, i.e.
+//
+//	myapp/1.2.0 (linux; amd64) httpclient/0.2.0
+type UserAgent struct {
+	products []string
+}
+
+// NewUserAgent creates a UserAgent starting with product in version with
+// optional comments.
+func NewUserAgent(product, version string, comments ...string) *UserAgent {
+	return new(UserAgent).Add(product, version, comments...)
+}
+
+// Add appends product in version with optional comments to u and returns u.
+// version may be empty.
+func (u *UserAgent) Add(product, version string, comments ...string) *UserAgent {
+	var b strings.Builder
+	b.WriteString(product)
+	if version != "" {
+		b.WriteByte('/')
+		b.WriteString(version)
+	}
+	if len(comments) > 0 {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(comments, "; "))
+		b.WriteByte(')')
+	}
+
+	u.products = append(u.products, b.String())
+	return u
+}
+
+// String returns the User-Agent header value.
+func (u *UserAgent) String() string {
+	return strings.Join(u.products, " ")
+}
+
+// WithUserAgent creates a RequestInterceptorOption setting the User-Agent
+// header to ua. Requests not setting a User-Agent are sent with
+// DefaultUserAgent instead of the generic one used by net/http. Passing an
+// empty ua sends no User-Agent at all.
+func WithUserAgent(ua string) RequestInterceptorOption {
+	return WithRequestHeader("User-Agent", ua)
+}
+
+// applyDefaultUserAgent sets DefaultUserAgent for r unless r already
+// declares a User-Agent header, even an empty one.
+func applyDefaultUserAgent(r *http.Request) {
+	if _, ok := r.Header["User-Agent"]; ok {
+		return
+	}
+
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set("User-Agent", DefaultUserAgent)
+}
@@ -0,0 +1,37 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestUserAgent(t *testing.T) {
+	var ua []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua = r.Header.Values("User-Agent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	_, err := client.Get(context.Background(), "/")
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, ua).Is(DeepEqual([]string{"httpclient/" + httpclient.Version + " (+https://github.com/halimath/httpclient)"}))
+
+	custom := httpclient.NewUserAgent("myapp", "1.2.0", "linux", "amd64").Add("httpclient", httpclient.Version).String()
+	ExpectThat(t, custom).Is(Equal("myapp/1.2.0 (linux; amd64) httpclient/" + httpclient.Version))
+
+	_, err = client.Get(context.Background(), "/", httpclient.WithUserAgent(custom))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, ua).Is(DeepEqual([]string{custom}))
+
+	_, err = client.Get(context.Background(), "/", httpclient.WithUserAgent(""))
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, len(ua)).Is(Equal(0))
+}
@@ -0,0 +1,176 @@
+// Package vcr provides record/replay cassettes for hermetic tests of code
+// built on httpclient. In record mode, exchanges are sent to the real server
+// and stored in a cassette file; in replay mode, responses are served from the
+// cassette without any network access. Cassettes are stored as HAR files.
+package vcr
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/har"
+)
+
+// Mode defines how a cassette is used.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette. Requests that are not
+	// found in the cassette fail.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends all requests to the server and records the exchanges,
+	// replacing any existing cassette.
+	ModeRecord
+
+	// ModeReplayOrRecord replays the cassette if it exists and records a new
+	// one otherwise.
+	ModeReplayOrRecord
+)
+
+// Redacted is the value stored in place of scrubbed header and cookie values.
+const Redacted = har.Redacted
+
+// Option customizes a cassette.
+type Option func(*cassette)
+
+// ScrubHeaders adds headers whose values are replaced with Redacted before
+// the cassette is written. Authorization, Proxy-Authorization, Cookie and
+// Set-Cookie are always scrubbed.
+func ScrubHeaders(names ...string) Option {
+	return func(c *cassette) {
+		c.scrubHeaders = append(c.scrubHeaders, names...)
+	}
+}
+
+// Scrubber adds a function that is invoked for every recorded entry before
+// the cassette is written, i.e. to remove secrets from URLs or bodies.
+//
+// In replay mode, the scrubbers are applied to each outgoing request before
+// it is matched against the cassette, so requests still match entries whose
+// URL or body has been scrubbed. The entry passed to f then only contains the
+// request. Scrubbers must thus be deterministic and produce the same result
+// for a request when recording and when replaying it.
+func Scrubber(f func(*har.Entry)) Option {
+	return func(c *cassette) {
+		c.scrubbers = append(c.scrubbers, f)
+	}
+}
+
+// New creates a cassette stored in the file named cassettePath used
+// according to mode and returns it as a ClientOption. In record mode the
+// cassette file is rewritten after every recorded exchange, so no explicit
+// save step is needed.
+//
+// Errors reading a cassette in replay mode are reported when the first
+// request is sent.
+func New(mode Mode, cassettePath string, opts ...Option) httpclient.ClientOption {
+	c := &cassette{
+		path: cassettePath,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if mode == ModeReplayOrRecord {
+		if _, err := os.Stat(cassettePath); errors.Is(err, fs.ErrNotExist) {
+			mode = ModeRecord
+		} else {
+			mode = ModeReplay
+		}
+	}
+
+	return httpclient.HTTPClientOption(func(hc *http.Client) {
+		if mode == ModeRecord {
+			c.recorder = har.NewRecorder(har.RedactHeaders(c.scrubHeaders...))
+			c.next = c.recorder.RoundTripper(hc.Transport)
+			hc.Transport = recordingTransport{c}
+		} else {
+			hc.Transport = replayingTransport{c}
+		}
+	})
+}
+
+type cassette struct {
+	path         string
+	scrubHeaders []string
+	scrubbers    []func(*har.Entry)
+
+	recorder *har.Recorder
+	next     http.RoundTripper
+	mutex    sync.Mutex
+
+	loadOnce sync.Once
+	replayer *har.Replayer
+	loadErr  error
+}
+
+func (c *cassette) save() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Headers and cookies have already been scrubbed by the recorder.
+	h := c.recorder.HAR()
+	for i := range h.Log.Entries {
+		c.scrub(&h.Log.Entries[i])
+	}
+
+	return har.WriteFile(c.path, h)
+}
+
+func (c *cassette) scrub(e *har.Entry) {
+	for _, s := range c.scrubbers {
+		s(e)
+	}
+}
+
+// normalize applies the scrubbers to an outgoing request before it is
+// matched against the cassette.
+func (c *cassette) normalize(r *har.Request) {
+	e := har.Entry{Request: *r}
+	c.scrub(&e)
+	*r = e.Request
+}
+
+type recordingTransport struct {
+	c *cassette
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.c.next.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+
+	if err := t.c.save(); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type replayingTransport struct {
+	c *cassette
+}
+
+func (t replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.c.loadOnce.Do(func() {
+		var h *har.HAR
+		h, t.c.loadErr = har.ReadFile(t.c.path)
+		if t.c.loadErr == nil {
+			t.c.replayer = har.NewReplayer(h, har.NormalizeRequest(t.c.normalize))
+		}
+	})
+
+	if t.c.loadErr != nil {
+		return nil, t.c.loadErr
+	}
+
+	return t.c.replayer.RoundTrip(req)
+}
@@ -0,0 +1,100 @@
+package vcr_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/har"
+	"github.com/halimath/httpclient/vcr"
+)
+
+func TestCassette(t *testing.T) {
+	var calls int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"gopher"}`))
+	}))
+	defer testServer.Close()
+
+	cassettePath := filepath.Join(t.TempDir(), "cassette.har")
+
+	get := func(mode vcr.Mode) (string, error) {
+		client := httpclient.New(
+			vcr.New(mode, cassettePath, vcr.ScrubHeaders("X-Api-Key")),
+		)
+
+		var body struct {
+			Name string `json:"name"`
+		}
+		_, err := client.Get(context.Background(), testServer.URL+"/user",
+			httpclient.WithRequestHeader("X-Api-Key", "secret"),
+			httpclient.WithRequestHeader("Authorization", "Bearer secret"),
+			httpclient.ForJSON(&body),
+		)
+		return body.Name, err
+	}
+
+	name, err := get(vcr.ModeReplayOrRecord)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, name).Is(Equal("gopher"))
+	ExpectThat(t, calls).Is(Equal(1))
+
+	cassette, err := os.ReadFile(cassettePath)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, strings.Contains(string(cassette), "secret")).Is(Equal(false))
+
+	name, err = get(vcr.ModeReplayOrRecord)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, name).Is(Equal("gopher"))
+	ExpectThat(t, calls).Is(Equal(1))
+}
+
+func TestCassette_scrubber(t *testing.T) {
+	var calls int
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer testServer.Close()
+
+	cassettePath := filepath.Join(t.TempDir(), "cassette.har")
+
+	scrubber := vcr.Scrubber(func(e *har.Entry) {
+		e.Request.URL = strings.ReplaceAll(e.Request.URL, "token=secret", "token="+vcr.Redacted)
+		for i := range e.Request.QueryString {
+			if e.Request.QueryString[i].Name == "token" {
+				e.Request.QueryString[i].Value = vcr.Redacted
+			}
+		}
+		if e.Request.PostData != nil {
+			e.Request.PostData.Text = strings.ReplaceAll(e.Request.PostData.Text, "secret", vcr.Redacted)
+		}
+	})
+
+	post := func(mode vcr.Mode) (*http.Response, error) {
+		client := httpclient.New(vcr.New(mode, cassettePath, scrubber))
+		return client.Post(context.Background(), testServer.URL+"/login?token=secret",
+			httpclient.WithJSON(map[string]string{"password": "secret"}),
+		)
+	}
+
+	_, err := post(vcr.ModeRecord)
+	ExpectThat(t, err).Is(NoError())
+
+	cassette, err := os.ReadFile(cassettePath)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, strings.Contains(string(cassette), "secret")).Is(Equal(false))
+
+	res, err := post(vcr.ModeReplay)
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusCreated))
+	ExpectThat(t, calls).Is(Equal(1))
+}
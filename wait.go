@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// errMissingGetBody is returned by WaitFor if a request with a body has to be
+// sent again but provides no GetBody function.
+var errMissingGetBody = errors.New("request body can't be sent again: missing GetBody")
+
+// Backoff returns the time to wait before the given attempt, starting with 1
+// for the wait after the first attempt.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff creates a Backoff always waiting d.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// ExponentialBackoff creates a Backoff waiting initial after the first attempt
+// and doubling the time for every further attempt up to max.
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := initial
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
+
+// WaitFor repeatedly sends req using opts until predicate reports that the
+// condition it checks is met, i.e. that a resource created by an asynchronous
+// API has become ready:
+//
+//	res, err := client.WaitFor(ctx, req, func(r *http.Response) (bool, error) {
+//		var op Operation
+//		if err := json.NewDecoder(r.Body).Decode(&op); err != nil {
+//			return false, err
+//		}
+//		return op.Status == "ready", nil
+//	}, httpclient.ExponentialBackoff(time.Second, 30*time.Second))
+//
+// predicate runs as a response interceptor in PhasePostValidate, so it can
+// read the response body and only sees responses that passed the status
+// validation configured for c or with opts. Between attempts, WaitFor waits
+// as determined by backoff. The response that satisfied predicate is
+// returned with its body closed, as done by Do.
+//
+// WaitFor stops with an error if predicate returns an error, if a request
+// fails with an error for which IsRetryable reports false or if ctx is done.
+// Responses rejected by the status validation with a retryable status code,
+// i.e. 503, are polled again. Requests with a body are sent again using the
+// request's GetBody function.
+func (c *Client) WaitFor(ctx context.Context, req *http.Request, predicate func(*http.Response) (done bool, err error), backoff Backoff, opts ...RequestOption) (*http.Response, error) {
+	clock := c.clock
+	if clock == nil {
+		clock = ClockFromContext(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(ctx)
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return nil, errMissingGetBody
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		var done bool
+		var predicateErr error
+		res, err := c.Do(r, append(opts[:len(opts):len(opts)], InPhaseFunc(PhasePostValidate, func(r *http.Response) (*http.Response, error) {
+			done, predicateErr = predicate(r)
+			return r, predicateErr
+		}))...)
+
+		if predicateErr != nil {
+			return res, err
+		}
+		if err != nil && (ctx.Err() != nil || !IsRetryable(err)) {
+			return res, err
+		}
+		if done {
+			return res, nil
+		}
+
+		if err := sleep(ctx, clock, backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
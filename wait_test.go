@@ -0,0 +1,110 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestWaitFor(t *testing.T) {
+	var requests atomic.Int32
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.Write([]byte("pending"))
+			return
+		}
+		w.Write([]byte("ready"))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	isReady := func(r *http.Response) (bool, error) {
+		b, err := io.ReadAll(r.Body)
+		return string(b) == "ready", err
+	}
+
+	t.Run("done", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/operations/1", nil)
+		res, err := client.WaitFor(context.Background(), req, isReady, httpclient.ConstantBackoff(time.Millisecond))
+
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, res.StatusCode).Is(Equal(http.StatusOK))
+		ExpectThat(t, requests.Load()).Is(Equal(int32(3)))
+	})
+
+	t.Run("predicate error", func(t *testing.T) {
+		errFailed := errors.New("operation failed")
+		req, _ := http.NewRequest(http.MethodGet, "/operations/2", nil)
+		_, err := client.WaitFor(context.Background(), req, func(r *http.Response) (bool, error) {
+			return false, errFailed
+		}, httpclient.ConstantBackoff(time.Millisecond))
+
+		ExpectThat(t, err).Is(Error(errFailed))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		requests.Store(0)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		req, _ := http.NewRequest(http.MethodGet, "/operations/3", nil)
+		_, err := client.WaitFor(ctx, req, isReady, httpclient.ConstantBackoff(time.Hour))
+
+		ExpectThat(t, err).Is(Error(context.DeadlineExceeded))
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := httpclient.ExponentialBackoff(time.Second, 5*time.Second)
+
+	ExpectThat(t, b(1)).Is(Equal(time.Second))
+	ExpectThat(t, b(2)).Is(Equal(2 * time.Second))
+	ExpectThat(t, b(3)).Is(Equal(4 * time.Second))
+	ExpectThat(t, b(4)).Is(Equal(5 * time.Second))
+}
+
+func TestWaitFor_transientStatus(t *testing.T) {
+	var requests atomic.Int32
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Write([]byte("ready"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL), httpclient.ExpectSuccess())
+
+	isReady := func(r *http.Response) (bool, error) {
+		b, err := io.ReadAll(r.Body)
+		return string(b) == "ready", err
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/operations/1", nil)
+	res, err := client.WaitFor(context.Background(), req, isReady, httpclient.ConstantBackoff(time.Millisecond))
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, res.StatusCode).Is(Equal(http.StatusOK))
+	ExpectThat(t, requests.Load()).Is(Equal(int32(2)))
+
+	req, _ = http.NewRequest(http.MethodGet, "/operations/2", nil)
+	_, err = client.WaitFor(context.Background(), req, isReady, httpclient.ConstantBackoff(time.Millisecond))
+
+	ExpectThat(t, err).Is(Error(httpclient.ErrUnexpectedStatus))
+	ExpectThat(t, requests.Load()).Is(Equal(int32(3)))
+}
@@ -0,0 +1,54 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+)
+
+// WriterOption customizes the copying performed by ForWriter.
+type WriterOption func(*forWriter)
+
+// MaxWriteSize limits the number of bytes ForWriter copies to n. Copying a
+// larger body fails with an *Error of kind ErrDecode after n bytes have been
+// written.
+func MaxWriteSize(n int64) WriterOption {
+	return func(fw *forWriter) {
+		fw.maxSize = n
+	}
+}
+
+// forWriter is the ResponseInterceptor created by ForWriter.
+type forWriter struct {
+	w       io.Writer
+	maxSize int64
+}
+
+func (fw *forWriter) InterceptResponse(r *http.Response) (*http.Response, error) {
+	if fw.maxSize <= 0 {
+		_, err := io.Copy(fw.w, r.Body)
+		return r, err
+	}
+
+	src := &maxSizeReader{r: r.Body, n: fw.maxSize}
+	if _, err := io.Copy(fw.w, src); err != nil {
+		if src.exceeded {
+			return r, newResponseError(ErrDecode, r, err)
+		}
+		return r, err
+	}
+
+	return r, nil
+}
+
+// ForWriter creates a ResponseInterceptorOption copying the response body to
+// w without buffering it, i.e. to pipe it into a file, a hash or an
+// io.Pipe. The body is consumed afterwards. Errors reading the body or
+// writing to w are returned as is and abort processing of the response. Use
+// opts to limit the number of bytes copied.
+func ForWriter(w io.Writer, opts ...WriterOption) ResponseInterceptorOption {
+	fw := &forWriter{w: w}
+	for _, opt := range opts {
+		opt(fw)
+	}
+	return WithResponseInterceptor(fw)
+}
@@ -0,0 +1,52 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+)
+
+func TestForWriter(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	t.Run("copy", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := sha256.New()
+
+		_, err := client.Get(context.Background(), "/", httpclient.ForWriter(&buf), httpclient.ForWriter(h))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, buf.String()).Is(Equal(content))
+
+		// The body is consumed by the first ForWriter.
+		empty := sha256.Sum256(nil)
+		ExpectThat(t, h.Sum(nil)).Is(DeepEqual(empty[:]))
+	})
+
+	t.Run("withinLimit", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := client.Get(context.Background(), "/", httpclient.ForWriter(&buf, httpclient.MaxWriteSize(int64(len(content)))))
+		ExpectThat(t, err).Is(NoError())
+		ExpectThat(t, buf.Len()).Is(Equal(len(content)))
+	})
+
+	t.Run("exceedsLimit", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := client.Get(context.Background(), "/", httpclient.ForWriter(&buf, httpclient.MaxWriteSize(100)))
+		ExpectThat(t, err).Is(Error(httpclient.ErrDecode))
+		ExpectThat(t, buf.Len()).Is(Equal(100))
+	})
+}
@@ -0,0 +1,26 @@
+// Package yamlhttpclient integrates httpclient with YAML for APIs such as
+// configuration services accepting and returning YAML documents. It is
+// provided as a separate module so that applications not using YAML don't
+// depend on it.
+package yamlhttpclient
+
+import (
+	"github.com/halimath/httpclient"
+	"gopkg.in/yaml.v3"
+)
+
+// ContentType is the content type of YAML encoded bodies.
+const ContentType = "application/yaml"
+
+// WithYAML creates a RequestInterceptorOption that uses v encoded as YAML as
+// the request's body. See httpclient.WithEncoded.
+func WithYAML(v any) httpclient.RequestInterceptorOption {
+	return httpclient.WithEncoded(yaml.Marshal, v, ContentType)
+}
+
+// ForYAML creates a RequestOption that decodes a YAML encoded response body
+// into v. Besides application/yaml the commonly used application/x-yaml and
+// text/yaml content types are accepted. See httpclient.ForDecoded.
+func ForYAML(v any) httpclient.RequestOption {
+	return httpclient.ForDecoded(yaml.Unmarshal, v, ContentType, "application/x-yaml", "text/yaml")
+}
@@ -0,0 +1,49 @@
+package yamlhttpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/halimath/expect-go"
+	"github.com/halimath/httpclient"
+	"github.com/halimath/httpclient/yamlhttpclient"
+)
+
+func TestYAML(t *testing.T) {
+	type config struct {
+		Name     string   `yaml:"name"`
+		Replicas int      `yaml:"replicas"`
+		Labels   []string `yaml:"labels"`
+	}
+
+	var contentType, accept string
+	var body string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		accept = r.Header.Get("Accept")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+		w.Write(b)
+	}))
+	defer testServer.Close()
+
+	client := httpclient.New(httpclient.WithURLPrefix(testServer.URL))
+
+	in := config{Name: "web", Replicas: 2, Labels: []string{"a", "b"}}
+	var got config
+	_, err := client.Post(context.Background(), "/",
+		yamlhttpclient.WithYAML(in),
+		yamlhttpclient.ForYAML(&got),
+	)
+
+	ExpectThat(t, err).Is(NoError())
+	ExpectThat(t, got).Is(DeepEqual(in))
+	ExpectThat(t, body).Is(Equal("name: web\nreplicas: 2\nlabels:\n    - a\n    - b\n"))
+	ExpectThat(t, contentType).Is(Equal(yamlhttpclient.ContentType))
+	ExpectThat(t, accept).Is(Equal(yamlhttpclient.ContentType))
+}